@@ -0,0 +1,90 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+// defaultArenaChunk is the number of atoms allocated at once by an Arena when
+// no chunk size is given.
+const defaultArenaChunk = 4096
+
+// Arena is an allocator for markings that reserves the backing storage of
+// Atoms in large chunks. This reduces the number of allocations, and the
+// pressure on the garbage collector, when we create a lot of short-lived
+// markings, for instance during a state space exploration. The zero value of
+// Arena is ready to use.
+//
+// Markings allocated from an Arena are ordinary Markings. Their capacity is
+// equal to their length, so that appending to them never overwrites the
+// storage of another marking. A chunk is reclaimed by the garbage collector
+// only when all the markings allocated in it are unreachable.
+type Arena struct {
+	Chunk int // number of atoms allocated at once (defaultArenaChunk if 0)
+	buf   []Atom
+}
+
+// NewArena returns an Arena that allocates atoms in chunks of the given size.
+func NewArena(chunk int) *Arena {
+	return &Arena{Chunk: chunk}
+}
+
+// alloc returns an empty marking with capacity n. We return true if the
+// marking uses the storage of the arena, in which case the caller must call
+// commit once the marking is built.
+func (a *Arena) alloc(n int) (Marking, bool) {
+	if n > cap(a.buf)-len(a.buf) {
+		size := a.Chunk
+		if size <= 0 {
+			size = defaultArenaChunk
+		}
+		if n > size {
+			return make(Marking, 0, n), false
+		}
+		a.buf = make([]Atom, 0, size)
+	}
+	return Marking(a.buf[len(a.buf) : len(a.buf) : len(a.buf)+n]), true
+}
+
+// commit marks the atoms of m, obtained from alloc, as used in the arena.
+func (a *Arena) commit(m Marking) {
+	a.buf = a.buf[:len(a.buf)+len(m)]
+}
+
+// Add returns the pointwise sum of two markings, m and m2, allocated in the
+// arena. The result is equal to m.Add(m2).
+func (a *Arena) Add(m, m2 Marking) Marking {
+	res, shared := a.alloc(len(m) + len(m2))
+	k1, k2 := 0, 0
+	for k1 < len(m) || k2 < len(m2) {
+		switch {
+		case k1 == len(m):
+			res = append(res, m2[k2])
+			k2++
+		case k2 == len(m2):
+			res = append(res, m[k1])
+			k1++
+		case m[k1].Pl == m2[k2].Pl:
+			if mult := m[k1].Mult + m2[k2].Mult; mult != 0 {
+				res = append(res, Atom{Pl: m[k1].Pl, Mult: mult})
+			}
+			k1++
+			k2++
+		case m[k1].Pl < m2[k2].Pl:
+			res = append(res, m[k1])
+			k1++
+		default:
+			res = append(res, m2[k2])
+			k2++
+		}
+	}
+	if shared {
+		a.commit(res)
+	}
+	return res[:len(res):len(res)]
+}
+
+// Reset releases the current chunk of the arena. Markings allocated before the
+// call stay valid.
+func (a *Arena) Reset() {
+	a.buf = nil
+}
@@ -0,0 +1,87 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"bytes"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBinary(t *testing.T) {
+	for _, v := range []string{"abp.net", "demo.net", "ifip.net", "sokoban_3.net"} {
+		file, err := os.Open("testdata/" + v)
+		if err != nil {
+			t.Fatalf("Error opening file %s; %s", v, err)
+		}
+		net, err := Parse(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("Error parsing file %s; %s", v, err)
+		}
+		var buf bytes.Buffer
+		if err := net.WriteBinary(&buf); err != nil {
+			t.Fatalf("WriteBinary(%s): %s", v, err)
+		}
+		actual, err := ReadBinary(&buf)
+		if err != nil {
+			t.Fatalf("ReadBinary(%s): %s", v, err)
+		}
+		if err := net.compare(actual); err != nil {
+			t.Errorf("ReadBinary(%s): %s", v, err)
+		}
+	}
+}
+
+func TestReadBinaryHeader(t *testing.T) {
+	tables := []struct {
+		input, err string
+	}{
+		{"", "cannot read binary header"},
+		{"NET", "cannot read binary header"},
+		{"#net\n", "wrong magic number"},
+		{"NETS\x07", "unsupported binary format version 7"},
+		{"NETS\x02", "cannot decode net"},
+	}
+	for _, v := range tables {
+		_, err := ReadBinary(strings.NewReader(v.input))
+		if err == nil || !strings.Contains(err.Error(), v.err) {
+			t.Errorf("ReadBinary(%q): expected error %q, actual %v", v.input, v.err, err)
+		}
+	}
+}
+
+func TestBinaryVersion(t *testing.T) {
+	// if this test fails, the binary encoding of nets has changed and we
+	// need to increment binaryVersion and update binaryFields
+	fields := []string{}
+	typ := reflect.TypeOf(Net{})
+	for k := range typ.NumField() {
+		if f := typ.Field(k); f.IsExported() {
+			fields = append(fields, f.Name)
+		}
+	}
+	if !slices.Equal(fields, binaryFields) {
+		t.Errorf("Fields of Net changed, increment binaryVersion; expected %v, actual %v", binaryFields, fields)
+	}
+	// a net saved with an older version of the format is rejected, even when
+	// it can be decoded
+	net, err := ParseString("pl p0 (1)\ntr t0 p0 -> p1\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	var buf bytes.Buffer
+	if err := net.WriteBinary(&buf); err != nil {
+		t.Fatalf("WriteBinary: %s", err)
+	}
+	data := buf.Bytes()
+	data[len(binaryMagic)] = binaryVersion - 1
+	if _, err := ReadBinary(bytes.NewReader(data)); err == nil || !strings.Contains(err.Error(), "no longer supported") {
+		t.Errorf("ReadBinary: expected error with an older version, actual %v", err)
+	}
+}
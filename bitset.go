@@ -0,0 +1,106 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import "math/bits"
+
+// Bitset is a set of non-negative integers, such as transition or place
+// indexes, stored as a slice of 64-bits words. Bit i of the set is bit (i % 64)
+// of word (i / 64).
+type Bitset []uint64
+
+// NewBitset returns an empty Bitset large enough to store the integers in the
+// range [0, n).
+func NewBitset(n int) Bitset {
+	return make(Bitset, (n+63)/64)
+}
+
+// Set adds i to the set. It panics if i is out of the range of b.
+func (b Bitset) Set(i int) {
+	b[i/64] |= 1 << (uint(i) % 64)
+}
+
+// Clear removes i from the set. It panics if i is out of the range of b.
+func (b Bitset) Clear(i int) {
+	b[i/64] &^= 1 << (uint(i) % 64)
+}
+
+// Test reports whether i is in the set.
+func (b Bitset) Test(i int) bool {
+	if i < 0 || i/64 >= len(b) {
+		return false
+	}
+	return b[i/64]&(1<<(uint(i)%64)) != 0
+}
+
+// Count returns the number of elements in the set.
+func (b Bitset) Count() int {
+	c := 0
+	for _, w := range b {
+		c += bits.OnesCount64(w)
+	}
+	return c
+}
+
+// Equal reports whether b and b2 contain the same elements.
+func (b Bitset) Equal(b2 Bitset) bool {
+	if len(b) < len(b2) {
+		b, b2 = b2, b
+	}
+	for k := range b {
+		var w uint64
+		if k < len(b2) {
+			w = b2[k]
+		}
+		if b[k] != w {
+			return false
+		}
+	}
+	return true
+}
+
+// includedIn reports whether every element of b is also in b2, assuming both
+// sets have the same length.
+func (b Bitset) includedIn(b2 Bitset) bool {
+	for k := range b {
+		if b[k]&^b2[k] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ForEach calls fn on every element of the set, in increasing order.
+func (b Bitset) ForEach(fn func(i int)) {
+	for k, w := range b {
+		for w != 0 {
+			i := bits.TrailingZeros64(w)
+			fn(64*k + i)
+			w &= w - 1
+		}
+	}
+}
+
+// Slice returns the elements of the set as a sorted slice of integers.
+func (b Bitset) Slice() []int {
+	res := make([]int, 0, b.Count())
+	b.ForEach(func(i int) {
+		res = append(res, i)
+	})
+	return res
+}
+
+// EnabledMask returns the set of transitions enabled at marking m as a Bitset.
+// It contains the same transitions than AllEnabled, but this representation is
+// more compact and faster to compare when caching enabled sets.
+func (net *Net) EnabledMask(m Marking) Bitset {
+	b := NewBitset(len(net.Tr))
+	for t := range net.Tr {
+		if net.IsEnabled(m, t) {
+			b.Set(t)
+		}
+	}
+	return b
+}
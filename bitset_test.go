@@ -0,0 +1,34 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"os"
+	"slices"
+	"testing"
+)
+
+func TestEnabledMask(t *testing.T) {
+	file, err := os.Open("testdata/sokoban_3.net")
+	if err != nil {
+		t.Fatalf("Error opening file testdata/sokoban_3.net; %s", err)
+	}
+	defer file.Close()
+	net, err := Parse(file)
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/sokoban_3.net; %s", err)
+	}
+	mask := net.EnabledMask(net.Initial)
+	expected := net.AllEnabled(net.Initial)
+	if actual := mask.Slice(); !slices.Equal(actual, expected) {
+		t.Errorf("EnabledMask: expected %v, actual %v", expected, actual)
+	}
+	if mask.Count() != len(expected) {
+		t.Errorf("Count: expected %d, actual %d", len(expected), mask.Count())
+	}
+	if !mask.Equal(append(mask, 0)) {
+		t.Errorf("Equal should ignore trailing empty words")
+	}
+}
@@ -0,0 +1,267 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteBoolEncoding writes on an io.Writer a boolean encoding of the net,
+// suitable for symbolic model checking with BDD or SAT solvers, where the
+// marking of each place is encoded using bitsPerPlace boolean variables. We
+// ignore timing constraints and priorities. This is well-suited for 1-safe
+// nets, with bitsPerPlace equal to 1, but can also be used for bounded nets.
+//
+// The output is made of lines starting with a keyword:
+//
+//   - var p_i_b declares the variables for the bits of place i, where p_i_0 is
+//     the least significant bit (the original name of the place is given in a
+//     comment);
+//   - init gives the initial marking as a conjunction of literals;
+//   - tr t_k gives the transition relation of transition k as a formula over
+//     the variables (for the current marking) and the primed variables, such
+//     as p_i_b', for the marking after firing the transition.
+//
+// Formulas use the constants true and false and the operators ! (negation),
+// & (conjunction), | (disjunction), ^ (exclusive or) and <-> (equivalence),
+// with explicit parentheses. The relation of a transition is the conjunction
+// of its guard, obtained from Cond and Inhib, and of the update of every
+// place, obtained from Delta and Reset. Firing a transition that would produce
+// more tokens than can be stored in a place is blocked. For a transition with
+// probabilistic arcs, the update is the disjunction of the updates obtained
+// for each of its outcomes (see SampleOutcome).
+//
+// We return an error if bitsPerPlace is not between 1 and 30, or if the
+// initial marking of a place, or the weight of an arc, exceeds the value that
+// can be stored in bitsPerPlace bits.
+func (net *Net) WriteBoolEncoding(w io.Writer, bitsPerPlace int) error {
+	if bitsPerPlace < 1 || bitsPerPlace > 30 {
+		return fmt.Errorf("number of bits per place must be between 1 and 30, not %d", bitsPerPlace)
+	}
+	maxv := 1<<bitsPerPlace - 1
+	for _, a := range net.Initial {
+		if a.Mult > maxv {
+			return fmt.Errorf("initial marking of place %s exceeds %d bits", net.Pl[a.Pl], bitsPerPlace)
+		}
+	}
+	for t := range net.Tr {
+		for _, m := range append([]Marking{net.Cond[t]}, net.outcomes(t)...) {
+			for _, a := range m {
+				if a.Mult > maxv || -a.Mult > maxv {
+					return fmt.Errorf("weight of arc between place %s and transition %s exceeds %d bits", net.Pl[a.Pl], net.Tr[t], bitsPerPlace)
+				}
+			}
+		}
+	}
+	bw := bufio.NewWriter(w)
+	if net.Name != "" {
+		fmt.Fprintf(bw, "# net %s\n", net.Name)
+	}
+	fmt.Fprintf(bw, "# %d places, %d transitions, %d bits per place\n", len(net.Pl), len(net.Tr), bitsPerPlace)
+	for p, v := range net.Pl {
+		fmt.Fprint(bw, "var")
+		for b := range bitsPerPlace {
+			fmt.Fprintf(bw, " %s", boolVar(p, b, false))
+		}
+		fmt.Fprintf(bw, " # %s\n", strings.ReplaceAll(v, "\n", " "))
+	}
+	init := []string{}
+	for p := range net.Pl {
+		init = append(init, boolEqualConst(p, bitsPerPlace, net.Initial.Get(p), false))
+	}
+	fmt.Fprintf(bw, "init %s\n", boolAnd(init...))
+	for t := range net.Tr {
+		if net.IsDisabled(t) {
+			continue
+		}
+		rel := []string{}
+		for _, a := range net.Cond[t] {
+			rel = append(rel, boolGeq(a.Pl, bitsPerPlace, a.Mult))
+		}
+		for _, a := range net.Inhib[t] {
+			if a.Mult <= maxv {
+				rel = append(rel, boolNot(boolGeq(a.Pl, bitsPerPlace, a.Mult)))
+			}
+		}
+		update := func(d Marking) []string {
+			res := []string{}
+			for p := range net.Pl {
+				if setMember(net.resets(t), p) >= 0 {
+					res = append(res, boolEqualConst(p, bitsPerPlace, d.Get(p)-net.Pre[t].Get(p), true))
+					continue
+				}
+				res = append(res, boolUpdate(p, bitsPerPlace, d.Get(p)))
+			}
+			return res
+		}
+		if outcomes := net.outcomes(t); len(outcomes) == 1 {
+			rel = append(rel, update(outcomes[0])...)
+		} else {
+			// one alternative for each branch of the probabilistic arcs
+			branches := "false"
+			for _, d := range outcomes {
+				branches = boolOr(branches, boolAnd(update(d)...))
+			}
+			rel = append(rel, branches)
+		}
+		fmt.Fprintf(bw, "tr t_%d %s # %s\n", t, boolAnd(rel...), strings.ReplaceAll(net.Tr[t], "\n", " "))
+	}
+	return bw.Flush()
+}
+
+// boolVar returns the name of the variable for bit b of place p, or of its
+// primed version when next is true.
+func boolVar(p, b int, next bool) string {
+	if next {
+		return fmt.Sprintf("p_%d_%d'", p, b)
+	}
+	return fmt.Sprintf("p_%d_%d", p, b)
+}
+
+// boolEqualConst returns the conjunction of literals stating that the marking
+// of place p, after firing when next is true, is equal to v.
+func boolEqualConst(p, bits, v int, next bool) string {
+	res := make([]string, bits)
+	for b := range bits {
+		res[b] = boolVar(p, b, next)
+		if v&(1<<b) == 0 {
+			res[b] = boolNot(res[b])
+		}
+	}
+	return boolAnd(res...)
+}
+
+// boolGeq returns a formula stating that the marking of place p is greater or
+// equal to v. We compare bits starting from the least significant one: x >= v
+// on bits [0, b] holds when bit b of x is greater than bit b of v, or when they
+// are equal and x >= v on bits [0, b-1].
+func boolGeq(p, bits, v int) string {
+	res := "true"
+	for b := range bits {
+		if v&(1<<b) != 0 {
+			res = boolAnd(boolVar(p, b, false), res)
+		} else {
+			res = boolOr(boolVar(p, b, false), res)
+		}
+	}
+	return res
+}
+
+// boolUpdate returns a formula stating that the marking of place p after
+// firing is equal to its current marking plus d. We use a ripple-carry adder
+// with the constant d, where subtraction is encoded by adding the two's
+// complement of -d. When d is positive, we also require that there is no
+// overflow.
+func boolUpdate(p, bits, d int) string {
+	if d == 0 {
+		res := make([]string, bits)
+		for b := range bits {
+			res[b] = boolIff(boolVar(p, b, true), boolVar(p, b, false))
+		}
+		return boolAnd(res...)
+	}
+	c := d
+	if d < 0 {
+		c = 1<<bits + d
+	}
+	res := []string{}
+	carry := "false"
+	for b := range bits {
+		x := boolVar(p, b, false)
+		if c&(1<<b) != 0 {
+			res = append(res, boolIff(boolVar(p, b, true), boolNot(boolXor(x, carry))))
+			carry = boolOr(x, carry)
+		} else {
+			res = append(res, boolIff(boolVar(p, b, true), boolXor(x, carry)))
+			carry = boolAnd(x, carry)
+		}
+	}
+	if d > 0 {
+		res = append(res, boolNot(carry))
+	}
+	return boolAnd(res...)
+}
+
+// boolNot returns the negation of formula f.
+func boolNot(f string) string {
+	switch f {
+	case "true":
+		return "false"
+	case "false":
+		return "true"
+	}
+	if strings.HasPrefix(f, "!") && !strings.ContainsAny(f, " ") {
+		return f[1:]
+	}
+	return "!" + boolParen(f)
+}
+
+// boolAnd returns the conjunction of formulas fs, simplifying the constants
+// true and false.
+func boolAnd(fs ...string) string {
+	res := []string{}
+	for _, f := range fs {
+		switch f {
+		case "false":
+			return "false"
+		case "true":
+			continue
+		}
+		res = append(res, boolParen(f))
+	}
+	switch len(res) {
+	case 0:
+		return "true"
+	case 1:
+		return res[0]
+	}
+	return strings.Join(res, " & ")
+}
+
+// boolOr returns the disjunction of formulas f1 and f2, simplifying the
+// constants true and false.
+func boolOr(f1, f2 string) string {
+	switch {
+	case f1 == "true" || f2 == "true":
+		return "true"
+	case f1 == "false":
+		return f2
+	case f2 == "false":
+		return f1
+	}
+	return boolParen(f1) + " | " + boolParen(f2)
+}
+
+// boolXor returns the exclusive or of formulas f1 and f2, simplifying the
+// constants true and false.
+func boolXor(f1, f2 string) string {
+	switch {
+	case f1 == "false":
+		return f2
+	case f2 == "false":
+		return f1
+	case f1 == "true":
+		return boolNot(f2)
+	case f2 == "true":
+		return boolNot(f1)
+	}
+	return boolParen(f1) + " ^ " + boolParen(f2)
+}
+
+// boolIff returns the equivalence of formulas f1 and f2.
+func boolIff(f1, f2 string) string {
+	return boolParen(f1) + " <-> " + boolParen(f2)
+}
+
+// boolParen adds parentheses around f unless it is a literal.
+func boolParen(f string) string {
+	if strings.ContainsAny(f, " ") {
+		return "(" + f + ")"
+	}
+	return f
+}
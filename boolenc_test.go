@@ -0,0 +1,116 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// boolEval evaluates a formula, in the syntax used by WriteBoolEncoding, for
+// the valuation env.
+func boolEval(f string, env map[string]bool) bool {
+	tokens := strings.Fields(strings.NewReplacer("(", " ( ", ")", " ) ", "!", " ! ").Replace(f))
+	var expr, primary func() bool
+	primary = func() bool {
+		tok := tokens[0]
+		tokens = tokens[1:]
+		switch tok {
+		case "!":
+			return !primary()
+		case "(":
+			v := expr()
+			tokens = tokens[1:] // closing parenthesis
+			return v
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+		return env[tok]
+	}
+	expr = func() bool {
+		v := primary()
+		for len(tokens) != 0 && tokens[0] != ")" {
+			op := tokens[0]
+			tokens = tokens[1:]
+			v2 := primary()
+			switch op {
+			case "&":
+				v = v && v2
+			case "|":
+				v = v || v2
+			case "^":
+				v = v != v2
+			case "<->":
+				v = v == v2
+			}
+		}
+		return v
+	}
+	return expr()
+}
+
+func TestWriteBoolEncoding(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (2)\ntr t0 p0*2 -> p1\ntr t1 p1 p0?-1 -> p0*3\ntr t2 p0?1 -> p1*2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	const bits = 2
+	var b strings.Builder
+	if err := net.WriteBoolEncoding(&b, bits); err != nil {
+		t.Fatalf("WriteBoolEncoding: %s", err)
+	}
+	rel := map[string]string{}
+	for _, line := range strings.Split(b.String(), "\n") {
+		line, _, _ = strings.Cut(line, " #")
+		switch {
+		case strings.HasPrefix(line, "init "):
+			rel["init"] = strings.TrimPrefix(line, "init ")
+		case strings.HasPrefix(line, "tr "):
+			name, f, _ := strings.Cut(strings.TrimPrefix(line, "tr "), " ")
+			rel[name] = f
+		}
+	}
+	valuation := func(m, m2 []int) map[string]bool {
+		env := map[string]bool{}
+		for p := range m {
+			for b := range bits {
+				env[boolVar(p, b, false)] = m[p]&(1<<b) != 0
+				env[boolVar(p, b, true)] = m2[p]&(1<<b) != 0
+			}
+		}
+		return env
+	}
+	// we check the transition relation for every pair of markings
+	for v := range 1 << (4 * bits) {
+		m := []int{v & 3, (v >> 2) & 3}
+		m2 := []int{(v >> 4) & 3, (v >> 6) & 3}
+		env := valuation(m, m2)
+		if actual, expected := boolEval(rel["init"], env), m[0] == 2 && m[1] == 0; actual != expected {
+			t.Errorf("Wrong initial marking for %v: expected %v, actual %v", m, expected, actual)
+		}
+		mk := Marking{}
+		for p := range m {
+			if m[p] != 0 {
+				mk = append(mk, Atom{p, m[p]})
+			}
+		}
+		for tr := range net.Tr {
+			next := mk.Add(net.Delta[tr])
+			expected := net.IsEnabled(mk, tr) && next.Get(0) == m2[0] && next.Get(1) == m2[1]
+			if actual := boolEval(rel[fmt.Sprintf("t_%d", tr)], env); actual != expected {
+				t.Errorf("Wrong relation for t%d from %v to %v: expected %v, actual %v", tr, m, m2, expected, actual)
+			}
+		}
+	}
+	if err := net.WriteBoolEncoding(&b, 1); err == nil {
+		t.Errorf("WriteBoolEncoding: expected error when marking exceeds bit width")
+	}
+	if err := net.WriteBoolEncoding(&b, 0); err == nil {
+		t.Errorf("WriteBoolEncoding: expected error with null bit width")
+	}
+}
@@ -0,0 +1,349 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// NewNet returns an empty net with the given name. Places and transitions can
+// then be added using methods AddPlace and AddTransition, and arcs using
+// methods AddArc, AddReadArc and AddInhibitorArc.
+func NewNet(name string) *Net {
+	return &Net{Name: name, plIndex: map[string]int{}, trIndex: map[string]int{}}
+}
+
+// AddPlace adds a place to the net, with the given name, label and initial
+// marking, and returns its index. Use the empty string when the place has no
+// label. If the net already has a place with the same name, we return its
+// index and leave the place unchanged.
+func (net *Net) AddPlace(name, label string, initial int) int {
+	if p, ok := net.PlaceIndex(name); ok {
+		return p
+	}
+	net.Pl = append(net.Pl, name)
+	net.Plabel = append(net.Plabel, label)
+	p := len(net.Pl) - 1
+	if net.plIndex == nil {
+		net.indexNodes()
+	} else {
+		net.plIndex[name] = p
+	}
+	net.Initial = net.Initial.AddToPlace(p, initial)
+	return p
+}
+
+// AddTransition adds a transition to the net, with the given name, label and
+// time interval, and returns its index. The transition has no arcs and no
+// priorities. Use the empty string when the transition has no label, and the
+// value TimeInterval{} (or NewUnboundedInterval(0, true)) for the default
+// interval [0,w[. If the net already has a transition with the same name, we
+// return its index and leave the transition unchanged.
+func (net *Net) AddTransition(name, label string, i TimeInterval) int {
+	if t, ok := net.TransitionIndex(name); ok {
+		return t
+	}
+	t := net.addTransition(name)
+	net.Tlabel[t] = label
+	if i.Left.Bkind != BINFTY {
+		net.Time[t] = i
+	}
+	return t
+}
+
+// AddArc adds an arc, with the given weight, between the node named from and
+// the node named to, which must be an existing place and transition, in any
+// order. An arc from a place to a transition is an input arc, that consumes
+// weight tokens, and an arc from a transition to a place is an output arc,
+// that produces weight tokens. Like when parsing a .net file, adding several
+// arcs between the same nodes is the same as adding one arc with the sum of
+// their weights. Since a place and a transition may have the same name, we
+// check the case of an input arc first.
+//
+// We return an error if the weight is less than 1, if a node does not exist,
+// or if we try to link two places or two transitions.
+func (net *Net) AddArc(from, to string, weight int) error {
+	if weight < 1 {
+		return fmt.Errorf("weight of arc from %s to %s must be positive, not %d", from, to, weight)
+	}
+	p, fromPl := net.PlaceIndex(from)
+	t, toTr := net.TransitionIndex(to)
+	if fromPl && toTr {
+		net.Delta[t] = net.Delta[t].AddToPlace(p, -weight)
+		net.Pre[t] = net.Pre[t].AddToPlace(p, -weight)
+		net.Cond[t] = net.Cond[t].AddToPlace(p, weight)
+		return nil
+	}
+	t, fromTr := net.TransitionIndex(from)
+	p, toPl := net.PlaceIndex(to)
+	if fromTr && toPl {
+		net.Delta[t] = net.Delta[t].AddToPlace(p, weight)
+		return nil
+	}
+	switch {
+	case !fromPl && !fromTr:
+		return fmt.Errorf("unknown node %s in arc", from)
+	case !toPl && !toTr:
+		return fmt.Errorf("unknown node %s in arc", to)
+	case fromPl:
+		return fmt.Errorf("cannot add an arc between two places, %s and %s", from, to)
+	default:
+		return fmt.Errorf("cannot add an arc between two transitions, %s and %s", from, to)
+	}
+}
+
+// AddReadArc adds a read arc (also called test arc) between place and trans,
+// meaning that the transition can fire only if the place has at least weight
+// tokens, but does not consume them. When there are several read arcs between
+// the same nodes, we keep the one with the largest weight, like when parsing a
+// .net file. We return an error if the weight is less than 1 or if a node does
+// not exist.
+func (net *Net) AddReadArc(trans, place string, weight int) error {
+	t, p, err := net.arcNodes(trans, place, weight)
+	if err != nil {
+		return err
+	}
+	net.Cond[t] = net.Cond[t].updateIfGreater(p, weight)
+	return nil
+}
+
+// AddInhibitorArc adds an inhibitor arc between place and trans, meaning that
+// the transition can fire only if the place has less than weight tokens. When
+// there are several inhibitor arcs between the same nodes, we keep the one with
+// the smallest weight, like when parsing a .net file. We return an error if
+// the weight is less than 1 or if a node does not exist.
+func (net *Net) AddInhibitorArc(trans, place string, weight int) error {
+	t, p, err := net.arcNodes(trans, place, weight)
+	if err != nil {
+		return err
+	}
+	net.Inhib[t] = net.Inhib[t].updateIfLess(p, weight)
+	return nil
+}
+
+// arcNodes returns the indexes of transition trans and place place, or an
+// error if they do not exist or if weight is less than 1.
+func (net *Net) arcNodes(trans, place string, weight int) (int, int, error) {
+	if weight < 1 {
+		return -1, -1, fmt.Errorf("weight of arc between %s and %s must be positive, not %d", place, trans, weight)
+	}
+	t, ok := net.TransitionIndex(trans)
+	if !ok {
+		return -1, -1, fmt.Errorf("unknown transition %s in arc", trans)
+	}
+	p, ok := net.PlaceIndex(place)
+	if !ok {
+		return -1, -1, fmt.Errorf("unknown place %s in arc", place)
+	}
+	return t, p, nil
+}
+
+// PlaceIndex returns the index of the place with the given name, and false if
+// there is no such place. We use a map from names to indexes, stored in the
+// net, so that lookups of existing places take constant time. The map is built
+// when the net is created, for instance by Parse or NewNet, and it is kept
+// up-to-date by the methods that add, rename or remove places. When the map is
+// stale, for instance after modifying the slice Pl directly, we fall back to a
+// linear scan, so the result always reflects the net at call time. Looking up
+// a name that is not in the net also requires a linear scan. PlaceIndex never
+// modifies the net, so it is safe to call it from several goroutines, as long
+// as the net is not modified concurrently.
+func (net *Net) PlaceIndex(name string) (int, bool) {
+	return lookupIndex(net.plIndex, net.Pl, name)
+}
+
+// TransitionIndex returns the index of the transition with the given name, and
+// false if there is no such transition. It uses a map stored in the net, in
+// the same way as PlaceIndex.
+func (net *Net) TransitionIndex(name string) (int, bool) {
+	return lookupIndex(net.trIndex, net.Tr, name)
+}
+
+// RenamePlace changes the name of place from into to. Since arcs and markings
+// refer to places by their index, only the name of the place changes. We also
+// update the color of the place (see NodeColor) and the color notes that refer
+// to it, so that printing the net gives the same result, with the new name. We
+// return an error if from is not a place of the net, or if to is empty or is
+// already the name of another place.
+func (net *Net) RenamePlace(from, to string) error {
+	p, ok := net.PlaceIndex(from)
+	if !ok {
+		return fmt.Errorf("cannot rename unknown place %s", from)
+	}
+	if err := checkRename(net.Pl, "place", from, to); err != nil {
+		return err
+	}
+	net.Pl[p] = to
+	if net.plIndex != nil {
+		delete(net.plIndex, from)
+		net.plIndex[to] = p
+	}
+	net.renameInNotes(renameFunc(from, to), "color")
+	return nil
+}
+
+// RenameTransition changes the name of transition from into to, in the same
+// way as RenamePlace. We also update the server notes that refer to the
+// transition.
+func (net *Net) RenameTransition(from, to string) error {
+	t, ok := net.TransitionIndex(from)
+	if !ok {
+		return fmt.Errorf("cannot rename unknown transition %s", from)
+	}
+	if err := checkRename(net.Tr, "transition", from, to); err != nil {
+		return err
+	}
+	net.Tr[t] = to
+	if net.trIndex != nil {
+		delete(net.trIndex, from)
+		net.trIndex[to] = t
+	}
+	net.renameInNotes(renameFunc(from, to), "color", "server")
+	return nil
+}
+
+// checkRename returns an error if name to cannot be used to rename node from,
+// meaning that it is empty or already used in names.
+func checkRename(names []string, kind, from, to string) error {
+	if to == "" {
+		return fmt.Errorf("cannot rename %s %s with an empty name", kind, from)
+	}
+	if to != from && slices.Contains(names, to) {
+		return fmt.Errorf("cannot rename %s %s, name %s is already used", kind, from, to)
+	}
+	return nil
+}
+
+// renameFunc returns a function that maps from to to, and is the identity
+// otherwise.
+func renameFunc(from, to string) func(string) string {
+	return func(s string) string {
+		if s == from {
+			return to
+		}
+		return s
+	}
+}
+
+// renameInNotes replaces every node name s with f(s) in the colors of nodes,
+// and in the notes with one of the given names that have a body of the form
+// {s value}, such as color and server notes.
+func (net *Net) renameInNotes(f func(string) string, notes ...string) {
+	if net.NodeColor != nil {
+		colors := make(map[string]string, len(net.NodeColor))
+		for k, v := range net.NodeColor {
+			colors[f(k)] = v
+		}
+		net.NodeColor = colors
+	}
+	for k, n := range net.Notes {
+		if !slices.Contains(notes, n.Name) {
+			continue
+		}
+		if node, value, ok := noteNode(n); ok {
+			net.Notes[k].Body = "{" + f(node) + " " + value + "}"
+		}
+	}
+}
+
+// dropStaleNotes removes the colors of nodes (see NodeColor), and the color
+// and server notes, that refer to a node that is no longer in the net, for
+// instance after removing a place. Since a place and a transition may have the
+// same name, a color is kept as long as one of them is in the net.
+func (net *Net) dropStaleNotes() {
+	exists := func(name string) bool {
+		if _, ok := net.PlaceIndex(name); ok {
+			return true
+		}
+		_, ok := net.TransitionIndex(name)
+		return ok
+	}
+	for k := range net.NodeColor {
+		if !exists(k) {
+			delete(net.NodeColor, k)
+		}
+	}
+	net.Notes = slices.DeleteFunc(net.Notes, func(n Note) bool {
+		node, _, ok := noteNode(n)
+		if !ok {
+			return false
+		}
+		switch n.Name {
+		case "color":
+			return !exists(node)
+		case "server":
+			_, ok := net.TransitionIndex(node)
+			return !ok
+		}
+		return false
+	})
+}
+
+// noteNode returns the node name and the value in the body of note n when it
+// is of the form {node value}, such as with color and server notes.
+func noteNode(n Note) (string, string, bool) {
+	if !strings.HasPrefix(n.Body, "{") || !strings.HasSuffix(n.Body, "}") {
+		return "", "", false
+	}
+	arr := strings.Fields(n.Body[1 : len(n.Body)-1])
+	if len(arr) != 2 {
+		return "", "", false
+	}
+	return arr[0], arr[1], true
+}
+
+// TransitionsWithLabel returns the sorted list of transitions with the given
+// label. Labels are compared as they are stored in the net, meaning that a
+// label between braces in the .net file, such as {b s}, must be given with its
+// braces. Since the empty string is used for transitions without a label,
+// TransitionsWithLabel("") returns all the transitions without a label.
+func (net *Net) TransitionsWithLabel(label string) []int {
+	return withLabel(net.Tlabel, label)
+}
+
+// PlacesWithLabel returns the sorted list of places with the given label, in
+// the same way as TransitionsWithLabel.
+func (net *Net) PlacesWithLabel(label string) []int {
+	return withLabel(net.Plabel, label)
+}
+
+// withLabel returns the list of indexes k such that labels[k] is label.
+func withLabel(labels []string, label string) []int {
+	res := []int{}
+	for k, v := range labels {
+		if v == label {
+			res = append(res, k)
+		}
+	}
+	return res
+}
+
+// lookupIndex returns the index of name in names using the map index, or a
+// linear scan when index is missing or stale. We never modify index.
+func lookupIndex(index map[string]int, names []string, name string) (int, bool) {
+	if k, ok := index[name]; ok && k < len(names) && names[k] == name {
+		return k, true
+	}
+	k := slices.Index(names, name)
+	return k, k >= 0
+}
+
+// indexNodes builds the maps used by PlaceIndex and TransitionIndex. It must
+// be called when a net is created, and after operations that change the index
+// of several nodes, such as removing a place.
+func (net *Net) indexNodes() {
+	index := func(names []string) map[string]int {
+		m := make(map[string]int, len(names))
+		for i, v := range names {
+			if _, ok := m[v]; !ok {
+				m[v] = i
+			}
+		}
+		return m
+	}
+	net.plIndex, net.trIndex = index(net.Pl), index(net.Tr)
+}
@@ -0,0 +1,229 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestBuilder(t *testing.T) {
+	net := NewNet("test")
+	p0 := net.AddPlace("p0", "", 2)
+	p1 := net.AddPlace("p1", "a", 0)
+	if p0 != 0 || p1 != 1 {
+		t.Errorf("AddPlace: expected indexes 0 and 1, actual %d and %d", p0, p1)
+	}
+	if p := net.AddPlace("p0", "b", 5); p != p0 || net.Plabel[p0] != "" || net.Initial.Get(p0) != 2 {
+		t.Errorf("AddPlace: existing place should be left unchanged")
+	}
+	i, _ := NewInterval(1, 2, true, false)
+	t0 := net.AddTransition("t0", "", TimeInterval{})
+	t1 := net.AddTransition("t1", "c", i)
+	if t0 != 0 || t1 != 1 {
+		t.Errorf("AddTransition: expected indexes 0 and 1, actual %d and %d", t0, t1)
+	}
+	if tr := net.AddTransition("t1", "", TimeInterval{}); tr != t1 || net.Time[t1] != i {
+		t.Errorf("AddTransition: existing transition should be left unchanged")
+	}
+	if !net.Time[t0].Trivial() || net.Time[t0].Left.Bkind != BCLOSE {
+		t.Errorf("AddTransition: expected default interval [0,w[, actual %s", net.Time[t0].String())
+	}
+	if err := net.checkConsistency(); err != nil {
+		t.Errorf("Builder: inconsistent net; %s", err)
+	}
+}
+
+func TestBuilderArcs(t *testing.T) {
+	expected, err := Parse(strings.NewReader("net test\npl p0 (1)\npl p1\ntr t0 p0*2 p1?3 p1?2 p0?-5 p0?-4 -> p1 p1*2\ntr t1 p1 p1 -> p0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	net := NewNet("test")
+	net.AddPlace("p0", "", 1)
+	net.AddPlace("p1", "", 0)
+	net.AddTransition("t0", "", TimeInterval{})
+	net.AddTransition("t1", "", TimeInterval{})
+	for _, err := range []error{
+		net.AddArc("p0", "t0", 2),
+		net.AddReadArc("t0", "p1", 3),
+		net.AddReadArc("t0", "p1", 2),
+		net.AddInhibitorArc("t0", "p0", 5),
+		net.AddInhibitorArc("t0", "p0", 4),
+		net.AddArc("t0", "p1", 1),
+		net.AddArc("t0", "p1", 2),
+		net.AddArc("p1", "t1", 1),
+		net.AddArc("p1", "t1", 1),
+		net.AddArc("t1", "p0", 1),
+	} {
+		if err != nil {
+			t.Fatalf("Error adding arc; %s", err)
+		}
+	}
+	if err := expected.compare(net); err != nil {
+		t.Errorf("Builder: net different from the parsed one; %s", err)
+	}
+	for _, err := range []error{
+		net.AddArc("p0", "p1", 1),
+		net.AddArc("t0", "t1", 1),
+		net.AddArc("p0", "t2", 1),
+		net.AddArc("p0", "t0", 0),
+		net.AddReadArc("t0", "p2", 1),
+		net.AddReadArc("t0", "p0", 0),
+		net.AddInhibitorArc("t2", "p0", 1),
+		net.AddInhibitorArc("t0", "p0", -1),
+	} {
+		if err == nil {
+			t.Errorf("Builder: expected error when adding a bad arc")
+		}
+	}
+}
+
+func TestPlaceIndex(t *testing.T) {
+	net, err := ParseFile("testdata/sokoban_3.net")
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/sokoban_3.net; %s", err)
+	}
+	for k, v := range net.Pl {
+		if p, ok := net.PlaceIndex(v); !ok || p != k {
+			t.Fatalf("PlaceIndex(%s): expected %d, actual %d", v, k, p)
+		}
+	}
+	for k, v := range net.Tr {
+		if tr, ok := net.TransitionIndex(v); !ok || tr != k {
+			t.Fatalf("TransitionIndex(%s): expected %d, actual %d", v, k, tr)
+		}
+	}
+	if _, ok := net.PlaceIndex("unknown"); ok {
+		t.Errorf("PlaceIndex: unexpected result for unknown place")
+	}
+	// the index must follow changes made with the builder and directly
+	p := net.AddPlace("new", "", 0)
+	if k, ok := net.PlaceIndex("new"); !ok || k != p {
+		t.Errorf("PlaceIndex(new): expected %d, actual %d", p, k)
+	}
+	tr := net.AddTransition("tnew", "", TimeInterval{})
+	if k, ok := net.TransitionIndex("tnew"); !ok || k != tr {
+		t.Errorf("TransitionIndex(tnew): expected %d, actual %d", tr, k)
+	}
+	old := net.Pl[0]
+	net.Pl[0] = "renamed"
+	if k, ok := net.PlaceIndex("renamed"); !ok || k != 0 {
+		t.Errorf("PlaceIndex(renamed): expected 0, actual %d", k)
+	}
+	if _, ok := net.PlaceIndex(old); ok {
+		t.Errorf("PlaceIndex(%s): unexpected result for renamed place", old)
+	}
+	// removing a place shifts the index of the following ones
+	if err := net.MergePlaces(1, 0); err != nil {
+		t.Fatalf("Error in MergePlaces; %s", err)
+	}
+	for k, v := range net.Pl {
+		if p, ok := net.PlaceIndex(v); !ok || p != k {
+			t.Fatalf("PlaceIndex(%s): expected %d after MergePlaces, actual %d", v, k, p)
+		}
+	}
+	// lookups never modify the net, so they can run concurrently (check with
+	// go test -race)
+	net, err = ParseFile("testdata/sokoban_3.net")
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/sokoban_3.net; %s", err)
+	}
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for k, v := range net.Pl {
+				if p, ok := net.PlaceIndex(v); !ok || p != k {
+					t.Errorf("PlaceIndex(%s): expected %d, actual %d", v, k, p)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkPlaceIndex(b *testing.B) {
+	net, err := ParseFile("testdata/sokoban_3.net")
+	if err != nil {
+		b.Fatalf("Error parsing file testdata/sokoban_3.net; %s", err)
+	}
+	name := net.Pl[len(net.Pl)-1]
+	b.ResetTimer()
+	for range b.N {
+		net.PlaceIndex(name)
+	}
+}
+
+func TestWithLabel(t *testing.T) {
+	net, err := ParseFile("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	tables := []struct {
+		actual, expected []int
+	}{
+		{net.TransitionsWithLabel("a"), []int{1}},
+		{net.TransitionsWithLabel("{b s}"), []int{6}},
+		{net.TransitionsWithLabel(""), []int{0, 2, 4, 5}},
+		{net.TransitionsWithLabel("c"), []int{}},
+		{net.PlacesWithLabel("b"), []int{2}},
+		{net.PlacesWithLabel(""), []int{0, 1, 3}},
+	}
+	for k, v := range tables {
+		if !slices.Equal(v.actual, v.expected) {
+			t.Errorf("WithLabel (case %d): expected %v, actual %v", k, v.expected, v.actual)
+		}
+	}
+	if net.Tr[1] != "t0" {
+		t.Errorf("Transition with label a should be t0, actual %s", net.Tr[1])
+	}
+}
+
+func TestRename(t *testing.T) {
+	net, err := ParseString("pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p0\nnt color 0 {p0 red}\nnt server 0 {t0 infinite}\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if _, ok := net.PlaceIndex("p0"); !ok {
+		t.Fatalf("PlaceIndex: place p0 not found")
+	}
+	if err := net.RenamePlace("p0", "q0"); err != nil {
+		t.Fatalf("RenamePlace: %s", err)
+	}
+	if err := net.RenameTransition("t0", "u0"); err != nil {
+		t.Fatalf("RenameTransition: %s", err)
+	}
+	if p, ok := net.PlaceIndex("q0"); !ok || p != 0 {
+		t.Errorf("PlaceIndex: expected q0 at index 0, actual %d", p)
+	}
+	if _, ok := net.PlaceIndex("p0"); ok {
+		t.Errorf("PlaceIndex: place p0 should not exist after renaming")
+	}
+	expected, err := ParseString("pl q0 (1)\ntr u0 q0 -> p1\ntr t1 p1 -> q0\nnt color 0 {q0 red}\nnt server 0 {u0 infinite}\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if err := expected.compare(net); err != nil {
+		t.Errorf("Rename: %s\n%s", err, net.String())
+	}
+	if err := net.SelfCheck(); err != nil {
+		t.Errorf("Rename: %s", err)
+	}
+	for _, err := range []error{
+		net.RenamePlace("p0", "p2"),
+		net.RenamePlace("q0", "p1"),
+		net.RenamePlace("q0", ""),
+		net.RenameTransition("t0", "t2"),
+		net.RenameTransition("t1", "u0"),
+	} {
+		if err == nil {
+			t.Errorf("Rename: expected an error")
+		}
+	}
+}
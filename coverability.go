@@ -0,0 +1,140 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Omega is the multiplicity used for ω in the markings of a coverability
+// graph, meaning that a place can hold an arbitrarily large number of tokens.
+// An atom {p, Omega} stands for ω tokens in place p. Since Omega is the
+// largest int, the order on multiplicities used by Compare, LessEq and Covers
+// extends naturally to ω, which is greater than every finite value. Adding or
+// removing tokens from a place with ω tokens gives ω, which is taken care of
+// when building the graph, but not by methods such as Add or AddToPlace.
+// Function Mtoa prints ω as w, such as in "p0 p1*w".
+const Omega = math.MaxInt
+
+// CoverGraph is the coverability graph of a net, as computed by
+// CoverabilityGraph. States are identified by their index in Markings, the
+// initial marking has index 0, and Edges[s] lists the transitions enabled at
+// state s together with the index of the resulting state. Markings may contain
+// the multiplicity Omega.
+type CoverGraph struct {
+	Markings []Marking
+	Edges    [][]CoverEdge
+}
+
+// CoverEdge is an edge of a coverability graph, meaning that firing
+// transition T leads to the state with index Dst.
+type CoverEdge struct {
+	T, Dst int
+}
+
+// CoverabilityGraph returns the coverability graph of the net, ignoring timing
+// constraints and priorities, using the construction of Karp and Miller. We
+// explore the markings reachable from the initial marking, like with
+// Reachable, but when we find a marking m' that is strictly greater than one
+// of its ancestors m (in the tree of markings explored so far), we replace the
+// multiplicity of every place p such that m(p) < m'(p) with Omega, since we
+// can repeat the sequence of transitions from m to m' to put as many tokens in
+// p as we want. Nodes with the same marking are merged, and we do not explore
+// a marking twice. A transition with probabilistic arcs has one edge for each
+// of its outcomes. The result is always finite, even when the net is
+// unbounded, and a marking m is coverable in the net if and only if some
+// marking of the graph covers m (see Covers).
+//
+// This construction is not correct for nets with inhibitor or reset arcs, for
+// which coverability is undecidable (or cannot be decided this way), so we
+// return an error in this case. We also return an error if the initial marking
+// has a negative multiplicity.
+func (net *Net) CoverabilityGraph() (*CoverGraph, error) {
+	for t := range net.Tr {
+		if len(net.Inhib[t]) != 0 {
+			return nil, fmt.Errorf("cannot build coverability graph, transition %s has inhibitor arcs", net.Tr[t])
+		}
+		if len(net.resets(t)) != 0 {
+			return nil, fmt.Errorf("cannot build coverability graph, transition %s has reset arcs", net.Tr[t])
+		}
+	}
+	for _, a := range net.Initial {
+		if a.Mult < 0 {
+			return nil, fmt.Errorf("negative multiplicity for place %s in initial marking", net.Pl[a.Pl])
+		}
+	}
+	g := &CoverGraph{}
+	parent := []int{}
+	visited := make(map[string]int)
+	add := func(m Marking, from int) int {
+		key := omegaKey(m)
+		if s, ok := visited[key]; ok {
+			return s
+		}
+		s := len(g.Markings)
+		visited[key] = s
+		g.Markings = append(g.Markings, m)
+		g.Edges = append(g.Edges, nil)
+		parent = append(parent, from)
+		return s
+	}
+	add(net.Initial, -1)
+	for s := 0; s < len(g.Markings); s++ {
+		m := g.Markings[s]
+		for _, t := range net.AllEnabled(m) {
+			for _, d := range net.outcomes(t) {
+				m2 := fireOmega(m, d)
+				// we look for ancestors of m2 (including m) that it covers
+				for a := s; a >= 0; a = parent[a] {
+					if m1 := g.Markings[a]; m1.Compare(m2) == -1 {
+						m2 = accelerate(m1, m2)
+					}
+				}
+				g.Edges[s] = append(g.Edges[s], CoverEdge{t, add(m2, s)})
+			}
+		}
+	}
+	return g, nil
+}
+
+// fireOmega returns the marking obtained by adding delta, the effect of firing
+// a transition (see outcomes), to marking m, where places with Omega tokens
+// keep Omega tokens.
+func fireOmega(m Marking, delta Marking) Marking {
+	res := Marking{}
+	for _, a := range delta {
+		if m.Get(a.Pl) != Omega {
+			res = append(res, a)
+		}
+	}
+	return m.Add(res)
+}
+
+// accelerate returns a copy of m2 where every place with more tokens than in
+// m1 has Omega tokens. We assume that m1 is less than m2.
+func accelerate(m1, m2 Marking) Marking {
+	res := make(Marking, len(m2))
+	for k, a := range m2 {
+		if a.Mult > m1.Get(a.Pl) {
+			a.Mult = Omega
+		}
+		res[k] = a
+	}
+	return res
+}
+
+// omegaKey returns a string that identifies marking m, used to store the set
+// of markings of a coverability graph. Unlike with Unique, multiplicities can
+// be Omega.
+func omegaKey(m Marking) string {
+	buf := make([]byte, 0, 4*len(m))
+	for _, a := range m {
+		buf = binary.AppendUvarint(buf, uint64(a.Pl))
+		buf = binary.AppendUvarint(buf, uint64(a.Mult))
+	}
+	return string(buf)
+}
@@ -0,0 +1,108 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCoverabilityGraph(t *testing.T) {
+	tables := []struct {
+		input    string
+		markings []string
+	}{
+		{"pl p0 (1)\ntr t0 p0 -> p0 p1\ntr t1 p1 ->\n", []string{"p0", "p0 p1*w"}},
+		{"pl p0 (1)\ntr t0 p0 -> p1 p2\ntr t1 p1 -> p0\n", []string{"p0", "p1 p2", "p0 p2*w", "p1 p2*w"}},
+		{"pl p0 (2)\ntr t0 p0*2 -> p1\ntr t1 p1 -> p0\n", []string{"p0*2", "p1", "p0"}},
+		// every branch of a probabilistic transition is explored
+		{"tr t -> p%1.0\n", []string{"", "p*w"}},
+		{"pl p0 (1)\ntr t p0 -> p1%0.5 p2%0.5\n", []string{"p0", "p1", "p2"}},
+	}
+	for _, v := range tables {
+		net, err := ParseWithOptions(strings.NewReader(v.input), ParseOptions{Probabilities: true})
+		if err != nil {
+			t.Fatalf("Error parsing net; %s", err)
+		}
+		g, err := net.CoverabilityGraph()
+		if err != nil {
+			t.Fatalf("CoverabilityGraph: %s", err)
+		}
+		actual := []string{}
+		for _, m := range g.Markings {
+			actual = append(actual, net.Mtoa(m))
+		}
+		if !slices.Equal(actual, v.markings) {
+			t.Errorf("CoverabilityGraph(%q): expected %q, actual %q", v.input, v.markings, actual)
+		}
+		for s, edges := range g.Edges {
+			for _, e := range edges {
+				covered := slices.ContainsFunc(net.outcomes(e.T), func(d Marking) bool {
+					return fireOmega(g.Markings[s], d).LessEq(g.Markings[e.Dst])
+				})
+				if !net.IsEnabled(g.Markings[s], e.T) || !covered {
+					t.Errorf("CoverabilityGraph(%q): wrong edge %s -%s-> %s", v.input, actual[s], net.Tr[e.T], actual[e.Dst])
+				}
+			}
+		}
+	}
+	// for a bounded net, we get the reachability graph
+	net, err := ParseFile("testdata/ifip.net")
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	g, err := net.CoverabilityGraph()
+	if err != nil {
+		t.Fatalf("CoverabilityGraph: %s", err)
+	}
+	reach, err := net.Reachable(1000)
+	if err != nil {
+		t.Fatalf("Reachable: %s", err)
+	}
+	if !slices.EqualFunc(g.Markings, reach, Marking.Equal) {
+		t.Errorf("CoverabilityGraph: expected %v, actual %v", reach, g.Markings)
+	}
+	// without timing constraints, abp.net is unbounded
+	net, err = ParseFile("testdata/abp.net")
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	if g, err = net.CoverabilityGraph(); err != nil {
+		t.Fatalf("CoverabilityGraph: %s", err)
+	}
+	omega := false
+	for _, m := range g.Markings {
+		for _, a := range m {
+			omega = omega || a.Mult == Omega
+		}
+	}
+	if !omega {
+		t.Errorf("CoverabilityGraph: expected a marking with ω in abp.net")
+	}
+	for _, input := range []string{"tr t0 p0?-1 -> p0\n", "tr t0 p0?! -> p1\n"} {
+		net, err := ParseString(input)
+		if err != nil {
+			t.Fatalf("Error parsing net; %s", err)
+		}
+		if _, err := net.CoverabilityGraph(); err == nil {
+			t.Errorf("CoverabilityGraph(%q): expected an error", input)
+		}
+	}
+}
+
+func TestOmegaString(t *testing.T) {
+	net, err := ParseString("tr t0 p0 -> p1\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	m, err := net.Atoma("p1*w p0*2 p1")
+	if err != nil {
+		t.Fatalf("Atoma: %s", err)
+	}
+	if !m.Equal(Marking{{0, 2}, {1, Omega}}) || net.Mtoa(m) != "p0*2 p1*w" {
+		t.Errorf("Atoma: wrong marking %v", m)
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NetDiff is the result of comparing two nets with Diff. Nodes are given by
+// their names, in lexicographic order, so that the result does not depend on
+// the order of declarations in the nets.
+type NetDiff struct {
+	AddedPlaces, RemovedPlaces           []string
+	AddedTransitions, RemovedTransitions []string
+	Markings                             []Change // places with a different initial marking
+	Intervals                            []Change // transitions with a different time interval
+	Arcs                                 []Change // transitions with different arcs
+}
+
+// Change describes the difference for a node that is in both nets compared
+// with Diff. Old and New are the textual representations of the value in the
+// original net and in the other net, for instance "[0,1]" and "[0,2]" for a
+// time interval. Arcs of a transition are printed like in a tr declaration,
+// such as "p0*2 p1?1 -> p2", with places listed in lexicographic order.
+type Change struct {
+	Name, Old, New string
+}
+
+// Diff compares the structure of the net with the one of other, using the
+// names of places and transitions to match nodes. We list the places and
+// transitions that are only in other (added) or only in net (removed), and for
+// the nodes in both nets, the places with a different initial marking and the
+// transitions with different time intervals or arcs. We do not compare labels,
+// priorities, and notes.
+func (net *Net) Diff(other *Net) NetDiff {
+	var d NetDiff
+	for _, v := range sortedNames(net.Pl) {
+		p, _ := net.PlaceIndex(v)
+		q, ok := other.PlaceIndex(v)
+		if !ok {
+			d.RemovedPlaces = append(d.RemovedPlaces, v)
+			continue
+		}
+		if m1, m2 := net.Initial.Get(p), other.Initial.Get(q); m1 != m2 {
+			d.Markings = append(d.Markings, Change{v, fmt.Sprint(m1), fmt.Sprint(m2)})
+		}
+	}
+	for _, v := range sortedNames(other.Pl) {
+		if _, ok := net.PlaceIndex(v); !ok {
+			d.AddedPlaces = append(d.AddedPlaces, v)
+		}
+	}
+	for _, v := range sortedNames(net.Tr) {
+		t, _ := net.TransitionIndex(v)
+		u, ok := other.TransitionIndex(v)
+		if !ok {
+			d.RemovedTransitions = append(d.RemovedTransitions, v)
+			continue
+		}
+		if i1, i2 := net.Time[t], other.Time[u]; i1 != i2 {
+			d.Intervals = append(d.Intervals, Change{v, i1.String(), i2.String()})
+		}
+		if a1, a2 := net.arcsString(t), other.arcsString(u); a1 != a2 {
+			d.Arcs = append(d.Arcs, Change{v, a1, a2})
+		}
+	}
+	for _, v := range sortedNames(other.Tr) {
+		if _, ok := net.TransitionIndex(v); !ok {
+			d.AddedTransitions = append(d.AddedTransitions, v)
+		}
+	}
+	return d
+}
+
+// Empty reports whether the two nets compared with Diff have the same
+// structure, meaning that no difference was found.
+func (d NetDiff) Empty() bool {
+	return len(d.AddedPlaces) == 0 && len(d.RemovedPlaces) == 0 &&
+		len(d.AddedTransitions) == 0 && len(d.RemovedTransitions) == 0 &&
+		len(d.Markings) == 0 && len(d.Intervals) == 0 && len(d.Arcs) == 0
+}
+
+// String returns a textual representation of the differences, with one line
+// for each difference. Lines start with + for added nodes, - for removed
+// nodes, and ~ for nodes that have changed, followed by the old and the new
+// value separated by =>. For instance, "+ pl p5" means that place p5 has been
+// added, and "~ tr t1 [0,1] => [0,2]" that the time interval of transition t1
+// has changed from [0,1] to [0,2].
+func (d NetDiff) String() string {
+	var b strings.Builder
+	for _, v := range d.AddedPlaces {
+		fmt.Fprintf(&b, "+ pl %s\n", v)
+	}
+	for _, v := range d.RemovedPlaces {
+		fmt.Fprintf(&b, "- pl %s\n", v)
+	}
+	for _, v := range d.AddedTransitions {
+		fmt.Fprintf(&b, "+ tr %s\n", v)
+	}
+	for _, v := range d.RemovedTransitions {
+		fmt.Fprintf(&b, "- tr %s\n", v)
+	}
+	for _, c := range d.Markings {
+		fmt.Fprintf(&b, "~ pl %s (%s) => (%s)\n", c.Name, c.Old, c.New)
+	}
+	for _, c := range d.Intervals {
+		fmt.Fprintf(&b, "~ tr %s %s => %s\n", c.Name, c.Old, c.New)
+	}
+	for _, c := range d.Arcs {
+		fmt.Fprintf(&b, "~ tr %s %s => %s\n", c.Name, c.Old, c.New)
+	}
+	return b.String()
+}
+
+// sortedNames returns a sorted copy of names.
+func sortedNames(names []string) []string {
+	return slices.Sorted(slices.Values(names))
+}
+
+// arcsString returns a textual representation of the arcs of transition t,
+// like in a tr declaration but with places in lexicographic order.
+// Probabilistic arcs are printed with their probability, such as p%0.5.
+func (net *Net) arcsString(t int) string {
+	var in, out []string
+	net.forEachArc(t, func(p int, kind arcKind, w int) {
+		name := net.Pl[p]
+		switch kind {
+		case arcIn, arcOut:
+			if w != 1 {
+				name = fmt.Sprintf("%s*%d", name, w)
+			}
+		case arcRead:
+			name = fmt.Sprintf("%s?%d", name, w)
+		case arcInhibit:
+			name = fmt.Sprintf("%s?-%d", name, w)
+		case arcReset:
+			name += "?!"
+		}
+		if kind == arcOut {
+			out = append(out, name)
+		} else {
+			in = append(in, name)
+		}
+	})
+	for _, a := range net.probArcs(t) {
+		name := net.Pl[a.Pl]
+		if a.Mult != 1 {
+			name = fmt.Sprintf("%s*%d", name, a.Mult)
+		}
+		out = append(out, name+"%"+strconv.FormatFloat(a.Prob, 'f', -1, 64))
+	}
+	slices.Sort(in)
+	slices.Sort(out)
+	return strings.TrimSpace(strings.Join(in, " ") + " -> " + strings.Join(out, " "))
+}
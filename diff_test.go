@@ -0,0 +1,73 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	net1, err := ParseString("pl p0 (1)\npl p1\ntr t0 [0,1] p0 -> p1\ntr t1 p1 -> p0\ntr t2 p1?2 p3?-1 -> p0\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	net2, err := ParseString("tr t2 p3?-1 p1?2 -> p0\ntr t1 p1 -> p0*2 p4\ntr t0 [0,2] p0 -> p1\ntr t3 -> p1\npl p0 (2)\npl p3 (1)\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	d := net1.Diff(net2)
+	if !slices.Equal(d.AddedPlaces, []string{"p4"}) || len(d.RemovedPlaces) != 0 {
+		t.Errorf("Diff: wrong places, added %v removed %v", d.AddedPlaces, d.RemovedPlaces)
+	}
+	if !slices.Equal(d.AddedTransitions, []string{"t3"}) || len(d.RemovedTransitions) != 0 {
+		t.Errorf("Diff: wrong transitions, added %v removed %v", d.AddedTransitions, d.RemovedTransitions)
+	}
+	expected := `+ pl p4
++ tr t3
+~ pl p0 (1) => (2)
+~ pl p3 (0) => (1)
+~ tr t0 [0,1] => [0,2]
+~ tr t1 p1 -> p0 => p1 -> p0*2 p4
+`
+	if s := d.String(); s != expected {
+		t.Errorf("Diff: expected\n%s\nactual\n%s", expected, s)
+	}
+	if d := net2.Diff(net1); !slices.Equal(d.RemovedPlaces, []string{"p4"}) || !slices.Equal(d.RemovedTransitions, []string{"t3"}) {
+		t.Errorf("Diff: wrong removed nodes %v %v", d.RemovedPlaces, d.RemovedTransitions)
+	}
+	net3, err := ParseFile("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	var buf bytes.Buffer
+	net3.FprintSorted(&buf)
+	net4, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if d := net3.Diff(net4); !d.Empty() {
+		t.Errorf("Diff: expected no difference, actual\n%s", d.String())
+	}
+	// probabilistic arcs are compared with their probability
+	opts := ParseOptions{Probabilities: true}
+	net5, err := ParseWithOptions(strings.NewReader("tr t p0 -> p1%0.5 p2%0.5\n"), opts)
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	net6, err := ParseWithOptions(strings.NewReader("tr t p0 -> p1%0.2 p2*2%0.8\n"), opts)
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	expected = "~ tr t p0 -> p1%0.5 p2%0.5 => p0 -> p1%0.2 p2*2%0.8\n"
+	if s := net5.Diff(net6).String(); s != expected {
+		t.Errorf("Diff: expected\n%s\nactual\n%s", expected, s)
+	}
+	if d := net5.Diff(net5.Clone()); !d.Empty() {
+		t.Errorf("Diff: expected no difference, actual\n%s", d.String())
+	}
+}
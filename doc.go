@@ -24,7 +24,7 @@ separators.
 
 Labels may be (optionally) assigned to places and transitions, but we do not
 support the use of a "lb" declaration, for labels, that was only kept for
-backward compatibility. We also do not support stopwatches and reset arcs.
+backward compatibility. We also do not support stopwatches.
 
 Grammar
 
@@ -34,17 +34,18 @@ Grammar
     pldesc                  ::= ’pl’ <place> {":" <label>} {(<marking>)} {<pinput> -> <poutput>}
     ntdesc                  ::= ’nt’ <note> (’0’|’1’) <annotation>
     prdesc                  ::= ’pr’ (<transition>)+ ("<"|">") (<transition>)+
-    interval                ::= (’[’|’]’)INT’,’INT(’[’|’]’) | (’[’|’]’)INT’,’w[’
+    interval                ::= (’[’|’]’)<bound>’,’<bound>(’[’|’]’) | (’[’|’]’)<bound>’,’w[’
     tinput                  ::= <place>{<arc>}
     toutput                 ::= <place>{<normal_arc>}
     pinput                  ::= <transition>{<normal_arc>}
     poutput                 ::= <transition>{arc}
     arc                     ::= <normal_arc> | <test_arc> | <inhibitor_arc> |
-                                <stopwatch_arc> | <stopwatch-inhibitor_arc>
+                                <reset_arc> | <stopwatch_arc> | <stopwatch-inhibitor_arc>
     normal_arc              ::= ’*’<weight>
     test_arc                ::= ’?’<weight>
     inhibitor_arc           ::= ’?-’<weight>
-    weight, marking         ::= INT{’K’|’M’}
+    reset_arc               ::= ’?!’
+    weight, marking, bound  ::= INT{’K’|’M’}
     net, place, transition,
     label, note, annotation ::= ANAME | ’{’QNAME’}’
     INT                     ::= unsigned integer
@@ -59,14 +60,22 @@ Two forms are admitted for net, place and transition names:
 
      - ’{’QNAME’}’ : any chain between braces, and in which the three characters "{,}, or \" are escaped with a \
 
-Empty lines and lines beginning with ’#’ are considered comments.
+Empty lines and lines beginning with ’#’ are considered comments. More
+generally, a ’#’ starts a comment that runs until the end of the line, in any
+position except inside a name or label between braces.
 
 In any closed temporal interval [eft,lft], one must have eft <= lft.
 
 Weight is optional for normal arcs, but mandatory for test and inhibitor arcs.
+Reset arcs, such as in "tr t p0?! -> p1", are an extension of the format: they
+empty the place when the transition fires, whatever its marking, and are
+recorded in the Reset field of the net.
+Weights and markings may be written with an explicit plus sign, such as in
+"p*+2" or "(+3)", which is sometimes the case in machine-generated files.
 
 By default: transitions have temporal interval [0,w[; normal arcs have weight 1;
-places have marking 0; and transitions have the empty label "{}"
+places have marking 0; and transitions have the empty label "{}". An explicit
+empty label, such as in "tr t : {}", is the same as no label at all.
 
 When several labels are assigned to some node, only the last assigned is kept.
 
@@ -76,11 +85,37 @@ intersection of all the intervals (the result must not be empty).
 It is also possible to list transitions associated with a place, in a pl
 declaration. Arcs defined in this way are added to the respective transitions.
 
+Notes named color, such as "nt color 0 {p1 red}", are used to associate a
+color to a node (here place p1). These colors are recorded in the NodeColor
+field of the net and used when exporting a net in the DOT format of Graphviz.
+Likewise, notes named server, such as "nt server 0 {t1 infinite}", are used to
+define the server kind of a transition (single, infinite, or a positive
+integer), which is used when firing maximal steps. The transition must be
+declared before the note. All the notes, including
+those that do not follow these conventions, are recorded in the Notes field of
+the net, so that they can be printed back.
+
+As an extension, enabled with option Probabilities of ParseOptions, output
+arcs in a tr declaration can be annotated with a probability, such as in
+"tr t p0 -> p1%0.4 p2*2%0.6". Annotated arcs are alternative branches, from
+which SampleOutcome chooses exactly one when the transition fires; they are
+recorded in the ArcProb field of the net and are not part of Delta. Hence
+Fire only produces the tokens of the output arcs without annotation, while
+analyses such as Reachable, CoverabilityGraph or PInvariants take every
+branch into account. Methods that cannot do so, such as IncidenceMatrix or
+Pnml, return an error.
+
 Simple example of .net file
 
 This is a simple example of .net file. Note that it is possible to have several
 declarations for the same object (place or transition); the end result is the
-fusion of all these declarations.
+fusion of all these declarations. Use ParseStrict (or option Strict of
+ParseOptions) to report an error when two declarations give conflicting
+labels, markings, or time intervals to the same object. Since places and
+transitions are created when they first appear in an arc, we report an error
+when an arc of a tr declaration uses the name of a transition (or when an arc of
+a pl declaration uses the name of a place), unless a node of the expected kind
+with the same name has been declared before.
 
      tr t1 p1 p2*2 -> p3 p4 p5
      tr t2 [0,2] p4 -> p2
@@ -0,0 +1,103 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Dot writes a description of the net in the DOT language of Graphviz on an
+// io.Writer. Places are drawn as circles and transitions as boxes. We use the
+// colors defined in net.NodeColor, if any, to fill the corresponding nodes.
+// Read arcs are drawn with a dot head, inhibitor arcs with an odot head, and
+// reset arcs with a diamond head. Probabilistic arcs are dashed and labeled
+// with their weight, when it is not 1, and their probability, such as 2%0.3.
+//
+// Node identifiers are built from the index of places (pl_0, pl_1, ...) and
+// transitions (tr_0, ...), since a place and a transition may share the same
+// name in a .net file.
+func (net *Net) Dot(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "digraph %s {\n", dotQuote(net.Name))
+	for k, v := range net.Pl {
+		label := v
+		if net.Plabel[k] != "" {
+			label += " : " + net.Plabel[k]
+		}
+		if p := net.Initial.Get(k); p != 0 {
+			label += fmt.Sprintf(" (%d)", p)
+		}
+		fmt.Fprintf(bw, "  pl_%d [shape=circle, label=%s%s];\n", k, dotQuote(label), net.dotColor(v))
+	}
+	for k, v := range net.Tr {
+		label := v
+		if net.Tlabel[k] != "" {
+			label += " : " + net.Tlabel[k]
+		}
+		if !net.Time[k].Trivial() {
+			label += " " + net.Time[k].String()
+		}
+		fmt.Fprintf(bw, "  tr_%d [shape=box, label=%s%s];\n", k, dotQuote(label), net.dotColor(v))
+	}
+	for k := range net.Tr {
+		net.forEachArc(k, func(p int, kind arcKind, w int) {
+			switch kind {
+			case arcIn:
+				fmt.Fprintf(bw, "  pl_%d -> tr_%d%s;\n", p, k, dotWeight(w, ""))
+			case arcOut:
+				fmt.Fprintf(bw, "  tr_%d -> pl_%d%s;\n", k, p, dotWeight(w, ""))
+			case arcRead:
+				fmt.Fprintf(bw, "  pl_%d -> tr_%d%s;\n", p, k, dotWeight(w, "dot"))
+			case arcInhibit:
+				fmt.Fprintf(bw, "  pl_%d -> tr_%d%s;\n", p, k, dotWeight(w, "odot"))
+			case arcReset:
+				fmt.Fprintf(bw, "  pl_%d -> tr_%d%s;\n", p, k, dotWeight(1, "diamond"))
+			}
+		})
+		for _, a := range net.probArcs(k) {
+			label := "%" + strconv.FormatFloat(a.Prob, 'f', -1, 64)
+			if a.Mult != 1 {
+				label = strconv.Itoa(a.Mult) + label
+			}
+			fmt.Fprintf(bw, "  tr_%d -> pl_%d [label=%s, style=dashed];\n", k, a.Pl, dotQuote(label))
+		}
+	}
+	fmt.Fprint(bw, "}\n")
+	return bw.Flush()
+}
+
+// dotColor returns the attributes used to fill a node with the color
+// associated to its name, if any.
+func (net *Net) dotColor(name string) string {
+	if c, ok := net.NodeColor[name]; ok {
+		return fmt.Sprintf(", style=filled, fillcolor=%s", dotQuote(c))
+	}
+	return ""
+}
+
+// dotWeight returns the attributes of an arc with weight w and arrowhead
+// style head (the default style when head is empty).
+func dotWeight(w int, head string) string {
+	var attr []string
+	if w != 1 {
+		attr = append(attr, fmt.Sprintf("label=\"%d\"", w))
+	}
+	if head != "" {
+		attr = append(attr, "arrowhead="+head)
+	}
+	if len(attr) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(attr, ", ") + "]"
+}
+
+// dotQuote returns s as a double-quoted DOT identifier.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
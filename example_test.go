@@ -38,6 +38,7 @@ func Example_basic() {
 	// # 4 places, 7 transitions
 	// #
 	//
+	// net demo
 	// pl p0
 	// pl p1
 	// pl p4 : b
@@ -76,3 +77,60 @@ func Example_pnml() {
 	}
 	_ = net.Pnml(os.Stdout)
 }
+
+// This example shows how to build a net programmatically, instead of parsing a
+// .net file. We add the places and transitions of the net in file
+// testdata/demo.net, in the same order, but without priorities.
+func Example_builder() {
+	net := nets.NewNet("demo")
+	net.AddPlace("p0", "", 0)
+	net.AddPlace("p1", "", 0)
+	net.AddPlace("p4", "b", 0)
+	net.AddPlace("p2", "", 1)
+	t1, _ := nets.NewInterval(0, 1, true, true)
+	t0, _ := nets.NewInterval(2, 3, false, false)
+	t2, _ := nets.NewInterval(0, 0, true, true)
+	net.AddTransition("t1", "", t1)
+	net.AddTransition("t0", "a", t0)
+	net.AddTransition("t3", "", nets.TimeInterval{})
+	net.AddTransition("t5", "{\\{a\\}}", nets.TimeInterval{})
+	net.AddTransition("t4", "", nets.TimeInterval{})
+	net.AddTransition("t6", "", nets.TimeInterval{})
+	net.AddTransition("t2", "{b s}", t2)
+	arcs := []struct {
+		from, to string
+		weight   int
+	}{
+		{"p0", "t1", 1}, {"t1", "p1", 1},
+		{"p0", "t0", 3}, {"t0", "p1", 1}, {"t0", "p4", 1},
+		{"p2", "t3", 1},
+		{"p4", "t5", 1}, {"t5", "p0", 1},
+		{"t4", "p4", 1},
+	}
+	for _, a := range arcs {
+		if err := net.AddArc(a.from, a.to, a.weight); err != nil {
+			log.Fatal(err)
+		}
+	}
+	_ = net.AddReadArc("t6", "p4", 1)
+	_ = net.AddInhibitorArc("t2", "p1", 4000)
+	fmt.Printf("%s", net)
+	// Output:
+	// #
+	// # net demo
+	// # 4 places, 7 transitions
+	// #
+	//
+	// net demo
+	// pl p0
+	// pl p1
+	// pl p4 : b
+	// pl p2 (1)
+	// tr t1 [0,1] p0 -> p1
+	// tr t0 : a ]2,3[ p0*3 -> p1 p4
+	// tr t3  p2 ->
+	// tr t5 : {\{a\}}  p4 -> p0
+	// tr t4  -> p4
+	// tr t6  p4?1 ->
+	// tr t2 : {b s} [0,0] p1?-4000 ->
+}
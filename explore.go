@@ -0,0 +1,150 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// stateGraph is the (untimed) reachability graph of a net. States are
+// identified by their index in slice markings, the initial marking has index
+// 0, and edges[s] lists the transitions enabled at state s together with the
+// index of the resulting state.
+type stateGraph struct {
+	markings []Marking
+	edges    [][]edge
+}
+
+type edge struct {
+	t, dst int
+}
+
+// fire returns the marking obtained by firing transition t at marking m,
+// taking into account its reset arcs. We do not check that t is enabled and we
+// never modify m. Probabilistic arcs are ignored, meaning that we only add
+// Delta[t]; use successors to take every branch of t into account.
+func (net *Net) fire(m Marking, t int) Marking {
+	return net.applyResets(m.Add(net.Delta[t]), t, net.Delta[t])
+}
+
+// successors returns the markings obtained by firing transition t at marking
+// m, one for each possible outcome of t (see outcomes). The result is the
+// singleton fire(m, t) when t has no probabilistic arcs.
+func (net *Net) successors(m Marking, t int) []Marking {
+	res := []Marking{}
+	for _, d := range net.outcomes(t) {
+		res = append(res, net.applyResets(m.Add(d), t, d))
+	}
+	return res
+}
+
+// explore computes the reachability graph of the net, ignoring timing
+// constraints and priorities, using a breadth-first search from the initial
+// marking. We use marking handles (see Unique) to store the set of visited
+// markings. We return an error if we find more than bound markings or if a
+// marking cannot be interned, for instance because it has a negative
+// multiplicity. A transition with probabilistic arcs has one edge for each of
+// its outcomes, so a state may have several edges with the same transition.
+func (net *Net) explore(bound int) (*stateGraph, error) {
+	g := &stateGraph{}
+	visited := make(map[Handle]int)
+	add := func(m Marking) (int, error) {
+		h, err := m.Unique()
+		if err != nil {
+			return 0, fmt.Errorf("cannot store marking %s; %s", net.Mtoa(m), err)
+		}
+		if s, ok := visited[h]; ok {
+			return s, nil
+		}
+		if len(g.markings) >= bound {
+			return 0, fmt.Errorf("more than %d reachable markings", bound)
+		}
+		s := len(g.markings)
+		visited[h] = s
+		g.markings = append(g.markings, m)
+		g.edges = append(g.edges, nil)
+		return s, nil
+	}
+	if _, err := add(net.Initial); err != nil {
+		return g, err
+	}
+	for s := 0; s < len(g.markings); s++ {
+		m := g.markings[s]
+		for _, t := range net.AllEnabled(m) {
+			for _, m2 := range net.successors(m, t) {
+				dst, err := add(m2)
+				if err != nil {
+					return g, err
+				}
+				g.edges[s] = append(g.edges[s], edge{t, dst})
+			}
+		}
+	}
+	return g, nil
+}
+
+// Reachable returns the markings reachable from the initial marking of the
+// net, ignoring timing constraints and priorities, in the order they are
+// discovered using a breadth-first search. The first marking is always
+// net.Initial. We return an error if we find more than bound markings, or if
+// some marking has a negative multiplicity, since it cannot be interned (see
+// Unique).
+func (net *Net) Reachable(bound int) ([]Marking, error) {
+	g, err := net.explore(bound)
+	if err != nil {
+		return nil, err
+	}
+	return g.markings, nil
+}
+
+// Deadlocks returns the reachable markings of the net, computed like with
+// Reachable, where no transition is enabled. We return an empty slice when the
+// net is deadlock-free, and an error if we find more than bound markings.
+func (net *Net) Deadlocks(bound int) ([]Marking, error) {
+	g, err := net.explore(bound)
+	if err != nil {
+		return nil, err
+	}
+	res := []Marking{}
+	for s, m := range g.markings {
+		if len(g.edges[s]) == 0 {
+			res = append(res, m)
+		}
+	}
+	return res, nil
+}
+
+// WriteReachableCSV explores the (untimed) reachability graph of the net and
+// writes the reachable markings in CSV format, with one column for each place
+// (using the place names as header) and one row for each marking, in the order
+// they are discovered. We return an error, and write nothing, if the net has
+// more than maxStates reachable markings.
+func (net *Net) WriteReachableCSV(w io.Writer, maxStates int) error {
+	g, err := net.explore(maxStates)
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(net.Pl); err != nil {
+		return err
+	}
+	row := make([]string, len(net.Pl))
+	for _, m := range g.markings {
+		for p := range row {
+			row[p] = "0"
+		}
+		for _, a := range m {
+			row[a.Pl] = strconv.Itoa(a.Mult)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
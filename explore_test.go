@@ -0,0 +1,82 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteReachableCSV(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p0 p2\ntr t2 p2 ->\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if err := net.WriteReachableCSV(&strings.Builder{}, 10); err == nil {
+		t.Errorf("WriteReachableCSV should fail on an unbounded net")
+	}
+	net.Disable(1)
+	var buf strings.Builder
+	if err := net.WriteReachableCSV(&buf, 10); err != nil {
+		t.Fatalf("Error in WriteReachableCSV; %s", err)
+	}
+	expected := "p0,p1,p2\n1,0,0\n0,1,0\n"
+	if buf.String() != expected {
+		t.Errorf("WriteReachableCSV: expected %q, actual %q", expected, buf.String())
+	}
+}
+
+func TestReachable(t *testing.T) {
+	// a 1-safe net with two concurrent cycles synchronized on t2
+	net, err := Parse(strings.NewReader("pl p0 (1)\npl p2 (1)\ntr t0 p0 -> p1\ntr t1 p2 -> p3\ntr t2 p1 p3 -> p0 p2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	reach, err := net.Reachable(10)
+	if err != nil {
+		t.Fatalf("Error in Reachable; %s", err)
+	}
+	expected := []string{"p0 p2", "p2 p1", "p0 p3", "p1 p3"}
+	if len(reach) != len(expected) {
+		t.Fatalf("Reachable: expected %d markings, actual %d", len(expected), len(reach))
+	}
+	for k, m := range reach {
+		if net.Mtoa(m) != expected[k] {
+			t.Errorf("Reachable: expected marking %q at position %d, actual %q", expected[k], k, net.Mtoa(m))
+		}
+	}
+	if _, err := net.Reachable(3); err == nil {
+		t.Errorf("Reachable should fail when the bound is exceeded")
+	}
+	net.Initial = Marking{Atom{0, -1}}
+	if _, err := net.Reachable(10); err == nil {
+		t.Errorf("Reachable should fail on markings with negative multiplicities")
+	}
+}
+
+func TestDeadlocks(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p0 -> p2\ntr t2 p1 -> p0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	dead, err := net.Deadlocks(10)
+	if err != nil {
+		t.Fatalf("Error in Deadlocks; %s", err)
+	}
+	if len(dead) != 1 || net.Mtoa(dead[0]) != "p2" {
+		t.Errorf("Deadlocks: expected [p2], actual %v", dead)
+	}
+	net.Disable(1)
+	dead, err = net.Deadlocks(10)
+	if err != nil {
+		t.Fatalf("Error in Deadlocks; %s", err)
+	}
+	if dead == nil || len(dead) != 0 {
+		t.Errorf("Deadlocks: expected an empty slice, actual %v", dead)
+	}
+	if _, err := net.Deadlocks(1); err == nil {
+		t.Errorf("Deadlocks should fail when the bound is exceeded")
+	}
+}
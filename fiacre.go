@@ -0,0 +1,153 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteFiacre writes a translation of the net in the Fiacre language on an
+// io.Writer. The result can be checked with the Fiacre compiler, frac, which is
+// part of the Tina toolbox.
+//
+// We generate one process for each transition and a component, called Main,
+// that declares one shared variable, of type nat, for every place and runs all
+// the processes in parallel. A process has a single state and a single loop
+// that is guarded by the enabling condition of the transition (Cond and Inhib),
+// that waits for the time interval of the transition, and then updates the
+// marking according to Delta. Since the clock of a Fiacre transition is reset
+// when it becomes disabled, or when it fires, this matches the semantics of
+// Time Petri nets. Places and transitions are renamed p_0, p_1, ... and t_0,
+// t_1, ... since their names may not be valid Fiacre identifiers; the original
+// names are given in comments.
+//
+// This is a best-effort translation of the P/T and timing part of the net.
+// Disabled transitions are dropped and we ignore transition weights and server
+// kinds. We return an error if the net has priorities, which cannot be
+// expressed between processes, or probabilistic arcs, or if it has no
+// (enabled) transitions, since a component must have at least one process.
+func (net *Net) WriteFiacre(w io.Writer) error {
+	for k, v := range net.Prio {
+		if len(v) != 0 {
+			return fmt.Errorf("cannot translate net with priorities into Fiacre; see transition %s", net.Tr[k])
+		}
+	}
+	for k, v := range net.ArcProb {
+		if len(v) != 0 {
+			return fmt.Errorf("cannot translate net with probabilistic arcs into Fiacre; see transition %s", net.Tr[k])
+		}
+	}
+	bw := bufio.NewWriter(w)
+	if net.Name != "" {
+		fmt.Fprintf(bw, "(* net %s *)\n\n", fiacreComment(net.Name))
+	}
+	var inst []string
+	for t := range net.Tr {
+		if net.IsDisabled(t) {
+			continue
+		}
+		// we only pass the places used by t to the process
+		used := NewBitset(len(net.Pl))
+		for _, m := range []Marking{net.Cond[t], net.Inhib[t], net.Delta[t]} {
+			for _, a := range m {
+				used.Set(a.Pl)
+			}
+		}
+		for _, p := range net.resets(t) {
+			used.Set(p)
+		}
+		var params, args, guard, update []string
+		used.ForEach(func(p int) {
+			params = append(params, fmt.Sprintf("&p_%d : nat", p))
+			args = append(args, fmt.Sprintf("&p_%d", p))
+		})
+		for _, a := range net.Cond[t] {
+			guard = append(guard, fmt.Sprintf("p_%d >= %d", a.Pl, a.Mult))
+		}
+		for _, a := range net.Inhib[t] {
+			guard = append(guard, fmt.Sprintf("p_%d < %d", a.Pl, a.Mult))
+		}
+		for _, a := range net.Delta[t] {
+			if setMember(net.resets(t), a.Pl) >= 0 {
+				continue
+			}
+			if a.Mult > 0 {
+				update = append(update, fmt.Sprintf("p_%d := p_%d + %d", a.Pl, a.Pl, a.Mult))
+			} else {
+				update = append(update, fmt.Sprintf("p_%d := p_%d - %d", a.Pl, a.Pl, -a.Mult))
+			}
+		}
+		for _, p := range net.resets(t) {
+			update = append(update, fmt.Sprintf("p_%d := %d", p, net.Delta[t].Get(p)-net.Pre[t].Get(p)))
+		}
+		fmt.Fprintf(bw, "(* transition %s *)\n", fiacreComment(net.Tr[t]))
+		fmt.Fprintf(bw, "process t_%d", t)
+		if len(params) != 0 {
+			fmt.Fprintf(bw, " (%s)", strings.Join(params, ", "))
+		}
+		fmt.Fprint(bw, " is\n  states s0\n  from s0\n    ")
+		if len(guard) != 0 {
+			fmt.Fprintf(bw, "on (%s);\n    ", strings.Join(guard, " and "))
+		}
+		fmt.Fprintf(bw, "wait %s;\n    ", fiacreInterval(net.Time[t]))
+		for _, u := range update {
+			fmt.Fprintf(bw, "%s;\n    ", u)
+		}
+		fmt.Fprint(bw, "to s0\n\n")
+		inst = append(inst, fmt.Sprintf("t_%d [] (%s)", t, strings.Join(args, ", ")))
+	}
+	if len(inst) == 0 {
+		return fmt.Errorf("cannot translate net without transitions into Fiacre")
+	}
+	fmt.Fprint(bw, "component Main is\n")
+	if len(net.Pl) != 0 {
+		decl := make([]string, len(net.Pl))
+		for p, v := range net.Pl {
+			decl[p] = fmt.Sprintf("p_%d : nat := %d (* %s *)", p, net.Initial.Get(p), fiacreComment(v))
+		}
+		fmt.Fprintf(bw, "  var %s\n", strings.Join(decl, ",\n      "))
+	}
+	if len(inst) == 1 {
+		fmt.Fprintf(bw, "  %s\n", inst[0])
+	} else {
+		fmt.Fprintf(bw, "  par\n    %s\n  end\n", strings.Join(inst, "\n  || "))
+	}
+	fmt.Fprint(bw, "\nMain\n")
+	return bw.Flush()
+}
+
+// fiacreInterval returns the Fiacre syntax for time interval i, such as [0,2]
+// or ]1,...[ for an unbounded interval.
+func fiacreInterval(i TimeInterval) string {
+	if i.Left.Bkind == BINFTY {
+		// interval was never set
+		return "[0,...["
+	}
+	var b strings.Builder
+	if i.Left.Bkind == BOPEN {
+		b.WriteString("]")
+	} else {
+		b.WriteString("[")
+	}
+	fmt.Fprintf(&b, "%d,", i.Left.Value)
+	switch i.Right.Bkind {
+	case BINFTY:
+		b.WriteString("...[")
+	case BOPEN:
+		fmt.Fprintf(&b, "%d[", i.Right.Value)
+	default:
+		fmt.Fprintf(&b, "%d]", i.Right.Value)
+	}
+	return b.String()
+}
+
+// fiacreComment returns s in a form that can be safely included in a Fiacre
+// comment.
+func fiacreComment(s string) string {
+	return strings.NewReplacer("*)", "* )", "(*", "( *").Replace(s)
+}
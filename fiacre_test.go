@@ -0,0 +1,71 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestWriteFiacre(t *testing.T) {
+	net, err := Parse(strings.NewReader("net demo\npl p0 (1)\ntr t0 [1,3] p0 -> p1\ntr t1 ]2,w[ p1 p2?-1 -> p0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	var b strings.Builder
+	if err := net.WriteFiacre(&b); err != nil {
+		t.Fatalf("WriteFiacre: %s", err)
+	}
+	for _, v := range []string{
+		"process t_0 (&p_0 : nat, &p_1 : nat) is",
+		"on (p_0 >= 1);\n    wait [1,3];\n    p_0 := p_0 - 1;\n    p_1 := p_1 + 1;\n    to s0",
+		"on (p_1 >= 1 and p_2 < 1);\n    wait ]2,...[;",
+		"var p_0 : nat := 1 (* p0 *),\n      p_1 : nat := 0 (* p1 *)",
+		"par\n    t_0 [] (&p_0, &p_1)\n  || t_1 [] (&p_0, &p_1, &p_2)\n  end",
+		"\nMain\n",
+	} {
+		if !strings.Contains(b.String(), v) {
+			t.Errorf("WriteFiacre: missing %q in\n%s", v, b.String())
+		}
+	}
+	// place p2 is only reset by t, so it must still be passed to the process
+	net, err = Parse(strings.NewReader("pl p0 (1)\npl p1\npl p2\ntr t p0 p2?! -> p1\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	b.Reset()
+	if err := net.WriteFiacre(&b); err != nil {
+		t.Fatalf("WriteFiacre: %s", err)
+	}
+	for _, v := range []string{
+		"process t_0 (&p_0 : nat, &p_1 : nat, &p_2 : nat) is",
+		"p_2 := 0;",
+		"t_0 [] (&p_0, &p_1, &p_2)",
+	} {
+		if !strings.Contains(b.String(), v) {
+			t.Errorf("WriteFiacre: missing %q in\n%s", v, b.String())
+		}
+	}
+	net, err = ParseWithOptions(strings.NewReader("pl p0 (1)\ntr t p0 -> p1%0.5 p2%0.5\n"), ParseOptions{Probabilities: true})
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if err := net.WriteFiacre(&b); err == nil {
+		t.Errorf("WriteFiacre: expected error on net with probabilistic arcs")
+	}
+	file, err := os.Open("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error opening file demo.net; %s", err)
+	}
+	defer file.Close()
+	net, err = Parse(file)
+	if err != nil {
+		t.Fatalf("Error parsing file demo.net; %s", err)
+	}
+	if err := net.WriteFiacre(&b); err == nil {
+		t.Errorf("WriteFiacre: expected error on net with priorities")
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// graphmlKeys are the attribute declarations used in GraphML output. Tools
+// such as yEd or Gephi require that every data element refers to a declared
+// key.
+const graphmlKeys = `  <key id="type" for="node" attr.name="type" attr.type="string"/>
+  <key id="name" for="node" attr.name="name" attr.type="string"/>
+  <key id="label" for="node" attr.name="label" attr.type="string"/>
+  <key id="marking" for="node" attr.name="marking" attr.type="int">
+    <default>0</default>
+  </key>
+  <key id="interval" for="node" attr.name="interval" attr.type="string"/>
+  <key id="kind" for="edge" attr.name="kind" attr.type="string"/>
+  <key id="weight" for="edge" attr.name="weight" attr.type="int">
+    <default>1</default>
+  </key>
+  <key id="probability" for="edge" attr.name="probability" attr.type="double"/>
+`
+
+// WriteGraphML writes the bipartite graph of the net in GraphML format on an
+// io.Writer. Each node has a type attribute (place or transition), a name and
+// a label. Places also have an initial marking and transitions a time
+// interval. Edges have a kind (normal, read, inhibitor, reset or
+// probabilistic) and a weight. Probabilistic arcs also have a probability.
+// Like with Dot, node identifiers are built from the index of places (pl_0,
+// ...) and transitions (tr_0, ...).
+func (net *Net) WriteGraphML(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(xml.Header)
+	bw.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	bw.WriteString(graphmlKeys)
+	fmt.Fprintf(bw, "  <graph id=%s edgedefault=\"directed\">\n", xmlQuote(net.Name))
+	for k, v := range net.Pl {
+		fmt.Fprintf(bw, "    <node id=\"pl_%d\">\n", k)
+		graphmlData(bw, "type", "place")
+		graphmlData(bw, "name", v)
+		if net.Plabel[k] != "" {
+			graphmlData(bw, "label", net.Plabel[k])
+		}
+		if m := net.Initial.Get(k); m != 0 {
+			graphmlData(bw, "marking", fmt.Sprint(m))
+		}
+		bw.WriteString("    </node>\n")
+	}
+	for k, v := range net.Tr {
+		fmt.Fprintf(bw, "    <node id=\"tr_%d\">\n", k)
+		graphmlData(bw, "type", "transition")
+		graphmlData(bw, "name", v)
+		if net.Tlabel[k] != "" {
+			graphmlData(bw, "label", net.Tlabel[k])
+		}
+		graphmlData(bw, "interval", net.Time[k].String())
+		bw.WriteString("    </node>\n")
+	}
+	for k := range net.Tr {
+		net.forEachArc(k, func(p int, kind arcKind, weight int) {
+			if kind == arcOut {
+				fmt.Fprintf(bw, "    <edge source=\"tr_%d\" target=\"pl_%d\">\n", k, p)
+			} else {
+				fmt.Fprintf(bw, "    <edge source=\"pl_%d\" target=\"tr_%d\">\n", p, k)
+			}
+			graphmlData(bw, "kind", kind.String())
+			if weight != 1 && kind != arcReset {
+				graphmlData(bw, "weight", fmt.Sprint(weight))
+			}
+			bw.WriteString("    </edge>\n")
+		})
+		for _, a := range net.probArcs(k) {
+			fmt.Fprintf(bw, "    <edge source=\"tr_%d\" target=\"pl_%d\">\n", k, a.Pl)
+			graphmlData(bw, "kind", "probabilistic")
+			if a.Mult != 1 {
+				graphmlData(bw, "weight", fmt.Sprint(a.Mult))
+			}
+			graphmlData(bw, "probability", strconv.FormatFloat(a.Prob, 'f', -1, 64))
+			bw.WriteString("    </edge>\n")
+		}
+	}
+	bw.WriteString("  </graph>\n</graphml>\n")
+	return bw.Flush()
+}
+
+// graphmlData writes a data element, for attribute key, with the given value.
+func graphmlData(w io.Writer, key, value string) {
+	fmt.Fprintf(w, "      <data key=\"%s\">%s</data>\n", key, xmlEscape(value))
+}
+
+// xmlEscape returns s with the special XML characters escaped.
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
+
+// xmlQuote returns s as a double-quoted XML attribute value.
+func xmlQuote(s string) string {
+	return `"` + xmlEscape(s) + `"`
+}
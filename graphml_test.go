@@ -0,0 +1,96 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestWriteGraphML(t *testing.T) {
+	net, err := Parse(strings.NewReader("net {a<b}\npl p0 : lbl (2)\ntr t0 [1,3] p0*2 p1?3 p2?-1 -> p1\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	var b strings.Builder
+	if err := net.WriteGraphML(&b); err != nil {
+		t.Fatalf("WriteGraphML: %s", err)
+	}
+	// we check that the output is valid XML and read back the graph
+	type data struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+	var doc struct {
+		Keys []struct {
+			ID string `xml:"id,attr"`
+		} `xml:"key"`
+		Graph struct {
+			ID    string `xml:"id,attr"`
+			Nodes []struct {
+				ID   string `xml:"id,attr"`
+				Data []data `xml:"data"`
+			} `xml:"node"`
+			Edges []struct {
+				Source string `xml:"source,attr"`
+				Target string `xml:"target,attr"`
+				Data   []data `xml:"data"`
+			} `xml:"edge"`
+		} `xml:"graph"`
+	}
+	if err := xml.Unmarshal([]byte(b.String()), &doc); err != nil {
+		t.Fatalf("WriteGraphML: invalid XML; %s\n%s", err, b.String())
+	}
+	keys := []string{}
+	for _, k := range doc.Keys {
+		keys = append(keys, k.ID)
+	}
+	if doc.Graph.ID != "{a<b}" {
+		t.Errorf("WriteGraphML: wrong graph id %q", doc.Graph.ID)
+	}
+	nodes := []string{}
+	for _, n := range doc.Graph.Nodes {
+		s := n.ID
+		for _, d := range n.Data {
+			s += " " + d.Key + "=" + d.Value
+			if !slices.Contains(keys, d.Key) {
+				t.Errorf("WriteGraphML: undeclared key %s", d.Key)
+			}
+		}
+		nodes = append(nodes, s)
+	}
+	expected := []string{
+		"pl_0 type=place name=p0 label=lbl marking=2",
+		"pl_1 type=place name=p1",
+		"pl_2 type=place name=p2",
+		"tr_0 type=transition name=t0 interval=[1,3]",
+	}
+	if !slices.Equal(nodes, expected) {
+		t.Errorf("WriteGraphML: expected nodes %q, actual %q", expected, nodes)
+	}
+	edges := []string{}
+	for _, e := range doc.Graph.Edges {
+		s := fmt.Sprintf("%s->%s", e.Source, e.Target)
+		for _, d := range e.Data {
+			s += " " + d.Key + "=" + d.Value
+			if !slices.Contains(keys, d.Key) {
+				t.Errorf("WriteGraphML: undeclared key %s", d.Key)
+			}
+		}
+		edges = append(edges, s)
+	}
+	expected = []string{
+		"pl_0->tr_0 kind=normal weight=2",
+		"tr_0->pl_1 kind=normal",
+		"pl_1->tr_0 kind=read weight=3",
+		"pl_2->tr_0 kind=inhibitor",
+	}
+	if !slices.Equal(edges, expected) {
+		t.Errorf("WriteGraphML: expected edges %q, actual %q", expected, edges)
+	}
+}
@@ -13,6 +13,18 @@ import (
 const (
 	// DOCTYPE for the generated PNML file
 	DOCTYPE = `<?xml version="1.0" encoding="UTF-8"?>` + "\n"
+	// InhibitorArc is the value of the type attribute for inhibitor arcs, as
+	// used in the extension of P/T nets supported by tools such as LoLA or
+	// TAPAAL.
+	InhibitorArc = "inhibitor"
+	// ReadArc is the value of the type attribute for read arcs (also called
+	// test arcs), meaning arcs that test the marking of a place without
+	// consuming tokens. We also accept the value "read" when reading a file.
+	ReadArc = "test"
+	// TinaTool and TinaVersion are the attributes of the toolspecific elements
+	// used to store the time interval of transitions.
+	TinaTool    = "tina"
+	TinaVersion = "3"
 )
 
 // PT is the type of PNML for a P/T net without graphical information
@@ -45,11 +57,36 @@ type Place struct {
 }
 
 // Trans is the type used to marshal transitions. We keep a pointer to the net
-// so that we can find references to the arcs. We do not support inhibitor arcs.
+// so that we can find references to the arcs. Inhibitor arcs and read arcs
+// are marshalled as arcs with attribute type="inhibitor" and type="test".
 type Trans struct {
 	Name    string
 	Label   string
 	In, Out []Arc
+	Inhib   []Arc
+	Read    []Arc
+	Time    *Interval
+}
+
+// Interval is the type used to marshal the time interval of a transition,
+// inside a toolspecific element of the transition. Attributes left and right
+// give the kind of the bounds, either "closed" or "open", and eft and lft are
+// the earliest and latest firing times. Attributes lft and right are omitted
+// when the interval has no right bound. For instance ]1,w[ is marshalled as
+// <interval left="open" eft="1"/>.
+type Interval struct {
+	Left  string `xml:"left,attr"`
+	EFT   int    `xml:"eft,attr"`
+	LFT   string `xml:"lft,attr,omitempty"`
+	Right string `xml:"right,attr,omitempty"`
+}
+
+// toolTime is the toolspecific element used to marshal time intervals.
+type toolTime struct {
+	XMLName  xml.Name `xml:"toolspecific"`
+	Tool     string   `xml:"tool,attr"`
+	Version  string   `xml:"version,attr"`
+	Interval Interval `xml:"interval"`
 }
 
 // Arc is a pair of a place and a multiplicity. This is used to build arcs in
@@ -94,19 +131,30 @@ func (v Trans) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 
 	}
 	e.EncodeToken(xml.EndElement{Name: xml.Name{Local: "name"}})
+	if v.Time != nil {
+		e.Encode(toolTime{Tool: TinaTool, Version: TinaVersion, Interval: *v.Time})
+	}
 	e.EncodeToken(xml.EndElement{Name: start.Name})
 
 	for _, c := range v.In {
-		encodeArc(e, fmt.Sprintf("p2t-%s-%s", c.Place.Name, v.Name), "pl_"+c.Place.Name, "tr_"+v.Name, c.Mult)
+		encodeArc(e, fmt.Sprintf("p2t-%s-%s", c.Place.Name, v.Name), "pl_"+c.Place.Name, "tr_"+v.Name, "", c.Mult)
 	}
 	for _, c := range v.Out {
-		encodeArc(e, fmt.Sprintf("t2p-%s-%s", v.Name, c.Place.Name), "tr_"+v.Name, "pl_"+c.Place.Name, c.Mult)
+		encodeArc(e, fmt.Sprintf("t2p-%s-%s", v.Name, c.Place.Name), "tr_"+v.Name, "pl_"+c.Place.Name, "", c.Mult)
+	}
+	for _, c := range v.Inhib {
+		encodeArc(e, fmt.Sprintf("inh-%s-%s", c.Place.Name, v.Name), "pl_"+c.Place.Name, "tr_"+v.Name, InhibitorArc, c.Mult)
+	}
+	for _, c := range v.Read {
+		encodeArc(e, fmt.Sprintf("rd-%s-%s", c.Place.Name, v.Name), "pl_"+c.Place.Name, "tr_"+v.Name, ReadArc, c.Mult)
 	}
 
 	return nil
 }
 
-func encodeArc(e *xml.Encoder, id, src, tgt string, weight int) {
+// encodeArc encodes an arc with the given id, source, target and weight. We
+// add a type attribute when kind is not empty.
+func encodeArc(e *xml.Encoder, id, src, tgt, kind string, weight int) {
 	arc := xml.StartElement{
 		Name: xml.Name{Local: "arc"},
 		Attr: []xml.Attr{
@@ -115,6 +163,9 @@ func encodeArc(e *xml.Encoder, id, src, tgt string, weight int) {
 			{Name: xml.Name{Local: "target"}, Value: tgt},
 		},
 	}
+	if kind != "" {
+		arc.Attr = append(arc.Attr, xml.Attr{Name: xml.Name{Local: "type"}, Value: kind})
+	}
 	e.EncodeToken(arc)
 	if weight != 1 {
 		e.EncodeToken(xml.StartElement{Name: xml.Name{Local: "inscription"}})
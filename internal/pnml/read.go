@@ -0,0 +1,89 @@
+// Copyright (c) 2021 Silvano DAL ZILIO
+//
+// GNU Affero GPL v3
+
+package pnml
+
+import (
+	"encoding/xml"
+	"io"
+)
+
+// Document is the type used to unmarshal a PNML file. We only keep the
+// information needed to build a P/T net and ignore graphical information.
+type Document struct {
+	XMLName xml.Name  `xml:"pnml"`
+	NETS    []NetDecl `xml:"net"`
+}
+
+// NetDecl is the type used to unmarshal a PNML net.
+type NetDecl struct {
+	Thetype string     `xml:"type,attr"`
+	ID      string     `xml:"id,attr"`
+	NAME    string     `xml:"name>text"`
+	PAGES   []PageDecl `xml:"page"`
+	TOOLS   []ToolDecl `xml:"toolspecific"`
+}
+
+// PageDecl is the type used to unmarshal a page. We keep track of nested pages
+// and reference nodes, used in hierarchical nets, so that we can report them.
+type PageDecl struct {
+	ID       string      `xml:"id,attr"`
+	PAGES    []PageDecl  `xml:"page"`
+	PLACES   []PlaceDecl `xml:"place"`
+	TRANS    []TransDecl `xml:"transition"`
+	ARCS     []ArcDecl   `xml:"arc"`
+	REFPLACE []NodeDecl  `xml:"referencePlace"`
+	REFTRANS []NodeDecl  `xml:"referenceTransition"`
+	TOOLS    []ToolDecl  `xml:"toolspecific"`
+}
+
+// NodeDecl is the type used to unmarshal a reference place or transition.
+type NodeDecl struct {
+	ID string `xml:"id,attr"`
+}
+
+// PlaceDecl is the type used to unmarshal places. The initial marking is kept
+// as a string, which is empty if there is no initialMarking element.
+type PlaceDecl struct {
+	ID    string     `xml:"id,attr"`
+	NAME  string     `xml:"name>text"`
+	INIT  string     `xml:"initialMarking>text"`
+	TOOLS []ToolDecl `xml:"toolspecific"`
+}
+
+// TransDecl is the type used to unmarshal transitions.
+type TransDecl struct {
+	ID    string     `xml:"id,attr"`
+	NAME  string     `xml:"name>text"`
+	TOOLS []ToolDecl `xml:"toolspecific"`
+}
+
+// ArcDecl is the type used to unmarshal arcs. The inscription is kept as a
+// string, which is empty if there is no inscription element (meaning a weight
+// of 1). We also keep the type of the arc, used in some extensions of PNML.
+type ArcDecl struct {
+	ID     string `xml:"id,attr"`
+	SOURCE string `xml:"source,attr"`
+	TARGET string `xml:"target,attr"`
+	TYPE   string `xml:"type,attr"`
+	WEIGHT string `xml:"inscription>text"`
+}
+
+// ToolDecl is the type used to unmarshal toolspecific elements. Field Interval
+// is set when the element contains the time interval of a transition, like in
+// the files generated with option Time of PnmlWithOptions.
+type ToolDecl struct {
+	Tool     string    `xml:"tool,attr"`
+	Version  string    `xml:"version,attr"`
+	Interval *Interval `xml:"interval"`
+}
+
+// Read unmarshals a PNML file from an io.Reader.
+func Read(r io.Reader) (*Document, error) {
+	doc := &Document{}
+	if err := xml.NewDecoder(r).Decode(doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
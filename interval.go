@@ -72,6 +72,38 @@ type TimeInterval struct {
 	Left, Right Bound
 }
 
+// NewInterval returns the time interval with left bound lo and right bound hi,
+// where each bound is closed or open depending on loClosed and hiClosed. For
+// instance NewInterval(1, 3, false, true) is ]1,3]. We return an error if lo is
+// negative or if the interval is empty (see IsEmpty).
+func NewInterval(lo, hi int, loClosed, hiClosed bool) (TimeInterval, error) {
+	i := TimeInterval{Left: Bound{BOPEN, lo}, Right: Bound{BOPEN, hi}}
+	if loClosed {
+		i.Left.Bkind = BCLOSE
+	}
+	if hiClosed {
+		i.Right.Bkind = BCLOSE
+	}
+	if lo < 0 {
+		return i, fmt.Errorf("negative bound in time interval %s", i.String())
+	}
+	if i.IsEmpty() {
+		return i, fmt.Errorf("empty time interval %s", i.String())
+	}
+	return i, nil
+}
+
+// NewUnboundedInterval returns the time interval with left bound lo, closed or
+// open depending on loClosed, and no right bound. For instance
+// NewUnboundedInterval(2, true) is [2,w[. Value lo should not be negative.
+func NewUnboundedInterval(lo int, loClosed bool) TimeInterval {
+	i := TimeInterval{Left: Bound{BOPEN, lo}, Right: Bound{BINFTY, 0}}
+	if loClosed {
+		i.Left.Bkind = BCLOSE
+	}
+	return i
+}
+
 func (i *TimeInterval) String() string {
 	if i.Left.Bkind == BINFTY {
 		// it means interval was never set
@@ -202,6 +234,68 @@ func (i *TimeInterval) Trivial() bool {
 	return true
 }
 
+// IsUrgent is true if the time interval i is of the form [0,0], meaning that a
+// transition with this interval must fire as soon as it is enabled, before
+// time can elapse.
+func (i *TimeInterval) IsUrgent() bool {
+	return i.Left.Bkind != BINFTY && i.Right.Bkind == BCLOSE && i.Right.Value == 0
+}
+
+// Contains is true if value v is in the time interval i. An un-initialized
+// interval is treated as [0,w[.
+func (i *TimeInterval) Contains(v int) bool {
+	switch i.Left.Bkind {
+	case BINFTY:
+		return v >= 0
+	case BCLOSE:
+		if v < i.Left.Value {
+			return false
+		}
+	default:
+		if v <= i.Left.Value {
+			return false
+		}
+	}
+	switch i.Right.Bkind {
+	case BINFTY:
+		return true
+	case BCLOSE:
+		return v <= i.Right.Value
+	default:
+		return v < i.Right.Value
+	}
+}
+
+// Add returns the sum of intervals i and j, meaning the interval of values
+// a + b with a in i and b in j. Bounds are added using BAdd, so that the
+// result is right-unbounded when i or j is, and a bound of the result is open
+// when one of the corresponding bounds is open. For instance [1,2] + ]0,3[ is
+// ]1,5[. An un-initialized interval is treated as [0,w[.
+func (i TimeInterval) Add(j TimeInterval) TimeInterval {
+	if i.Left.Bkind == BINFTY {
+		i = TimeInterval{Left: Bound{BCLOSE, 0}, Right: Bound{BINFTY, 0}}
+	}
+	if j.Left.Bkind == BINFTY {
+		j = TimeInterval{Left: Bound{BCLOSE, 0}, Right: Bound{BINFTY, 0}}
+	}
+	return TimeInterval{Left: BAdd(i.Left, j.Left), Right: BAdd(i.Right, j.Right)}
+}
+
+// Shift returns the interval obtained by adding d to both bounds of i. An
+// infinite right bound stays infinite and an un-initialized interval is
+// treated as [0,w[.
+func (i *TimeInterval) Shift(d int) TimeInterval {
+	if i.Left.Bkind == BINFTY {
+		return TimeInterval{Left: Bound{BCLOSE, d}, Right: Bound{BINFTY, 0}}
+	}
+	res := *i
+	res.Left.Value += d
+	if res.Right.Bkind != BINFTY {
+		res.Right.Value += d
+	}
+	return res
+}
+
 // intersectWith sets interval i to the intersection of i and j. We return an
 // error if the intersection is empty.
 func (i *TimeInterval) intersectWith(j TimeInterval) error {
@@ -223,25 +317,50 @@ func (i *TimeInterval) intersectWith(j TimeInterval) error {
 			i.Left.Value = j.Left.Value
 		}
 	}
-	if j.Right.Bkind == BINFTY {
+	switch {
+	case j.Right.Bkind == BINFTY:
 		// we do not need to update the right part
-		return nil
-	}
-	if i.Right.Bkind == BINFTY {
+	case i.Right.Bkind == BINFTY:
 		i.Right.Bkind = j.Right.Bkind
 		i.Right.Value = j.Right.Value
-		return nil
-	}
-	// when both intervals are right-bounded we take the min of their right parts
-	if j.Right.Value <= i.Right.Value {
+	case j.Right.Value <= i.Right.Value:
+		// when both intervals are right-bounded we take the min of their right parts
 		if j.Right.Value < i.Right.Value || (j.Right.Value == i.Right.Value && j.Right.Bkind == BOPEN) {
 			i.Right.Bkind = j.Right.Bkind
 			i.Right.Value = j.Right.Value
 		}
 	}
 	// we need to test if the result is empty
-	if i.Right.Value < i.Left.Value || (i.Right.Value == i.Left.Value && (i.Left.Bkind == BOPEN || i.Right.Bkind == BOPEN)) {
+	if i.IsEmpty() {
 		return fmt.Errorf("empty time interval when computing intersection")
 	}
 	return nil
 }
+
+// IntersectWith sets interval i to the intersection of i and j. We return an
+// error if j is not initialized or if the intersection is empty, in which case
+// i is left in an inconsistent state.
+func (i *TimeInterval) IntersectWith(j TimeInterval) error {
+	return i.intersectWith(j)
+}
+
+// IsEmpty is true if the time interval i contains no values, meaning its right
+// bound is less than its left bound, like with [2,1], or both bounds have the
+// same value and one of them is open, like with ]1,1]. An un-initialized
+// interval is treated as [0,w[ and is not empty.
+func (i *TimeInterval) IsEmpty() bool {
+	if i.Left.Bkind == BINFTY || i.Right.Bkind == BINFTY {
+		return false
+	}
+	if i.Right.Value < i.Left.Value {
+		return true
+	}
+	return i.Right.Value == i.Left.Value && (i.Left.Bkind == BOPEN || i.Right.Bkind == BOPEN)
+}
+
+// FiringWindow returns the interval of (absolute) dates at which transition t
+// may fire, given that it became enabled at date enabledSince. This is the
+// static time interval of t shifted by enabledSince.
+func (net *Net) FiringWindow(t int, enabledSince int) TimeInterval {
+	return net.Time[t].Shift(enabledSince)
+}
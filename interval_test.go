@@ -0,0 +1,192 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import "testing"
+
+func TestIntervalContains(t *testing.T) {
+	tables := []struct {
+		i        TimeInterval
+		v        int
+		expected bool
+	}{
+		{TimeInterval{Bound{BOPEN, 1}, Bound{BCLOSE, 3}}, 0, false},
+		{TimeInterval{Bound{BOPEN, 1}, Bound{BCLOSE, 3}}, 1, false},
+		{TimeInterval{Bound{BOPEN, 1}, Bound{BCLOSE, 3}}, 2, true},
+		{TimeInterval{Bound{BOPEN, 1}, Bound{BCLOSE, 3}}, 3, true},
+		{TimeInterval{Bound{BOPEN, 1}, Bound{BCLOSE, 3}}, 4, false},
+		{TimeInterval{Bound{BCLOSE, 0}, Bound{BCLOSE, 0}}, -1, false},
+		{TimeInterval{Bound{BCLOSE, 0}, Bound{BCLOSE, 0}}, 0, true},
+		{TimeInterval{Bound{BCLOSE, 0}, Bound{BCLOSE, 0}}, 1, false},
+		{TimeInterval{Bound{BCLOSE, 2}, Bound{BINFTY, 0}}, 1, false},
+		{TimeInterval{Bound{BCLOSE, 2}, Bound{BINFTY, 0}}, 2, true},
+		{TimeInterval{Bound{BCLOSE, 2}, Bound{BINFTY, 0}}, 1000, true},
+		{TimeInterval{Bound{BCLOSE, 2}, Bound{BOPEN, 4}}, 4, false},
+		{TimeInterval{Bound{BINFTY, 0}, Bound{BINFTY, 0}}, 0, true},
+		{TimeInterval{Bound{BINFTY, 0}, Bound{BINFTY, 0}}, -1, false},
+	}
+	for _, tt := range tables {
+		if actual := tt.i.Contains(tt.v); actual != tt.expected {
+			t.Errorf("%s .Contains(%d): expected %v, actual %v", tt.i.String(), tt.v, tt.expected, actual)
+		}
+	}
+}
+
+func TestIntervalAdd(t *testing.T) {
+	tables := []struct {
+		i, j     TimeInterval
+		expected string
+	}{
+		{TimeInterval{Bound{BCLOSE, 1}, Bound{BCLOSE, 2}}, TimeInterval{Bound{BOPEN, 0}, Bound{BOPEN, 3}}, "]1,5["},
+		{TimeInterval{Bound{BCLOSE, 1}, Bound{BCLOSE, 2}}, TimeInterval{Bound{BCLOSE, 0}, Bound{BCLOSE, 3}}, "[1,5]"},
+		{TimeInterval{Bound{BOPEN, 1}, Bound{BCLOSE, 2}}, TimeInterval{Bound{BCLOSE, 2}, Bound{BOPEN, 3}}, "]3,5["},
+		{TimeInterval{Bound{BCLOSE, 1}, Bound{BCLOSE, 2}}, TimeInterval{Bound{BCLOSE, 4}, Bound{BINFTY, 0}}, "[5,w["},
+		{TimeInterval{Bound{BOPEN, 1}, Bound{BINFTY, 0}}, TimeInterval{Bound{BCLOSE, 0}, Bound{BCLOSE, 0}}, "]1,w["},
+		{TimeInterval{Bound{BINFTY, 0}, Bound{BINFTY, 0}}, TimeInterval{Bound{BCLOSE, 1}, Bound{BCLOSE, 2}}, "[1,w["},
+	}
+	for _, tt := range tables {
+		actual := tt.i.Add(tt.j)
+		if actual.String() != tt.expected {
+			t.Errorf("%s .Add(%s): expected %s, actual %s", tt.i.String(), tt.j.String(), tt.expected, actual.String())
+		}
+		if rev := tt.j.Add(tt.i); rev != actual {
+			t.Errorf("%s .Add(%s): expected %s, actual %s", tt.j.String(), tt.i.String(), actual.String(), rev.String())
+		}
+	}
+}
+
+func TestIntervalShift(t *testing.T) {
+	tables := []struct {
+		i        TimeInterval
+		d        int
+		expected string
+	}{
+		{TimeInterval{Bound{BCLOSE, 1}, Bound{BCLOSE, 3}}, 2, "[3,5]"},
+		{TimeInterval{Bound{BOPEN, 1}, Bound{BOPEN, 3}}, 2, "]3,5["},
+		{TimeInterval{Bound{BOPEN, 0}, Bound{BCLOSE, 0}}, 4, "]4,4]"},
+		{TimeInterval{Bound{BCLOSE, 2}, Bound{BINFTY, 0}}, 5, "[7,w["},
+		{TimeInterval{Bound{BOPEN, 2}, Bound{BINFTY, 0}}, 5, "]7,w["},
+		{TimeInterval{Bound{BCLOSE, 1}, Bound{BOPEN, 3}}, 0, "[1,3["},
+		{TimeInterval{Bound{BCLOSE, 4}, Bound{BCLOSE, 6}}, -4, "[0,2]"},
+		{TimeInterval{Bound{BINFTY, 0}, Bound{BINFTY, 0}}, 3, "[3,w["},
+		{TimeInterval{Bound{BINFTY, 0}, Bound{BINFTY, 0}}, 0, "[0,w["},
+	}
+	for _, tt := range tables {
+		before := tt.i
+		actual := tt.i.Shift(tt.d)
+		if actual.String() != tt.expected {
+			t.Errorf("%s .Shift(%d): expected %s, actual %s", tt.i.String(), tt.d, tt.expected, actual.String())
+		}
+		if tt.i != before {
+			t.Errorf("%s .Shift(%d) should not modify the interval", before.String(), tt.d)
+		}
+		if tt.i.Left.Bkind != BINFTY && actual.Right.Bkind == BINFTY && !actual.Contains(tt.i.Left.Value+tt.d+1000) {
+			t.Errorf("%s .Shift(%d): right bound should stay infinite", tt.i.String(), tt.d)
+		}
+	}
+}
+
+func TestFiringWindow(t *testing.T) {
+	net, err := ParseString("tr t0 [1,3] p0 -> p1\ntr t1 ]2,w[ p1 -> p0\ntr t2 p0 ->\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	for k, expected := range []string{"[11,13]", "]12,w[", "[10,w["} {
+		if actual := net.FiringWindow(k, 10); actual.String() != expected {
+			t.Errorf("FiringWindow(%s, 10): expected %s, actual %s", net.Tr[k], expected, actual.String())
+		}
+	}
+	if w := net.FiringWindow(0, 10); !w.Contains(11) || !w.Contains(13) || w.Contains(10) || w.Contains(14) {
+		t.Errorf("FiringWindow(t0, 10): wrong window %s", w.String())
+	}
+	if w := net.FiringWindow(1, 10); w.Contains(12) || !w.Contains(13) {
+		t.Errorf("FiringWindow(t1, 10): wrong window %s", w.String())
+	}
+}
+
+func TestIntervalIsEmpty(t *testing.T) {
+	tables := []struct {
+		i        TimeInterval
+		expected bool
+	}{
+		{TimeInterval{Bound{BCLOSE, 2}, Bound{BCLOSE, 1}}, true},
+		{TimeInterval{Bound{BOPEN, 1}, Bound{BOPEN, 1}}, true},
+		{TimeInterval{Bound{BOPEN, 1}, Bound{BCLOSE, 1}}, true},
+		{TimeInterval{Bound{BCLOSE, 1}, Bound{BOPEN, 1}}, true},
+		{TimeInterval{Bound{BCLOSE, 1}, Bound{BCLOSE, 1}}, false},
+		{TimeInterval{Bound{BOPEN, 1}, Bound{BOPEN, 2}}, false},
+		{TimeInterval{Bound{BOPEN, 5}, Bound{BINFTY, 0}}, false},
+		{TimeInterval{Bound{BINFTY, 0}, Bound{BINFTY, 0}}, false},
+	}
+	for _, tt := range tables {
+		if actual := tt.i.IsEmpty(); actual != tt.expected {
+			t.Errorf("%v .IsEmpty(): expected %v, actual %v", tt.i, tt.expected, actual)
+		}
+	}
+}
+
+func TestIntervalIntersectWith(t *testing.T) {
+	tables := []struct {
+		i, j     TimeInterval
+		expected string
+		empty    bool
+	}{
+		{TimeInterval{Bound{BCLOSE, 0}, Bound{BCLOSE, 3}}, TimeInterval{Bound{BOPEN, 1}, Bound{BINFTY, 0}}, "]1,3]", false},
+		{TimeInterval{Bound{BCLOSE, 0}, Bound{BOPEN, 2}}, TimeInterval{Bound{BCLOSE, 1}, Bound{BCLOSE, 4}}, "[1,2[", false},
+		{TimeInterval{Bound{BINFTY, 0}, Bound{BINFTY, 0}}, TimeInterval{Bound{BCLOSE, 1}, Bound{BCLOSE, 4}}, "[1,4]", false},
+		{TimeInterval{Bound{BCLOSE, 0}, Bound{BOPEN, 1}}, TimeInterval{Bound{BCLOSE, 1}, Bound{BCLOSE, 4}}, "", true},
+		{TimeInterval{Bound{BCLOSE, 0}, Bound{BCLOSE, 1}}, TimeInterval{Bound{BCLOSE, 2}, Bound{BINFTY, 0}}, "", true},
+	}
+	for _, tt := range tables {
+		i := tt.i
+		err := i.IntersectWith(tt.j)
+		if tt.empty {
+			if err == nil {
+				t.Errorf("%s .IntersectWith(%s): expected error, actual %s", tt.i.String(), tt.j.String(), i.String())
+			}
+			continue
+		}
+		if err != nil || i.String() != tt.expected {
+			t.Errorf("%s .IntersectWith(%s): expected %s, actual %s (%v)", tt.i.String(), tt.j.String(), tt.expected, i.String(), err)
+		}
+	}
+}
+
+func TestNewInterval(t *testing.T) {
+	tables := []struct {
+		lo, hi             int
+		loClosed, hiClosed bool
+		expected           string
+		ok                 bool
+	}{
+		{1, 3, false, true, "]1,3]", true},
+		{0, 0, true, true, "[0,0]", true},
+		{2, 5, true, false, "[2,5[", true},
+		{2, 1, true, true, "", false},
+		{1, 1, false, false, "", false},
+		{1, 1, true, false, "", false},
+		{-1, 2, true, true, "", false},
+	}
+	for _, tt := range tables {
+		i, err := NewInterval(tt.lo, tt.hi, tt.loClosed, tt.hiClosed)
+		if (err == nil) != tt.ok {
+			t.Errorf("NewInterval(%d, %d, %v, %v): unexpected error value %v", tt.lo, tt.hi, tt.loClosed, tt.hiClosed, err)
+			continue
+		}
+		if tt.ok && i.String() != tt.expected {
+			t.Errorf("NewInterval(%d, %d, %v, %v): expected %s, actual %s", tt.lo, tt.hi, tt.loClosed, tt.hiClosed, tt.expected, i.String())
+		}
+	}
+	for _, tt := range []struct {
+		lo       int
+		loClosed bool
+		expected string
+	}{{2, true, "[2,w["}, {0, false, "]0,w["}} {
+		i := NewUnboundedInterval(tt.lo, tt.loClosed)
+		if i.String() != tt.expected {
+			t.Errorf("NewUnboundedInterval(%d, %v): expected %s, actual %s", tt.lo, tt.loClosed, tt.expected, i.String())
+		}
+	}
+}
@@ -0,0 +1,455 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"slices"
+)
+
+// maxFarkasRows is the maximal number of rows that we accept during the
+// computation of semiflows. The Farkas algorithm may generate an exponential
+// number of intermediate rows and we prefer to stop with an error.
+const maxFarkasRows = 1 << 16
+
+// farkasRow is a row in the Farkas algorithm; we store the values of the
+// constraints in c and the (identity) coefficients of the variables in x.
+type farkasRow struct {
+	c, x []int
+}
+
+// semiflows returns the minimal-support non-negative integer solutions x of
+// the system x·a = 0, where a is a matrix with one row for each variable and
+// one column for each constraint. We use the Farkas algorithm, where we
+// eliminate constraints one at a time by combining rows with opposite signs,
+// choosing at each step the constraint that generates the least number of
+// rows, and we remove rows that do not have a minimal support at each step.
+// Each solution is normalized so that the gcd of its coefficients is 1. The
+// result is sorted in lexicographic order.
+//
+// We return an error if the number of intermediate rows gets too big or if a
+// coefficient overflows.
+func semiflows(a [][]int) ([][]int, error) {
+	rows := make([]farkasRow, len(a))
+	for k, v := range a {
+		rows[k] = farkasRow{c: slices.Clone(v), x: make([]int, len(a))}
+		rows[k].x[k] = 1
+	}
+	ncols := 0
+	if len(a) != 0 {
+		ncols = len(a[0])
+	}
+	done := make([]bool, ncols)
+	for range ncols {
+		j := nextColumn(rows, done)
+		done[j] = true
+		var pos, neg, next []farkasRow
+		for _, r := range rows {
+			switch {
+			case r.c[j] > 0:
+				pos = append(pos, r)
+			case r.c[j] < 0:
+				neg = append(neg, r)
+			default:
+				next = append(next, r)
+			}
+		}
+		if len(next)+len(pos)*len(neg) > maxFarkasRows {
+			return nil, fmt.Errorf("too many intermediate rows when computing semiflows")
+		}
+		for _, rp := range pos {
+			for _, rn := range neg {
+				r, err := combineRows(rp, -rn.c[j], rn, rp.c[j])
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, r)
+			}
+		}
+		rows = minimalRows(next)
+	}
+	res := make([][]int, len(rows))
+	for k, r := range rows {
+		res[k] = r.x
+	}
+	slices.SortFunc(res, slices.Compare)
+	return res, nil
+}
+
+// nextColumn returns the index of the column, among those not already
+// eliminated, that generates the least number of new rows. This heuristic
+// greatly reduces the size of intermediate results.
+func nextColumn(rows []farkasRow, done []bool) int {
+	best, cost := -1, 0
+	for j := range done {
+		if done[j] {
+			continue
+		}
+		npos, nneg := 0, 0
+		for _, r := range rows {
+			if r.c[j] > 0 {
+				npos++
+			} else if r.c[j] < 0 {
+				nneg++
+			}
+		}
+		if c := npos*nneg - npos - nneg; best == -1 || c < cost {
+			best, cost = j, c
+		}
+	}
+	return best
+}
+
+// combineRows returns the row k1*r1 + k2*r2, normalized by the gcd of its
+// coefficients.
+func combineRows(r1 farkasRow, k1 int, r2 farkasRow, k2 int) (farkasRow, error) {
+	res := farkasRow{c: make([]int, len(r1.c)), x: make([]int, len(r1.x))}
+	g := 0
+	for _, v := range [][3][]int{{res.c, r1.c, r2.c}, {res.x, r1.x, r2.x}} {
+		for i := range v[0] {
+			// coefficients fit in 32 bits, so a, b and c cannot overflow
+			a, b := int64(k1)*int64(v[1][i]), int64(k2)*int64(v[2][i])
+			c := a + b
+			if !fitsInt32(a) || !fitsInt32(b) || !fitsInt32(c) {
+				return res, fmt.Errorf("overflow when computing semiflows")
+			}
+			v[0][i] = int(c)
+			g = gcd(g, v[0][i])
+		}
+	}
+	if g > 1 {
+		for i := range res.c {
+			res.c[i] /= g
+		}
+		for i := range res.x {
+			res.x[i] /= g
+		}
+	}
+	return res, nil
+}
+
+// fitsInt32 reports whether v is in the range of int32, so that it can be
+// stored in an int on every architecture.
+func fitsInt32(v int64) bool {
+	return v >= math.MinInt32 && v <= math.MaxInt32
+}
+
+// minimalRows removes the rows that are null, duplicated, or whose support (the
+// set of variables with a non-null coefficient) strictly includes the support
+// of another row. We use bitsets to compare supports efficiently.
+func minimalRows(rows []farkasRow) []farkasRow {
+	supp := make([]Bitset, len(rows))
+	for k, r := range rows {
+		supp[k] = NewBitset(len(r.x))
+		for i, v := range r.x {
+			if v != 0 {
+				supp[k].Set(i)
+			}
+		}
+	}
+	res := []farkasRow{}
+	for k, r := range rows {
+		if supp[k].Count() == 0 {
+			continue
+		}
+		keep := true
+		for k2 := range rows {
+			if k2 == k || !supp[k2].includedIn(supp[k]) {
+				continue
+			}
+			if !supp[k].includedIn(supp[k2]) || (k2 < k && slices.Equal(r.x, rows[k2].x)) {
+				// rows[k2] has a smaller support, or r is a duplicate of a
+				// previous row
+				keep = false
+				break
+			}
+		}
+		if keep {
+			res = append(res, r)
+		}
+	}
+	return res
+}
+
+func gcd(a, b int) int {
+	if a < 0 {
+		a = -a
+	}
+	if b < 0 {
+		b = -b
+	}
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// IncidenceMatrix returns the incidence matrix of the net, as a dense matrix
+// with one row for each transition and one column for each place, such that
+// the value at row t and column p is post(t,p) - pre(t,p), the number of
+// tokens added to p when firing t. Rows are obtained from Delta, so read arcs
+// and inhibitor arcs, that only appear in Cond and Inhib, do not contribute to
+// the matrix. Likewise, the effect of reset arcs is not taken into account. We
+// return an error if a transition has probabilistic arcs, since its effect
+// cannot be given by a single row.
+func (net *Net) IncidenceMatrix() ([][]int, error) {
+	c := make([][]int, len(net.Tr))
+	for t := range net.Tr {
+		if len(net.probArcs(t)) != 0 {
+			return nil, fmt.Errorf("no incidence matrix, transition %s has probabilistic arcs", net.Tr[t])
+		}
+		c[t] = make([]int, len(net.Pl))
+		for _, v := range net.Delta[t] {
+			c[t][v.Pl] = v.Mult
+		}
+	}
+	return c, nil
+}
+
+// PInvariants returns the minimal-support P-semiflows of the net, meaning the
+// non-negative integer vectors x (indexed by places) such that C·x = 0, where
+// C is the incidence matrix of the net (see IncidenceMatrix). Hence the
+// weighted sum of tokens x·M is the same for every marking M obtained by firing
+// a transition. Every non-negative P-semiflow is a non-negative rational
+// combination of the vectors in the result, and each vector is normalized so
+// that the gcd of its coefficients is 1. The result is sorted in lexicographic
+// order. We ignore read arcs, inhibitor arcs, timing constraints and
+// priorities.
+//
+// We use the Farkas algorithm, with integer coefficients, that can generate an
+// exponential number of intermediate rows. We return an error, instead of a
+// partial result, if the number of rows gets too big for large nets, or if a
+// coefficient overflows.
+//
+// The effect of a reset arc depends on the marking, so a place reset by some
+// transition cannot be part of an invariant. We enforce this by adding one
+// constraint for each reset place, with a single non-null coefficient.
+// Likewise, a transition with probabilistic arcs adds one constraint for each
+// of its outcomes (see SampleOutcome), since the weighted sum of tokens must
+// be preserved whatever the branch chosen.
+func (net *Net) PInvariants() ([][]int, error) {
+	a := make([][]int, len(net.Pl))
+	column := func(m Marking) {
+		for p := range a {
+			a[p] = append(a[p], m.Get(p))
+		}
+	}
+	reset := []int{}
+	for t := range net.Tr {
+		for _, d := range net.outcomes(t) {
+			column(d)
+		}
+		for _, p := range net.resets(t) {
+			reset = setAdd(reset, p)
+		}
+	}
+	for _, p := range reset {
+		column(Marking{Atom{p, 1}})
+	}
+	return semiflows(a)
+}
+
+// TransitionInvariants returns the minimal-support T-semiflows of the net,
+// meaning the non-negative vectors x (indexed by transitions) such that firing
+// every transition t exactly x[t] times, in some order, leaves the marking
+// unchanged. Like with place invariants, we only consider the effect of
+// transitions (Delta) and ignore read arcs, inhibitor arcs, reset arcs, timing
+// constraints and priorities. The result is sorted in lexicographic order. We
+// return an error if the net has probabilistic arcs (see IncidenceMatrix) or
+// if the computation of semiflows fails.
+func (net *Net) TransitionInvariants() ([][]int, error) {
+	c, err := net.IncidenceMatrix()
+	if err != nil {
+		return nil, err
+	}
+	return semiflows(c)
+}
+
+// PotentiallyLive returns the transitions that belong to the support of at
+// least one T-semiflow of the net, in increasing order. In a bounded net, a
+// transition that can be fired infinitely often must take part in a firing
+// sequence that returns to a previously visited marking, and therefore in the
+// support of some T-semiflow. This is a necessary, but not sufficient,
+// condition for liveness: a transition outside of the result cannot be live in
+// a bounded net, but a transition in the result may never be fireable. We
+// return nil if we fail to compute the invariants.
+func (net *Net) PotentiallyLive() []int {
+	inv, err := net.TransitionInvariants()
+	if err != nil {
+		return nil
+	}
+	supp := NewBitset(len(net.Tr))
+	for _, x := range inv {
+		for t, v := range x {
+			if v > 0 {
+				supp.Set(t)
+			}
+		}
+	}
+	return supp.Slice()
+}
+
+// CoverableUpperBound is a quick, sound, check used to refute the coverability
+// of a target marking using the place invariants of the net. For every
+// P-semiflow x, the value of x·M is the same for every reachable marking M.
+// Hence, if x·target > x·Initial, no reachable marking can cover target. We
+// return false when one of the invariants proves that target cannot be
+// covered. A true result is inconclusive: the target may, or may not, be
+// coverable. We return an error if we fail to compute the invariants.
+func (net *Net) CoverableUpperBound(target Marking) (bool, error) {
+	inv, err := net.PInvariants()
+	if err != nil {
+		return true, err
+	}
+	for _, x := range inv {
+		if weightedSum(x, target) > weightedSum(x, net.Initial) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// weightedSum returns the sum of x[p] * m(p) over all places p.
+func weightedSum(x []int, m Marking) int {
+	s := 0
+	for _, a := range m {
+		s += x[a.Pl] * a.Mult
+	}
+	return s
+}
+
+// placeBounds returns, for every place p, an upper bound on the marking of p
+// in every reachable marking, derived from the P-semiflows of the net. For a
+// semiflow x with x[p] > 0, we have x[p] * M(p) <= x·M = x·Initial, and we
+// keep the best bound over all semiflows. The bound is -1 for places that are
+// not covered by any semiflow.
+func (net *Net) placeBounds() ([]int, error) {
+	inv, err := net.PInvariants()
+	if err != nil {
+		return nil, err
+	}
+	bounds := make([]int, len(net.Pl))
+	for p := range bounds {
+		bounds[p] = -1
+	}
+	for _, x := range inv {
+		s := weightedSum(x, net.Initial)
+		for p, v := range x {
+			if v > 0 && (bounds[p] == -1 || s/v < bounds[p]) {
+				bounds[p] = s / v
+			}
+		}
+	}
+	return bounds, nil
+}
+
+// EstimateStateSpace returns an upper bound on the number of reachable
+// markings of the net, without exploring its state space. We compute a bound,
+// b(p), on the marking of every place p using the place invariants of the net
+// and return the product of the values b(p) + 1. The result can be
+// astronomically large, hence the use of a big.Int. We return an error if some
+// place is not covered by a place invariant, in which case we cannot bound its
+// marking.
+func (net *Net) EstimateStateSpace() (*big.Int, error) {
+	bounds, err := net.placeBounds()
+	if err != nil {
+		return nil, err
+	}
+	res := big.NewInt(1)
+	for p, b := range bounds {
+		if b < 0 {
+			return nil, fmt.Errorf("cannot bound the marking of place %s", net.Pl[p])
+		}
+		res.Mul(res, big.NewInt(int64(b)+1))
+	}
+	return res, nil
+}
+
+// IsConservative reports whether the net has a strictly positive place
+// invariant, meaning a weighting x of places, with x[p] >= 1 for every place
+// p, such that x·M is the same for every reachable marking M. Conservative
+// nets are structurally bounded. Since the sum of two semiflows is also a
+// semiflow, this is the case exactly when every place is covered by a minimal
+// P-semiflow. We return false if we fail to compute the invariants.
+func (net *Net) IsConservative() bool {
+	inv, err := net.PInvariants()
+	if err != nil {
+		return false
+	}
+	covered := NewBitset(len(net.Pl))
+	for _, x := range inv {
+		for p, v := range x {
+			if v > 0 {
+				covered.Set(p)
+			}
+		}
+	}
+	return covered.Count() == len(net.Pl)
+}
+
+// StructurallyBounded reports whether the net is bounded for every initial
+// marking. We check that the net is sub-conservative, meaning that there is a
+// weighting y of places, with y[p] >= 1 for every place p, such that y·C <= 0,
+// where C is the incidence matrix. In this case the weighted sum of tokens y·M
+// never increases, and the marking of place p is bounded by y·Initial / y[p].
+// Conservative nets (see IsConservative), that have a strictly positive place
+// invariant, are sub-conservative. For nets with only normal arcs, being
+// sub-conservative is equivalent to being structurally bounded.
+//
+// We look for the weighting using the Farkas algorithm, with one additional
+// variable for every transition, so we return false if the computation fails
+// on large nets. Read arcs, inhibitor arcs, priorities and timing constraints
+// can only restrict the behavior of the net, so we ignore them and the result
+// stays valid. For a place p reset by transition t, we use the largest
+// possible effect of t on p, which is the number of tokens produced in p minus
+// the number of tokens required to fire t. A transition with probabilistic
+// arcs has one column in C for each of its outcomes (see SampleOutcome).
+//
+// A false result does not mean that the net is unbounded for its initial
+// marking, or even for some marking. It only means that we cannot prove
+// boundedness using this method.
+func (net *Net) StructurallyBounded() bool {
+	// C has one column for each outcome of a transition
+	cols := [][]int{}
+	for t := range net.Tr {
+		for _, d := range net.outcomes(t) {
+			c := make([]int, len(net.Pl))
+			for _, v := range d {
+				c[v.Pl] = v.Mult
+			}
+			for _, p := range net.resets(t) {
+				c[p] = d.Get(p) - net.Pre[t].Get(p) - net.Cond[t].Get(p)
+			}
+			cols = append(cols, c)
+		}
+	}
+	// the constraints y·C + s = 0, with s >= 0, have one row for each place
+	// and one row for each slack variable.
+	a := make([][]int, len(net.Pl)+len(cols))
+	for k := range a {
+		a[k] = make([]int, len(cols))
+	}
+	for j, c := range cols {
+		for p, v := range c {
+			a[p][j] = v
+		}
+		a[len(net.Pl)+j][j] = 1
+	}
+	inv, err := semiflows(a)
+	if err != nil {
+		return false
+	}
+	covered := NewBitset(len(net.Pl))
+	for _, x := range inv {
+		for p := range net.Pl {
+			if x[p] > 0 {
+				covered.Set(p)
+			}
+		}
+	}
+	return covered.Count() == len(net.Pl)
+}
@@ -0,0 +1,207 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCoverableUpperBound(t *testing.T) {
+	// a simple mutex where p0 + p1 is invariant
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p0\ntr t2 -> p2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	tables := []struct {
+		target   Marking
+		expected bool
+	}{
+		{Marking{}, true},
+		{Marking{Atom{1, 1}}, true},
+		{Marking{Atom{0, 1}, Atom{1, 1}}, false},
+		{Marking{Atom{1, 2}}, false},
+		{Marking{Atom{2, 10}}, true},
+	}
+	for _, tt := range tables {
+		actual, err := net.CoverableUpperBound(tt.target)
+		if err != nil {
+			t.Fatalf("Error computing invariants; %s", err)
+		}
+		if actual != tt.expected {
+			t.Errorf("CoverableUpperBound(%s): expected %v, actual %v", net.Mtoa(tt.target), tt.expected, actual)
+		}
+	}
+}
+
+func TestEstimateStateSpace(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (2)\ntr t0 p0 -> p1\ntr t1 p1 -> p0\npl q0 (1)\ntr t2 q0 -> q1\ntr t3 q1 -> q0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	actual, err := net.EstimateStateSpace()
+	if err != nil {
+		t.Fatalf("Error in EstimateStateSpace; %s", err)
+	}
+	// (2+1) * (2+1) * (1+1) * (1+1)
+	if actual.Int64() != 36 {
+		t.Errorf("EstimateStateSpace: expected 36, actual %s", actual)
+	}
+	net, _ = Parse(strings.NewReader("tr t0 -> p0\n"))
+	if _, err := net.EstimateStateSpace(); err == nil {
+		t.Errorf("EstimateStateSpace should fail on unbounded places")
+	}
+}
+
+func TestIsConservative(t *testing.T) {
+	tables := []struct {
+		net      string
+		expected bool
+	}{
+		{"pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p0\n", true},
+		{"pl p0 (1)\ntr t0 p0 -> p1 p2\ntr t1 p1 p2 -> p0\n", true},
+		{"pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p0\ntr t2 -> p2\n", false},
+		{"pl p0 (1)\ntr t0 p0 -> p0 p1\n", false},
+	}
+	for _, v := range tables {
+		net, err := Parse(strings.NewReader(v.net))
+		if err != nil {
+			t.Fatalf("Error parsing net; %s", err)
+		}
+		if actual := net.IsConservative(); actual != v.expected {
+			t.Errorf("IsConservative(%q): expected %v, actual %v", v.net, v.expected, actual)
+		}
+	}
+}
+
+func TestTransitionInvariants(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p0\ntr t2 p1 -> p2\ntr t3 p0*2 -> p0 p0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	inv, err := net.TransitionInvariants()
+	if err != nil {
+		t.Fatalf("Error in TransitionInvariants; %s", err)
+	}
+	expected := [][]int{{0, 0, 0, 1}, {1, 1, 0, 0}}
+	if !slices.EqualFunc(inv, expected, slices.Equal) {
+		t.Errorf("TransitionInvariants: expected %v, actual %v", expected, inv)
+	}
+	if actual := net.PotentiallyLive(); !slices.Equal(actual, []int{0, 1, 3}) {
+		t.Errorf("PotentiallyLive: expected [0 1 3], actual %v", actual)
+	}
+}
+
+func TestIncidenceMatrix(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0*2 -> p1\ntr t1 p1 p2?1 -> p0 p1\ntr t2 p0?-3 p2 -> p2*4\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	// places are p0, p1, p2, in this order
+	expected := [][]int{
+		{-2, 1, 0},
+		{1, 0, 0},
+		{0, 0, 3},
+	}
+	actual, err := net.IncidenceMatrix()
+	if err != nil {
+		t.Fatalf("Error in IncidenceMatrix; %s", err)
+	}
+	if len(actual) != len(expected) {
+		t.Fatalf("IncidenceMatrix: expected %v, actual %v", expected, actual)
+	}
+	for k := range expected {
+		if !slices.Equal(actual[k], expected[k]) {
+			t.Errorf("IncidenceMatrix: expected row %v for %s, actual %v", expected[k], net.Tr[k], actual[k])
+		}
+	}
+}
+
+func TestPInvariants(t *testing.T) {
+	net, err := ParseFile("testdata/abp.net")
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/abp.net; %s", err)
+	}
+	inv, err := net.PInvariants()
+	if err != nil {
+		t.Fatalf("Error in PInvariants; %s", err)
+	}
+	// the sender (p1 to p4) and the receiver (p5 to p8) are both in exactly
+	// one state, whereas the channels (p9 to p12) are not bounded.
+	expected := [][]string{{"p5", "p6", "p7", "p8"}, {"p1", "p2", "p3", "p4"}}
+	if len(inv) != len(expected) {
+		t.Fatalf("PInvariants: expected %d invariants, actual %v", len(expected), inv)
+	}
+	for k, x := range inv {
+		support := []string{}
+		for p, v := range x {
+			if v != 0 {
+				if v != 1 {
+					t.Errorf("PInvariants: expected coefficient 1 for %s, actual %d", net.Pl[p], v)
+				}
+				support = append(support, net.Pl[p])
+			}
+		}
+		slices.Sort(support)
+		if !slices.Equal(support, expected[k]) {
+			t.Errorf("PInvariants: expected support %v, actual %v", expected[k], support)
+		}
+		if weightedSum(x, net.Initial) != 1 {
+			t.Errorf("PInvariants: expected weighted sum 1 for invariant %v", x)
+		}
+	}
+}
+
+func TestCombineRows(t *testing.T) {
+	r1 := farkasRow{c: []int{1 << 30}, x: []int{1, 0}}
+	r2 := farkasRow{c: []int{1 << 30}, x: []int{0, 1}}
+	// each product fits in 32 bits, but not their sum
+	if _, err := combineRows(r1, 1, r2, 1); err == nil {
+		t.Errorf("combineRows: expected overflow error")
+	}
+	r, err := combineRows(r1, 1, r2, -1)
+	if err != nil || r.c[0] != 0 || !slices.Equal(r.x, []int{1, -1}) {
+		t.Errorf("combineRows: expected {[0] [1 -1]}, actual %v, %v", r, err)
+	}
+}
+
+func TestStructurallyBounded(t *testing.T) {
+	tables := []struct {
+		net      string
+		expected bool
+	}{
+		{"", true},
+		{"pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p0\n", true},
+		{"pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p0\ntr t2 -> p2\n", false},
+		{"pl p0 (1)\ntr t0 p0 -> p0 p1\n", false},
+		// sub-conservative but not conservative
+		{"pl p0 (2)\ntr t0 p0*2 -> p1\ntr t1 p1 -> p0\n", true},
+		{"pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 ->\n", true},
+		// a read arc does not change the effect of t0
+		{"pl p0 (1)\ntr t0 p0 p1?1 -> p0 p1\n", false},
+		// reset arcs
+		{"pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 p1?! -> p0\n", true},
+		{"pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1?! -> p0\n", false},
+	}
+	for _, v := range tables {
+		net, err := Parse(strings.NewReader(v.net))
+		if err != nil {
+			t.Fatalf("Error parsing net; %s", err)
+		}
+		if actual := net.StructurallyBounded(); actual != v.expected {
+			t.Errorf("StructurallyBounded(%q): expected %v, actual %v", v.net, v.expected, actual)
+		}
+	}
+	for _, v := range []string{"abp.net", "ifip.net", "demo.net"} {
+		net, err := ParseFile("testdata/" + v)
+		if err != nil {
+			t.Fatalf("Error parsing file; %s", err)
+		}
+		if net.IsConservative() && !net.StructurallyBounded() {
+			t.Errorf("StructurallyBounded(%s): conservative nets are structurally bounded", v)
+		}
+	}
+}
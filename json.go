@@ -0,0 +1,337 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// jsonNet is the JSON representation of a net. Places and transitions are
+// listed in the same order as in the net, and we use names instead of indexes
+// to refer to them, so that the result is easy to use from other languages.
+type jsonNet struct {
+	Name          string                    `json:"name"`
+	Places        []jsonPlace               `json:"places"`
+	Transitions   []jsonTransition          `json:"transitions"`
+	NamedMarkings map[string]map[string]int `json:"markings,omitempty"`
+	Colors        map[string]string         `json:"colors,omitempty"`
+	Notes         []jsonNote                `json:"notes,omitempty"`
+}
+
+type jsonPlace struct {
+	Name    string `json:"name"`
+	Label   string `json:"label,omitempty"`
+	Initial int    `json:"initial,omitempty"`
+}
+
+type jsonTransition struct {
+	Name     string         `json:"name"`
+	Label    string         `json:"label,omitempty"`
+	Time     jsonInterval   `json:"time"`
+	Cond     map[string]int `json:"cond,omitempty"`
+	Inhib    map[string]int `json:"inhib,omitempty"`
+	Pre      map[string]int `json:"pre,omitempty"`
+	Delta    map[string]int `json:"delta,omitempty"`
+	Reset    []string       `json:"reset,omitempty"`
+	Prob     []jsonProbArc  `json:"prob,omitempty"`
+	Prio     []string       `json:"priority,omitempty"`
+	Disabled bool           `json:"disabled,omitempty"`
+	Weight   *float64       `json:"weight,omitempty"`
+	Server   string         `json:"server,omitempty"`
+}
+
+type jsonInterval struct {
+	Left  jsonBound `json:"left"`
+	Right jsonBound `json:"right"`
+}
+
+type jsonBound struct {
+	Kind  string `json:"kind"`
+	Value int    `json:"value,omitempty"`
+}
+
+type jsonProbArc struct {
+	Place string  `json:"place"`
+	Mult  int     `json:"mult"`
+	Prob  float64 `json:"prob"`
+}
+
+type jsonNote struct {
+	Name string `json:"name"`
+	Flag int    `json:"flag"`
+	Body string `json:"body"`
+}
+
+// jsonBkind gives the names of the kinds of bounds used in JSON.
+var jsonBkind = map[Bkind]string{BCLOSE: "closed", BOPEN: "open", BINFTY: "inf"}
+
+// MarshalJSON returns a JSON representation of the net, making Net a
+// json.Marshaler. The result is an object with fields name, places and
+// transitions (and optionally markings, colors and notes). Each place has a
+// name, a label, and an initial marking, and each transition has a name, a
+// label, a time interval, and its arcs. Bounds of time intervals are objects
+// with a kind, either "closed", "open" or "inf", and a value. Markings, such as
+// the condition or delta of a transition, are objects that map place names to
+// multiplicities, and the priority of a transition lists the names of the
+// transitions with less priority. Fields with default values are omitted.
+func (net *Net) MarshalJSON() ([]byte, error) {
+	jn := jsonNet{
+		Name:        net.Name,
+		Places:      make([]jsonPlace, len(net.Pl)),
+		Transitions: make([]jsonTransition, len(net.Tr)),
+		Colors:      net.NodeColor,
+	}
+	for p, v := range net.Pl {
+		jn.Places[p] = jsonPlace{Name: v, Label: net.Plabel[p], Initial: net.Initial.Get(p)}
+	}
+	for t, v := range net.Tr {
+		jt := jsonTransition{
+			Name:     v,
+			Label:    net.Tlabel[t],
+			Time:     jsonInterval{jsonBound{jsonBkind[net.Time[t].Left.Bkind], net.Time[t].Left.Value}, jsonBound{jsonBkind[net.Time[t].Right.Bkind], net.Time[t].Right.Value}},
+			Cond:     net.jsonMarking(net.Cond[t]),
+			Inhib:    net.jsonMarking(net.Inhib[t]),
+			Pre:      net.jsonMarking(net.Pre[t]),
+			Delta:    net.jsonMarking(net.Delta[t]),
+			Disabled: net.IsDisabled(t),
+		}
+		for _, p := range net.resets(t) {
+			jt.Reset = append(jt.Reset, net.Pl[p])
+		}
+		for _, a := range net.probArcs(t) {
+			jt.Prob = append(jt.Prob, jsonProbArc{net.Pl[a.Pl], a.Mult, a.Prob})
+		}
+		for _, t2 := range net.Prio[t] {
+			jt.Prio = append(jt.Prio, net.Tr[t2])
+		}
+		if t < len(net.Weight) {
+			w := net.Weight[t]
+			jt.Weight = &w
+		}
+		if s := net.Server(t); s != SingleServer {
+			jt.Server = s.String()
+		}
+		jn.Transitions[t] = jt
+	}
+	if len(net.NamedMarkings) != 0 {
+		jn.NamedMarkings = make(map[string]map[string]int)
+		for k, m := range net.NamedMarkings {
+			jn.NamedMarkings[k] = net.jsonMarking(m)
+			if jn.NamedMarkings[k] == nil {
+				jn.NamedMarkings[k] = map[string]int{}
+			}
+		}
+	}
+	for _, n := range net.Notes {
+		jn.Notes = append(jn.Notes, jsonNote(n))
+	}
+	return json.Marshal(jn)
+}
+
+// jsonMarking returns marking m as a map from place names to multiplicities,
+// or nil if m is empty.
+func (net *Net) jsonMarking(m Marking) map[string]int {
+	if len(m) == 0 {
+		return nil
+	}
+	res := make(map[string]int, len(m))
+	for _, a := range m {
+		res[net.Pl[a.Pl]] = a.Mult
+	}
+	return res
+}
+
+// UnmarshalJSON sets the net from its JSON representation, as returned by
+// MarshalJSON, making Net a json.Unmarshaler. We return an error if the input
+// refers to an unknown place or transition, if the same name is used for two
+// places (or two transitions), or if a bound has an unknown kind.
+func (net *Net) UnmarshalJSON(data []byte) error {
+	var jn jsonNet
+	if err := json.Unmarshal(data, &jn); err != nil {
+		return err
+	}
+	res := Net{Name: jn.Name, NodeColor: jn.Colors}
+	pl := make(map[string]int)
+	for p, v := range jn.Places {
+		if _, ok := pl[v.Name]; ok {
+			return fmt.Errorf("duplicate place %s in JSON net", v.Name)
+		}
+		pl[v.Name] = p
+		res.Pl = append(res.Pl, v.Name)
+		res.Plabel = append(res.Plabel, v.Label)
+		res.Initial = res.Initial.AddToPlace(p, v.Initial)
+	}
+	tr := make(map[string]int)
+	for t, v := range jn.Transitions {
+		if _, ok := tr[v.Name]; ok {
+			return fmt.Errorf("duplicate transition %s in JSON net", v.Name)
+		}
+		tr[v.Name] = t
+		res.addTransition(v.Name)
+	}
+	marking := func(m map[string]int) (Marking, error) {
+		var res Marking
+		for k, v := range m {
+			p, ok := pl[k]
+			if !ok {
+				return nil, fmt.Errorf("unknown place %s in JSON net", k)
+			}
+			res = res.AddToPlace(p, v)
+		}
+		return res, nil
+	}
+	bound := func(b jsonBound) (Bound, error) {
+		for k, v := range jsonBkind {
+			if v == b.Kind {
+				return Bound{k, b.Value}, nil
+			}
+		}
+		return Bound{}, fmt.Errorf("unknown bound kind %q in JSON net", b.Kind)
+	}
+	var err error
+	for t, v := range jn.Transitions {
+		res.Tlabel[t] = v.Label
+		if res.Time[t].Left, err = bound(v.Time.Left); err != nil {
+			return err
+		}
+		if res.Time[t].Right, err = bound(v.Time.Right); err != nil {
+			return err
+		}
+		if res.Cond[t], err = marking(v.Cond); err != nil {
+			return err
+		}
+		if res.Inhib[t], err = marking(v.Inhib); err != nil {
+			return err
+		}
+		if res.Pre[t], err = marking(v.Pre); err != nil {
+			return err
+		}
+		if res.Delta[t], err = marking(v.Delta); err != nil {
+			return err
+		}
+		for _, k := range v.Reset {
+			p, ok := pl[k]
+			if !ok {
+				return fmt.Errorf("unknown place %s in JSON net", k)
+			}
+			res.addReset(t, p)
+		}
+		for _, a := range v.Prob {
+			p, ok := pl[a.Place]
+			if !ok {
+				return fmt.Errorf("unknown place %s in JSON net", a.Place)
+			}
+			res.addProbArc(t, ProbArc{p, a.Mult, a.Prob})
+		}
+		for _, k := range v.Prio {
+			t2, ok := tr[k]
+			if !ok {
+				return fmt.Errorf("unknown transition %s in JSON net", k)
+			}
+			res.Prio[t] = setAdd(res.Prio[t], t2)
+		}
+		if v.Disabled {
+			res.Disable(t)
+		}
+		if v.Weight != nil {
+			for len(res.Weight) <= t {
+				res.Weight = append(res.Weight, 1)
+			}
+			res.Weight[t] = *v.Weight
+		}
+		if v.Server != "" {
+			s, err := parseServerKind(v.Server)
+			if err != nil {
+				return err
+			}
+			for len(res.ServerKind) <= t {
+				res.ServerKind = append(res.ServerKind, SingleServer)
+			}
+			res.ServerKind[t] = s
+		}
+	}
+	for k, v := range jn.NamedMarkings {
+		m, err := marking(v)
+		if err != nil {
+			return err
+		}
+		if res.NamedMarkings == nil {
+			res.NamedMarkings = make(map[string]Marking)
+		}
+		res.NamedMarkings[k] = m
+	}
+	for _, n := range jn.Notes {
+		res.Notes = append(res.Notes, Note(n))
+	}
+	res.indexNodes()
+	*net = res
+	return nil
+}
+
+// jsonAtom is the JSON representation of an atom in a marking.
+type jsonAtom struct {
+	Pl   int `json:"pl"`
+	Mult int `json:"mult"`
+}
+
+// MarshalJSON returns a JSON representation of marking m, making Marking a
+// json.Marshaler. The result is an array of objects of the form {"pl": 3,
+// "mult": 2}, in increasing order of place index. Since places are given by
+// their index, this representation does not depend on a net; see MarkingToJSON
+// for a representation using place names.
+func (m Marking) MarshalJSON() ([]byte, error) {
+	res := make([]jsonAtom, len(m))
+	for k, a := range m {
+		res[k] = jsonAtom(a)
+	}
+	return json.Marshal(res)
+}
+
+// UnmarshalJSON sets marking m from its JSON representation, as returned by
+// MarshalJSON, making Marking a json.Unmarshaler. We return an error if places
+// are not listed in strictly increasing order of index, if a place index is
+// negative, or if a multiplicity is 0, since the result would not be a valid
+// marking.
+func (m *Marking) UnmarshalJSON(data []byte) error {
+	var atoms []jsonAtom
+	if err := json.Unmarshal(data, &atoms); err != nil {
+		return err
+	}
+	res := make(Marking, len(atoms))
+	for k, a := range atoms {
+		switch {
+		case a.Pl < 0:
+			return fmt.Errorf("negative place index %d in JSON marking", a.Pl)
+		case a.Mult == 0:
+			return fmt.Errorf("null multiplicity for place %d in JSON marking", a.Pl)
+		case k > 0 && a.Pl == atoms[k-1].Pl:
+			return fmt.Errorf("duplicate place %d in JSON marking", a.Pl)
+		case k > 0 && a.Pl < atoms[k-1].Pl:
+			return fmt.Errorf("places not sorted in JSON marking (%d after %d)", a.Pl, atoms[k-1].Pl)
+		}
+		res[k] = Atom(a)
+	}
+	*m = res
+	return nil
+}
+
+// MarkingToJSON returns a JSON representation of marking m using the names of
+// places in the net. The result is an object that maps place names to
+// multiplicities, such as {"p0": 1, "p4": 2}, like with the markings in the
+// JSON representation of a net (see MarshalJSON). We return an error if m
+// refers to a place that is not in the net.
+func (net *Net) MarkingToJSON(m Marking) ([]byte, error) {
+	for _, a := range m {
+		if a.Pl < 0 || a.Pl >= len(net.Pl) {
+			return nil, fmt.Errorf("invalid place index %d in marking", a.Pl)
+		}
+	}
+	res := net.jsonMarking(m)
+	if res == nil {
+		res = map[string]int{}
+	}
+	return json.Marshal(res)
+}
@@ -0,0 +1,108 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestNetJSON(t *testing.T) {
+	for _, file := range []string{"testdata/demo.net", "testdata/abp.net", "testdata/ifip.net"} {
+		net, err := ParseFile(file)
+		if err != nil {
+			t.Fatalf("Error parsing file %s; %s", file, err)
+		}
+		data, err := json.Marshal(net)
+		if err != nil {
+			t.Fatalf("Error marshaling %s; %s", file, err)
+		}
+		net2 := &Net{}
+		if err := json.Unmarshal(data, net2); err != nil {
+			t.Fatalf("Error unmarshaling %s; %s", file, err)
+		}
+		if err := net.compare(net2); err != nil {
+			t.Errorf("JSON round-trip of %s: %s", file, err)
+		}
+	}
+}
+
+func TestNetJSONExtensions(t *testing.T) {
+	net, err := ParseWithOptions(strings.NewReader("tr t0 ]1,3] p0 p1?! -> p2%0.5 p3%0.5\ntr t1 p2?-2 -> p0\nnt server 0 {t1 infinite}\npl p0 (2)\n"), ParseOptions{Probabilities: true})
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	net.Disable(1)
+	net.Weight = []float64{2.5}
+	data, err := json.Marshal(net)
+	if err != nil {
+		t.Fatalf("Error marshaling net; %s", err)
+	}
+	for _, s := range []string{`"kind":"open","value":1`, `"reset":["p1"]`, `"cond":{"p0":1}`, `"initial":2`, `"server":"infinite"`} {
+		if !strings.Contains(string(data), s) {
+			t.Errorf("MarshalJSON: expected %s in output %s", s, data)
+		}
+	}
+	net2 := &Net{}
+	if err := json.Unmarshal(data, net2); err != nil {
+		t.Fatalf("Error unmarshaling net; %s", err)
+	}
+	if err := net.compare(net2); err != nil {
+		t.Errorf("JSON round-trip: %s", err)
+	}
+	if !net2.IsDisabled(1) || net2.Weight[0] != 2.5 || net2.Server(1) != InfiniteServer || len(net2.Notes) != 1 {
+		t.Errorf("JSON round-trip: extensions not preserved")
+	}
+	if err := json.Unmarshal([]byte(`{"places":[{"name":"p0"}],"transitions":[{"name":"t0","time":{"left":{"kind":"closed"},"right":{"kind":"inf"}},"cond":{"p1":1}}]}`), net2); err == nil {
+		t.Errorf("UnmarshalJSON: expected error on unknown place")
+	}
+}
+
+func TestMarkingJSON(t *testing.T) {
+	for _, m := range []Marking{{}, {Atom{0, 1}}, {Atom{1, 3}, Atom{4, -2}, Atom{7, 1}}} {
+		data, err := json.Marshal(m)
+		if err != nil {
+			t.Fatalf("Error marshaling %v; %s", m, err)
+		}
+		var m2 Marking
+		if err := json.Unmarshal(data, &m2); err != nil {
+			t.Fatalf("Error unmarshaling %s; %s", data, err)
+		}
+		if !m2.Equal(m) {
+			t.Errorf("JSON round-trip of %v: actual %v", m, m2)
+		}
+	}
+	data, _ := json.Marshal(Marking{Atom{1, 3}, Atom{4, -2}})
+	if expected := `[{"pl":1,"mult":3},{"pl":4,"mult":-2}]`; string(data) != expected {
+		t.Errorf("MarshalJSON: expected %s, actual %s", expected, data)
+	}
+	for _, s := range []string{
+		`[{"pl":4,"mult":1},{"pl":1,"mult":3}]`,
+		`[{"pl":1,"mult":1},{"pl":1,"mult":3}]`,
+		`[{"pl":1,"mult":0}]`,
+		`[{"pl":-1,"mult":2}]`,
+		`{"p0":1}`,
+	} {
+		var m Marking
+		if err := json.Unmarshal([]byte(s), &m); err == nil {
+			t.Errorf("UnmarshalJSON(%s): expected error, actual %v", s, m)
+		}
+	}
+	net, err := ParseFile("testdata/ifip.net")
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/ifip.net; %s", err)
+	}
+	data, err = net.MarkingToJSON(Marking{Atom{0, 7}, Atom{3, 4}})
+	if err != nil {
+		t.Fatalf("Error in MarkingToJSON; %s", err)
+	}
+	if expected := `{"p1":7,"p4":4}`; string(data) != expected {
+		t.Errorf("MarkingToJSON: expected %s, actual %s", expected, data)
+	}
+	if _, err := net.MarkingToJSON(Marking{Atom{100, 1}}); err == nil {
+		t.Errorf("MarkingToJSON: expected error on invalid place index")
+	}
+}
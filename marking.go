@@ -4,28 +4,32 @@
 
 package nets
 
+import (
+	"math"
+	"slices"
+)
+
 // AddToPlace returns a new Marking obtained from m by adding mult tokens to
-// place pl.
+// place pl. We never modify m, nor its underlying array, so that m can be
+// safely shared. The result may be m itself when mult is 0.
 func (m Marking) AddToPlace(pl int, mult int) Marking {
 	if mult == 0 {
 		return m
 	}
-	if m == nil {
-		return Marking{Atom{pl, mult}}
-	}
 	for i := range m {
 		if m[i].Pl == pl {
-			m[i].Mult += mult
-			if m[i].Mult == 0 {
-				return append(m[:i], m[i+1:]...)
+			if m[i].Mult+mult == 0 {
+				return slices.Concat(m[:i], m[i+1:])
 			}
-			return m
+			res := slices.Clone(m)
+			res[i].Mult += mult
+			return res
 		}
 		if m[i].Pl > pl {
-			return append(m[:i], append(Marking{Atom{pl, mult}}, m[i:]...)...)
+			return slices.Concat(m[:i], Marking{Atom{pl, mult}}, m[i:])
 		}
 	}
-	return append(m, Atom{pl, mult})
+	return slices.Concat(m, Marking{Atom{pl, mult}})
 }
 
 // Add returns the pointwise sum of two markings, m and m2.
@@ -56,10 +60,66 @@ func (m Marking) Add(m2 Marking) Marking {
 	}
 }
 
+// Sub returns the pointwise difference of two markings, m minus m2. The result
+// may contain negative multiplicities, like the markings in net.Delta.
+func (m Marking) Sub(m2 Marking) Marking {
+	res := []Atom{}
+	k1, k2 := 0, 0
+	for {
+		switch {
+		case k1 == len(m):
+			for _, a := range m2[k2:] {
+				res = append(res, Atom{Pl: a.Pl, Mult: -a.Mult})
+			}
+			return res
+		case k2 == len(m2):
+			res = append(res, m[k1:]...)
+			return res
+		case m[k1].Pl == m2[k2].Pl:
+			if mult := m[k1].Mult - m2[k2].Mult; mult != 0 {
+				res = append(res, Atom{Pl: m[k1].Pl, Mult: mult})
+			}
+			k1++
+			k2++
+		case m[k1].Pl < m2[k2].Pl:
+			res = append(res, m[k1])
+			k1++
+		default:
+			res = append(res, Atom{Pl: m2[k2].Pl, Mult: -m2[k2].Mult})
+			k2++
+		}
+	}
+}
+
+// Scale returns a new marking obtained from m by multiplying the multiplicity
+// of every place by k. We return an empty marking when k is 0. Like with Add,
+// we make no attempt to check if multiplicities overflow.
+func (m Marking) Scale(k int) Marking {
+	if k == 0 {
+		return Marking{}
+	}
+	res := make(Marking, len(m))
+	for i, a := range m {
+		res[i] = Atom{Pl: a.Pl, Mult: k * a.Mult}
+	}
+	return res
+}
+
 // IsEnabled checks if transition t in the net is enabled for marking m, meaning
 // m is greater than the precondition for t (in net.Cond) and also less than the
 // inhibition/capacity constraints given in net.Inhib.
+//
+// A transition that has been disabled, using Disable, is never enabled.
+//
+// Marking m may contain ω (see Omega), such as in the markings of a
+// coverability graph. A place with ω tokens always satisfies the condition of
+// t, whatever its weight, but never satisfies an inhibitor arc, since ω is
+// greater than any finite bound. Hence a transition with an inhibitor arc on a
+// place with ω tokens is not enabled.
 func (net *Net) IsEnabled(m Marking, t int) bool {
+	if net.IsDisabled(t) {
+		return false
+	}
 	for _, v := range net.Cond[t] {
 		if m.Get(v.Pl) < v.Mult {
 			return false
@@ -73,6 +133,82 @@ func (net *Net) IsEnabled(m Marking, t int) bool {
 	return true
 }
 
+// Fire returns the marking obtained by firing transition t at marking m,
+// ignoring timing constraints and priorities, together with a boolean that is
+// true if t is enabled at m (see IsEnabled). When t is not enabled, we return
+// m unchanged and false. We take into account the reset arcs of t and we never
+// modify m. The probabilistic arcs of t are ignored, meaning that we only
+// produce the tokens of its other output arcs (see SampleOutcome to choose a
+// branch).
+func (net *Net) Fire(m Marking, t int) (Marking, bool) {
+	if !net.IsEnabled(m, t) {
+		return m, false
+	}
+	return net.fire(m, t), true
+}
+
+// SequenceEnabled checks whether the sequence of transitions seq can be fired,
+// in order, starting from marking m, ignoring timing constraints and
+// priorities. We return true and -1 if every transition in seq is enabled when
+// it is fired. Otherwise we return false and the position, in seq, of the first
+// transition that is not enabled (or that is not a valid transition index).
+//
+// We only allocate the running marking, stored as a vector indexed by places,
+// and never build the intermediate markings. Hence this method is cheaper than
+// firing the transitions one by one when we do not need the final marking.
+func (net *Net) SequenceEnabled(m Marking, seq []int) (bool, int) {
+	cur := make([]int, len(net.Pl))
+	for _, a := range m {
+		cur[a.Pl] = a.Mult
+	}
+	for k, t := range seq {
+		if t < 0 || t >= len(net.Tr) || net.IsDisabled(t) {
+			return false, k
+		}
+		for _, v := range net.Cond[t] {
+			if cur[v.Pl] < v.Mult {
+				return false, k
+			}
+		}
+		for _, v := range net.Inhib[t] {
+			if cur[v.Pl] >= v.Mult {
+				return false, k
+			}
+		}
+		for _, p := range net.resets(t) {
+			// after adding Delta, the marking of p is the number of tokens
+			// produced by t
+			cur[p] = -net.Pre[t].Get(p)
+		}
+		for _, v := range net.Delta[t] {
+			cur[v.Pl] += v.Mult
+		}
+	}
+	return true, -1
+}
+
+// Disable marks transition t as inactive, without deleting it from the net.
+// Disabled transitions are never enabled, but they keep their arcs and can be
+// activated again using Enable.
+func (net *Net) Disable(t int) {
+	for len(net.Disabled) < len(net.Tr) {
+		net.Disabled = append(net.Disabled, false)
+	}
+	net.Disabled[t] = true
+}
+
+// Enable activates transition t again after a call to Disable.
+func (net *Net) Enable(t int) {
+	if t < len(net.Disabled) {
+		net.Disabled[t] = false
+	}
+}
+
+// IsDisabled reports whether transition t has been disabled.
+func (net *Net) IsDisabled(t int) bool {
+	return t < len(net.Disabled) && net.Disabled[t]
+}
+
 // AllEnabled returns the set of transitions (as an ordered slice of transition index) enabled for marking m.
 func (net *Net) AllEnabled(m Marking) []int {
 	enabled := []int{}
@@ -84,6 +220,68 @@ func (net *Net) AllEnabled(m Marking) []int {
 	return enabled
 }
 
+// EnabledWithPriority returns the set of transitions (as an ordered slice of
+// transition index) enabled for marking m that are not blocked by a transition
+// with higher priority, meaning we remove from AllEnabled(m) every transition t
+// such that some transition u, with t in Prio[u], is also enabled at m. We only
+// check the priority relation as it is stored in the net, so callers should use
+// PrioClosure first when the relation is not transitively closed.
+func (net *Net) EnabledWithPriority(m Marking) []int {
+	enabled := net.AllEnabled(m)
+	blocked := NewBitset(len(net.Tr))
+	for _, u := range enabled {
+		if u >= len(net.Prio) {
+			continue
+		}
+		for _, t := range net.Prio[u] {
+			if t != u {
+				blocked.Set(t)
+			}
+		}
+	}
+	res := []int{}
+	for _, t := range enabled {
+		if !blocked.Test(t) {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// UrgentEnabled returns the set of transitions (as an ordered slice of
+// transition index) enabled for marking m that are urgent, meaning their time
+// interval is [0,0]. These transitions must fire before time can elapse and
+// therefore have priority over the other enabled transitions.
+func (net *Net) UrgentEnabled(m Marking) []int {
+	urgent := []int{}
+	for t := range net.Tr {
+		if net.Time[t].IsUrgent() && net.IsEnabled(m, t) {
+			urgent = append(urgent, t)
+		}
+	}
+	return urgent
+}
+
+// Len returns the number of places with a non-null multiplicity in m.
+func (m Marking) Len() int {
+	return len(m)
+}
+
+// At returns the place index and multiplicity of the i-th atom in m, in
+// increasing order of places. It panics if i is out of range.
+func (m Marking) At(i int) (pl, mult int) {
+	return m[i].Pl, m[i].Mult
+}
+
+// ForEach calls fn on every place with a non-null multiplicity in m, in
+// increasing order of places. This should be preferred to ranging over the
+// atoms of m directly.
+func (m Marking) ForEach(fn func(pl, mult int)) {
+	for _, a := range m {
+		fn(a.Pl, a.Mult)
+	}
+}
+
 // Get returns the multiplicity associated with place pl. The returned value is
 // 0 if pl is not in m.
 func (m *Marking) Get(pl int) int {
@@ -105,42 +303,40 @@ func (m *Marking) Get(pl int) int {
 // multiplicity of place pl to mul, but only if mul is greater than the marking
 // of pl in m. This is the least upper bound of m and the marking {pl : mul}
 func (m Marking) updateIfGreater(pl int, mul int) Marking {
-	if m == nil {
-		return Marking{Atom{pl, mul}}
-	}
 	for i := range m {
 		switch {
 		case m[i].Pl == pl:
 			if m[i].Mult < mul {
-				m[i].Mult = mul
+				res := slices.Clone(m)
+				res[i].Mult = mul
+				return res
 			}
 			return m
 		case m[i].Pl > pl:
-			return append(m[:i], append(Marking{Atom{pl, mul}}, m[i:]...)...)
+			return slices.Concat(m[:i], Marking{Atom{pl, mul}}, m[i:])
 		}
 	}
-	return append(m, Atom{pl, mul})
+	return slices.Concat(m, Marking{Atom{pl, mul}})
 }
 
 // updateIfLess returns the marking obtained from m by setting the multiplicity
 // of place pl to mul, but only if mul is less than the marking of pl in m. This
 // is the greatest lower bound of m and the marking {pl : mul}
 func (m Marking) updateIfLess(pl int, mul int) Marking {
-	if m == nil {
-		return Marking{Atom{pl, mul}}
-	}
 	for i := range m {
 		switch {
 		case m[i].Pl == pl:
 			if m[i].Mult > mul {
-				m[i].Mult = mul
+				res := slices.Clone(m)
+				res[i].Mult = mul
+				return res
 			}
 			return m
 		case m[i].Pl > pl:
-			return append(m[:i], append(Marking{Atom{pl, mul}}, m[i:]...)...)
+			return slices.Concat(m[:i], Marking{Atom{pl, mul}}, m[i:])
 		}
 	}
-	return append(m, Atom{pl, mul})
+	return slices.Concat(m, Marking{Atom{pl, mul}})
 }
 
 // Clone returns a copy of Marking  m.
@@ -162,3 +358,69 @@ func (m Marking) Equal(m2 Marking) bool {
 	}
 	return true
 }
+
+// Incomparable is the value returned by Compare when two markings are not
+// comparable for the pointwise order.
+const Incomparable = math.MinInt
+
+// Compare returns an integer comparing two markings for the pointwise order,
+// where a place that does not appear in a marking has multiplicity 0. The
+// result is 0 if m and m2 are equal, -1 if m is less than m2 at every place
+// (and they are not equal), and +1 if m is greater than m2 at every place (and
+// they are not equal). We return Incomparable otherwise.
+func (m Marking) Compare(m2 Marking) int {
+	less, greater := false, false
+	cmp := func(a, b int) {
+		if a < b {
+			less = true
+		} else if a > b {
+			greater = true
+		}
+	}
+	k1, k2 := 0, 0
+	for (k1 < len(m) || k2 < len(m2)) && !(less && greater) {
+		switch {
+		case k1 == len(m):
+			cmp(0, m2[k2].Mult)
+			k2++
+		case k2 == len(m2):
+			cmp(m[k1].Mult, 0)
+			k1++
+		case m[k1].Pl == m2[k2].Pl:
+			cmp(m[k1].Mult, m2[k2].Mult)
+			k1++
+			k2++
+		case m[k1].Pl < m2[k2].Pl:
+			cmp(m[k1].Mult, 0)
+			k1++
+		default:
+			cmp(0, m2[k2].Mult)
+			k2++
+		}
+	}
+	switch {
+	case less && greater:
+		return Incomparable
+	case less:
+		return -1
+	case greater:
+		return +1
+	}
+	return 0
+}
+
+// Covers reports whether m covers m2, meaning that m is greater or equal than
+// m2 for the pointwise order. Markings may contain ω (see Omega), in which case
+// a place with ω tokens in m covers any multiplicity in m2, including ω, while
+// a finite multiplicity in m never covers ω. This is the same as
+// m2.LessEq(m).
+func (m Marking) Covers(m2 Marking) bool {
+	return m2.LessEq(m)
+}
+
+// LessEq reports whether m is less or equal than m2 for the pointwise order,
+// meaning m2 covers m.
+func (m Marking) LessEq(m2 Marking) bool {
+	c := m.Compare(m2)
+	return c == 0 || c == -1
+}
@@ -3,6 +3,8 @@ package nets
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -58,3 +60,356 @@ func TestMtoa(t *testing.T) {
 		}
 	}
 }
+
+func TestMarkingForEach(t *testing.T) {
+	m := Marking{Atom{0, -1}, Atom{5, 4}, Atom{7, 2}}
+	var actual Marking
+	m.ForEach(func(pl, mult int) {
+		actual = append(actual, Atom{pl, mult})
+	})
+	if !actual.Equal(m) {
+		t.Errorf("%v .ForEach: expected %v, actual %v", m, m, actual)
+	}
+	if m.Len() != 3 {
+		t.Errorf("%v .Len(): expected 3, actual %d", m, m.Len())
+	}
+	if pl, mult := m.At(1); pl != 5 || mult != 4 {
+		t.Errorf("%v .At(1): expected (5, 4), actual (%d, %d)", m, pl, mult)
+	}
+}
+
+func TestUrgentEnabled(t *testing.T) {
+	file, err := os.Open("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error opening file testdata/demo.net; %s", err)
+	}
+	defer file.Close()
+	net, err := Parse(file)
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/demo.net; %s", err)
+	}
+	// t2 has interval [0,0] and is enabled when p1 has less than 4000 tokens
+	urgent := net.UrgentEnabled(net.Initial)
+	if len(urgent) != 1 || net.Tr[urgent[0]] != "t2" {
+		t.Errorf("UrgentEnabled: expected [t2], actual %v", urgent)
+	}
+}
+
+func TestDisable(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p0 -> p2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	net.Disable(0)
+	if actual := net.AllEnabled(net.Initial); len(actual) != 1 || actual[0] != 1 {
+		t.Errorf("AllEnabled with t0 disabled: expected [1], actual %v", actual)
+	}
+	if !strings.Contains(net.String(), "# tr t0") {
+		t.Errorf("Disabled transition t0 should be commented out:\n%s", net.String())
+	}
+	net.Enable(0)
+	if actual := net.AllEnabled(net.Initial); len(actual) != 2 {
+		t.Errorf("AllEnabled with t0 enabled: expected [0 1], actual %v", actual)
+	}
+	// transitions added after a call to Disable can be disabled too
+	t2 := net.AddTransition("t2", "", TimeInterval{})
+	net.Disable(t2)
+	if !net.IsDisabled(t2) || net.IsDisabled(0) {
+		t.Errorf("Disable(%d): expected only t2 disabled, actual %v", t2, net.Disabled)
+	}
+}
+
+func TestArenaAdd(t *testing.T) {
+	a := NewArena(4)
+	m1 := Marking{Atom{0, 1}, Atom{2, 3}}
+	m2 := Marking{Atom{1, 2}, Atom{2, -3}}
+	res := []Marking{}
+	for range 5 {
+		res = append(res, a.Add(m1, m2))
+		res = append(res, a.Add(m1, m1))
+	}
+	for k, m := range res {
+		expected := m1.Add(m2)
+		if k%2 == 1 {
+			expected = m1.Add(m1)
+		}
+		if !m.Equal(expected) {
+			t.Errorf("Arena.Add: expected %v, actual %v", expected, m)
+		}
+	}
+}
+
+func TestSequenceEnabled(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p0\ntr t2 p0 p1 -> \n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	tables := []struct {
+		seq []int
+		ok  bool
+		pos int
+	}{
+		{[]int{}, true, -1},
+		{[]int{0, 1, 0, 1}, true, -1},
+		{[]int{0, 0}, false, 1},
+		{[]int{0, 1, 2}, false, 2},
+		{[]int{0, 5}, false, 1},
+	}
+	for _, v := range tables {
+		ok, pos := net.SequenceEnabled(net.Initial, v.seq)
+		if ok != v.ok || pos != v.pos {
+			t.Errorf("SequenceEnabled(%v): expected (%v, %d), actual (%v, %d)", v.seq, v.ok, v.pos, ok, pos)
+		}
+	}
+}
+
+func TestFire(t *testing.T) {
+	net, err := ParseFile("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/demo.net; %s", err)
+	}
+	pl := func(name string) int { return slices.Index(net.Pl, name) }
+	tr := func(name string) int { return slices.Index(net.Tr, name) }
+	tables := []struct {
+		m        Marking
+		t        string
+		expected Marking
+		ok       bool
+	}{
+		{net.Initial, "t3", Marking{}, true},
+		{net.Initial, "t1", net.Initial, false},
+		{Marking{Atom{pl("p0"), 1}}, "t1", Marking{Atom{pl("p1"), 1}}, true},
+		{Marking{Atom{pl("p0"), 2}}, "t0", Marking{Atom{pl("p0"), 2}}, false},
+		{Marking{Atom{pl("p0"), 4}}, "t0", Marking{Atom{pl("p0"), 1}, Atom{pl("p1"), 1}, Atom{pl("p4"), 1}}, true},
+		{Marking{Atom{pl("p1"), 3}}, "t2", Marking{Atom{pl("p1"), 3}}, true},
+		{Marking{Atom{pl("p1"), 4000}}, "t2", Marking{Atom{pl("p1"), 4000}}, false},
+		{Marking{Atom{pl("p4"), 1}}, "t6", Marking{Atom{pl("p4"), 1}}, true},
+	}
+	for _, tt := range tables {
+		m := tt.m.Clone()
+		actual, ok := net.Fire(m, tr(tt.t))
+		if ok != tt.ok || !actual.Equal(tt.expected) {
+			t.Errorf("Fire(%s, %s): expected (%s, %v), actual (%s, %v)", net.Mtoa(tt.m), tt.t, net.Mtoa(tt.expected), tt.ok, net.Mtoa(actual), ok)
+		}
+		if !m.Equal(tt.m) {
+			t.Errorf("Fire(%s, %s): input marking modified to %s", net.Mtoa(tt.m), tt.t, net.Mtoa(m))
+		}
+	}
+}
+
+func TestMarkingSub(t *testing.T) {
+	tables := []struct {
+		m1, m2   Marking
+		expected Marking
+	}{
+		{Marking{}, Marking{}, Marking{}},
+		{Marking{Atom{1, 3}}, Marking{}, Marking{Atom{1, 3}}},
+		{Marking{}, Marking{Atom{1, 3}}, Marking{Atom{1, -3}}},
+		{Marking{Atom{1, 3}, Atom{4, 2}}, Marking{Atom{1, 3}, Atom{4, 2}}, Marking{}},
+		{Marking{Atom{1, 3}, Atom{4, 2}}, Marking{Atom{1, 1}, Atom{4, 5}}, Marking{Atom{1, 2}, Atom{4, -3}}},
+		{Marking{Atom{0, 1}, Atom{5, 4}}, Marking{Atom{2, 2}, Atom{5, 4}, Atom{7, 1}}, Marking{Atom{0, 1}, Atom{2, -2}, Atom{7, -1}}},
+		{Marking{Atom{2, -1}}, Marking{Atom{2, -3}}, Marking{Atom{2, 2}}},
+	}
+	for _, tt := range tables {
+		actual := tt.m1.Sub(tt.m2)
+		if !actual.Equal(tt.expected) {
+			t.Errorf("%v .Sub(%v): expected %v, actual %v", tt.m1, tt.m2, tt.expected, actual)
+		}
+		if sum := actual.Add(tt.m2); !sum.Equal(tt.m1) {
+			t.Errorf("%v .Sub(%v).Add(%v): expected %v, actual %v", tt.m1, tt.m2, tt.m2, tt.m1, sum)
+		}
+	}
+}
+
+func TestMarkingCompare(t *testing.T) {
+	tables := []struct {
+		m1, m2   Marking
+		expected int
+	}{
+		{Marking{}, Marking{}, 0},
+		{Marking{Atom{1, 3}, Atom{4, 2}}, Marking{Atom{1, 3}, Atom{4, 2}}, 0},
+		{Marking{}, Marking{Atom{1, 3}}, -1},
+		{Marking{Atom{1, 3}}, Marking{}, +1},
+		{Marking{Atom{1, 2}}, Marking{Atom{1, 3}, Atom{2, 1}}, -1},
+		{Marking{Atom{1, 3}, Atom{4, 2}}, Marking{Atom{1, 3}}, +1},
+		{Marking{Atom{1, 3}}, Marking{Atom{2, 3}}, Incomparable},
+		{Marking{Atom{1, 3}, Atom{2, 1}}, Marking{Atom{1, 2}, Atom{2, 2}}, Incomparable},
+		{Marking{Atom{1, -1}}, Marking{}, -1},
+		{Marking{}, Marking{Atom{1, -1}, Atom{3, 2}}, Incomparable},
+	}
+	for _, tt := range tables {
+		if actual := tt.m1.Compare(tt.m2); actual != tt.expected {
+			t.Errorf("%v .Compare(%v): expected %d, actual %d", tt.m1, tt.m2, tt.expected, actual)
+		}
+		if actual, expected := tt.m1.LessEq(tt.m2), tt.expected == 0 || tt.expected == -1; actual != expected {
+			t.Errorf("%v .LessEq(%v): expected %v, actual %v", tt.m1, tt.m2, expected, actual)
+		}
+	}
+}
+
+func TestMarkingCovers(t *testing.T) {
+	tables := []struct {
+		m1, m2   Marking
+		expected bool
+	}{
+		{Marking{Atom{1, Omega}}, Marking{Atom{1, 1000}}, true},
+		{Marking{Atom{1, 1000}}, Marking{Atom{1, Omega}}, false},
+		{Marking{Atom{1, Omega}}, Marking{Atom{1, Omega}}, true},
+		{Marking{Atom{1, Omega}}, Marking{Atom{1, Omega}, Atom{2, 1}}, false},
+		{Marking{Atom{1, Omega}, Atom{2, 3}}, Marking{Atom{1, Omega}, Atom{2, 1}}, true},
+		{Marking{Atom{1, Omega}}, Marking{Atom{2, Omega}}, false},
+		{Marking{Atom{1, 3}, Atom{2, Omega}}, Marking{Atom{1, 3}}, true},
+		{Marking{}, Marking{}, true},
+	}
+	for _, tt := range tables {
+		if actual := tt.m1.Covers(tt.m2); actual != tt.expected {
+			t.Errorf("%v .Covers(%v): expected %v, actual %v", tt.m1, tt.m2, tt.expected, actual)
+		}
+	}
+	net, err := ParseString("tr t0 p0*5 -> p1\ntr t1 p0?1000 -> p1\ntr t2 p0?-1000 -> p1\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	m := Marking{Atom{0, Omega}}
+	for tr, expected := range []bool{true, true, false} {
+		if actual := net.IsEnabled(m, tr); actual != expected {
+			t.Errorf("IsEnabled(%s, %s): expected %v, actual %v", net.Mtoa(m), net.Tr[tr], expected, actual)
+		}
+	}
+}
+
+func TestMarkingScale(t *testing.T) {
+	tables := []struct {
+		Marking
+		k        int
+		expected Marking
+	}{
+		{Marking{}, 3, Marking{}},
+		{Marking{Atom{1, 3}, Atom{4, -2}}, 0, Marking{}},
+		{Marking{Atom{1, 3}, Atom{4, -2}}, 1, Marking{Atom{1, 3}, Atom{4, -2}}},
+		{Marking{Atom{1, 3}, Atom{4, -2}}, 2, Marking{Atom{1, 6}, Atom{4, -4}}},
+		{Marking{Atom{1, 3}, Atom{4, -2}}, -1, Marking{Atom{1, -3}, Atom{4, 2}}},
+	}
+	for _, tt := range tables {
+		m := tt.Marking.Clone()
+		actual := tt.Marking.Scale(tt.k)
+		if !actual.Equal(tt.expected) {
+			t.Errorf("%v .Scale(%d): expected %v, actual %v", tt.Marking, tt.k, tt.expected, actual)
+		}
+		if !m.Equal(tt.Marking) {
+			t.Errorf("%v .Scale(%d): receiver modified to %v", m, tt.k, tt.Marking)
+		}
+	}
+}
+
+func TestEnabledWithPriority(t *testing.T) {
+	net, err := ParseFile("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/demo.net; %s", err)
+	}
+	if err := net.PrioClosure(); err != nil {
+		t.Fatalf("Error computing priority closure; %s", err)
+	}
+	pl := func(name string) int { return slices.Index(net.Pl, name) }
+	names := func(ts []int) []string {
+		res := []string{}
+		for _, t := range ts {
+			res = append(res, net.Tr[t])
+		}
+		return res
+	}
+	tables := []struct {
+		m        Marking
+		expected []string
+	}{
+		{Marking{}, []string{"t2", "t4"}},
+		{Marking{Atom{pl("p0"), 3}}, []string{"t1", "t2", "t4"}},
+		{Marking{Atom{pl("p0"), 3}, Atom{pl("p2"), 1}}, []string{"t3", "t4"}},
+		{Marking{Atom{pl("p4"), 1}}, []string{"t4", "t5", "t6"}},
+		{Marking{Atom{pl("p0"), 1}, Atom{pl("p4"), 1}}, []string{"t4", "t5", "t6"}},
+	}
+	for _, tt := range tables {
+		actual := names(net.EnabledWithPriority(tt.m))
+		slices.Sort(actual)
+		if !slices.Equal(actual, tt.expected) {
+			t.Errorf("EnabledWithPriority(%s): expected %v, actual %v", net.Mtoa(tt.m), tt.expected, actual)
+		}
+	}
+}
+
+func TestMarkingCopyOnWrite(t *testing.T) {
+	// a marking with spare capacity, like the ones built with append
+	m := make(Marking, 2, 8)
+	m[0], m[1] = Atom{0, 2}, Atom{3, 1}
+	orig := m.Clone()
+	for _, tt := range []struct{ pl, mult int }{{0, 1}, {0, -2}, {1, 4}, {5, 1}, {3, -1}} {
+		_ = m.AddToPlace(tt.pl, tt.mult)
+		_ = m.updateIfGreater(tt.pl, tt.mult+3)
+		_ = m.updateIfLess(tt.pl, tt.mult)
+		if !m.Equal(orig) || !slices.Equal(m[:cap(m)], append(orig.Clone(), make(Marking, 6)...)) {
+			t.Fatalf("marking %v modified after update on place %d", orig, tt.pl)
+		}
+	}
+	net, err := Parse(strings.NewReader("pl p0 (2)\ntr t0 p0 -> p1\ntr t1 p0 -> p2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	shared := make(Marking, 0, 8)
+	shared = append(shared, net.Initial...)
+	m1, _ := net.Fire(shared, 0)
+	m2, _ := net.Fire(shared, 1)
+	if net.Mtoa(shared) != "p0*2" || net.Mtoa(m1) != "p0 p1" || net.Mtoa(m2) != "p0 p2" {
+		t.Errorf("Fire from shared marking: expected p0*2, p0 p1 and p0 p2, actual %s, %s and %s", net.Mtoa(shared), net.Mtoa(m1), net.Mtoa(m2))
+	}
+}
+
+func TestAtoma(t *testing.T) {
+	net, err := ParseFile("testdata/ifip.net")
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/ifip.net; %s", err)
+	}
+	for _, m := range []Marking{
+		{},
+		{Atom{3, 4}},
+		{Atom{0, -1}, Atom{4, 4}},
+		{Atom{0, 7}, Atom{2, 1}, Atom{3, 4}},
+		net.Initial,
+	} {
+		actual, err := net.Atoma(net.Mtoa(m))
+		if err != nil {
+			t.Errorf("Atoma(%q): unexpected error; %s", net.Mtoa(m), err)
+			continue
+		}
+		if !actual.Equal(m) {
+			t.Errorf("Atoma(%q): expected %v, actual %v", net.Mtoa(m), m, actual)
+		}
+	}
+	tables := []struct {
+		s        string
+		expected Marking
+	}{
+		{"  p4*4   p1*7 p3 ", Marking{Atom{0, 7}, Atom{2, 1}, Atom{3, 4}}},
+		{"p1 p1*2", Marking{Atom{0, 3}}},
+		{"p1*2K", Marking{Atom{0, 2000}}},
+		{"p1*0", Marking{}},
+	}
+	for _, tt := range tables {
+		actual, err := net.Atoma(tt.s)
+		if err != nil || !actual.Equal(tt.expected) {
+			t.Errorf("Atoma(%q): expected %v, actual %v (%v)", tt.s, tt.expected, actual, err)
+		}
+	}
+	for _, s := range []string{"foo", "p1*", "p1*x", "p1 * 2"} {
+		if _, err := net.Atoma(s); err == nil {
+			t.Errorf("Atoma(%q): expected error", s)
+		}
+	}
+	braces, err := Parse(strings.NewReader("pl {a b} (2)\npl {c*d}\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	m := Marking{Atom{0, 2}, Atom{1, 3}}
+	if actual, err := braces.Atoma(braces.Mtoa(m)); err != nil || !actual.Equal(m) {
+		t.Errorf("Atoma(%q): expected %v, actual %v (%v)", braces.Mtoa(m), m, actual, err)
+	}
+}
@@ -4,7 +4,10 @@
 
 package nets
 
-import "fmt"
+import (
+	"fmt"
+	"slices"
+)
 
 // Net is the concrete type of Time Petri Nets. We support labels on both
 // transitions and places. The semantics of nets is as follows. Our choice
@@ -43,6 +46,55 @@ type Net struct {
 	Delta   []Marking      // The delta (Post - Pre) for each transition.
 	Initial Marking        // Initial marking of places.
 	Prio    [][]int        // the slice Prio[i] lists all transitions with less priority than Tr[i] (the slice is sorted).
+	// NodeColor associates a color to the name of a node (place or
+	// transition). It is built from notes of the form `nt color 0 {p1 red}`
+	// and used when exporting the net to Graphviz. The map is nil when the net
+	// has no color notes.
+	NodeColor map[string]string
+	// Disabled[k] is true when transition Tr[k] has been disabled using
+	// method Disable. The slice is nil if no transition was ever disabled.
+	Disabled []bool
+	// NamedMarkings associates a name to markings declared in the same file
+	// than the net, using the mk extension of the format (see ParseOptions).
+	// The map is nil when there are no named markings.
+	NamedMarkings map[string]Marking
+	// Weight[k] is the weight of transition Tr[k] used in the stochastic
+	// interpretation of the net (see SampleTransition). The slice is nil, or
+	// shorter than Tr, when some transitions have the default weight, 1.
+	Weight []float64
+	// ServerKind[k] is the server kind of transition Tr[k] (see
+	// FireMaximalStep). It is built from notes of the form
+	// `nt server 0 {t infinite}`. The slice is nil, or shorter than Tr, when
+	// some transitions have the default kind, SingleServer.
+	ServerKind []ServerKind
+	// ArcProb[k] lists the output arcs of transition Tr[k] that are annotated
+	// with a probability, such as p%0.3 (see SampleOutcome and option
+	// Probabilities of ParseOptions). These arcs are not accounted for in
+	// Delta, which only gives the effect of the other arcs. The slice is nil,
+	// or shorter than Tr, when some transitions have no probabilistic arcs.
+	ArcProb [][]ProbArc
+	// Reset[k] is the sorted list of places reset by transition Tr[k], meaning
+	// places that are emptied when the transition fires, whatever their
+	// marking, before the output tokens are produced. Reset arcs are written
+	// p?! in a .net file. The slice is nil, or shorter than Tr, when some
+	// transitions have no reset arcs.
+	Reset [][]int
+	// Notes lists the notes (nt declarations) found in the .net file, in the
+	// order in which they appear. They are printed back by Fprint.
+	Notes []Note
+	// plIndex and trIndex map the names of places and transitions to their
+	// index. They are built when the net is created (see indexNodes) and are
+	// only read by PlaceIndex and TransitionIndex.
+	plIndex, trIndex map[string]int
+}
+
+// Note is a note declaration in a .net file, such as "nt color 0 {p1 red}".
+// Notes are used by tools of the Tina toolbox, like nd, to store additional
+// information about a net.
+type Note struct {
+	Name string // name of the note, such as color
+	Flag int    // the flag of the note, usually 0 or 1
+	Body string // the annotation, such as {p1 red}
 }
 
 // Marking is the type of Petri net markings. It is a slice of Atoms (places index
@@ -58,8 +110,9 @@ type Marking []Atom
 
 // Atom is a pair of a place index (an index in slice Pl) and a multiplicity (we
 // never store places with a null multiplicity). We assume that markings and arc
-// weights should fit into a 32 bits integer and we make no attempt to check if
-// these values overflow.
+// weights fit into a 32 bits integer. The parser rejects values that do not,
+// but we make no attempt to check for overflows when computing with markings,
+// for instance in Add.
 type Atom struct {
 	Pl   int
 	Mult int
@@ -101,7 +154,9 @@ func (net *Net) PrioClosure() error {
 		for _, t := range work {
 			if setIncluded(net.Prio[t], done) {
 				for _, v := range net.Prio[t] {
-					net.Prio[t] = setUnion(net.Prio[t], net.Prio[v])
+					if len(net.Prio[v]) != 0 {
+						net.Prio[t] = setUnion(net.Prio[t], net.Prio[v])
+					}
 				}
 				donen = setAdd(donen, t)
 			} else {
@@ -122,3 +177,204 @@ func (net *Net) PrioClosure() error {
 		done = donen
 	}
 }
+
+// CheckPriorityReferences returns an error for every transition that appears
+// in a priority relation but has no arcs, no label and a trivial time
+// interval. Since the parser creates transitions on demand, this is often the
+// sign of a typo in a priority declaration (pr), which introduces a phantom
+// transition. The result is empty when no problem is found.
+func (net *Net) CheckPriorityReferences() []error {
+	inprio := make([]bool, len(net.Tr))
+	for k, v := range net.Prio {
+		if len(v) != 0 {
+			inprio[k] = true
+		}
+		for _, t := range v {
+			inprio[t] = true
+		}
+	}
+	errs := []error{}
+	_, isolated := net.IsolatedNodes()
+	for _, t := range isolated {
+		if inprio[t] && net.Tlabel[t] == "" && net.Time[t].Trivial() {
+			errs = append(errs, fmt.Errorf("transition %s only appears in priority declarations", net.Tr[t]))
+		}
+	}
+	return errs
+}
+
+// checkConsistency returns an error if the slices describing places and
+// transitions do not have the same length, or if a marking refers to a place
+// that does not exist.
+func (net *Net) checkConsistency() error {
+	if len(net.Plabel) != len(net.Pl) {
+		return fmt.Errorf("inconsistent number of places")
+	}
+	nt := len(net.Tr)
+	if len(net.Tlabel) != nt || len(net.Time) != nt || len(net.Cond) != nt ||
+		len(net.Inhib) != nt || len(net.Pre) != nt || len(net.Delta) != nt || len(net.Prio) != nt {
+		return fmt.Errorf("inconsistent number of transitions")
+	}
+	check := func(m Marking) error {
+		for _, a := range m {
+			if a.Pl < 0 || a.Pl >= len(net.Pl) {
+				return fmt.Errorf("invalid place index %d in marking", a.Pl)
+			}
+		}
+		return nil
+	}
+	if err := check(net.Initial); err != nil {
+		return err
+	}
+	for t := range net.Tr {
+		for _, m := range []Marking{net.Cond[t], net.Inhib[t], net.Pre[t], net.Delta[t]} {
+			if err := check(m); err != nil {
+				return fmt.Errorf("transition %s: %s", net.Tr[t], err)
+			}
+		}
+		for _, p := range net.resets(t) {
+			if p < 0 || p >= len(net.Pl) {
+				return fmt.Errorf("transition %s: invalid place index %d in reset arcs", net.Tr[t], p)
+			}
+		}
+		for _, t2 := range net.Prio[t] {
+			if t2 < 0 || t2 >= nt {
+				return fmt.Errorf("transition %s: invalid transition index %d in priorities", net.Tr[t], t2)
+			}
+		}
+	}
+	return nil
+}
+
+// Equal reports whether net and o have the same name, the same places and
+// transitions (in the same order), with the same labels, timing constraints,
+// arcs, initial marking, priorities and notes. Priorities are compared as
+// sets, meaning that the order of the transitions in Prio does not matter. We
+// do not compare node colors, that are obtained from notes, or the extensions
+// that are not part of the .net format, such as disabled transitions and
+// weights.
+func (net *Net) Equal(o *Net) bool {
+	return net.compare(o) == nil
+}
+
+// compare returns an error describing the first difference found between net
+// and o, or nil if they are equal.
+func (net *Net) compare(o *Net) error {
+	if net.Name != o.Name {
+		return fmt.Errorf("different net names, %s and %s", net.Name, o.Name)
+	}
+	if !slices.Equal(net.Pl, o.Pl) {
+		return fmt.Errorf("different places")
+	}
+	if !slices.Equal(net.Tr, o.Tr) {
+		return fmt.Errorf("different transitions")
+	}
+	if !slices.Equal(net.Plabel, o.Plabel) {
+		return fmt.Errorf("different place labels")
+	}
+	if !slices.Equal(net.Tlabel, o.Tlabel) {
+		return fmt.Errorf("different transition labels")
+	}
+	if !net.Initial.Equal(o.Initial) {
+		return fmt.Errorf("different initial markings, %s and %s", net.Mtoa(net.Initial), o.Mtoa(o.Initial))
+	}
+	if len(net.Time) != len(o.Time) || len(net.Cond) != len(o.Cond) ||
+		len(net.Inhib) != len(o.Inhib) || len(net.Pre) != len(o.Pre) ||
+		len(net.Delta) != len(o.Delta) || len(net.Prio) != len(o.Prio) {
+		return fmt.Errorf("inconsistent number of transitions")
+	}
+	for k, v := range net.Tr {
+		if net.Time[k] != o.Time[k] {
+			return fmt.Errorf("different time intervals for transition %s", v)
+		}
+		if !net.Cond[k].Equal(o.Cond[k]) || !net.Inhib[k].Equal(o.Inhib[k]) ||
+			!net.Pre[k].Equal(o.Pre[k]) || !net.Delta[k].Equal(o.Delta[k]) {
+			return fmt.Errorf("different arcs for transition %s", v)
+		}
+		if !slices.Equal(net.resets(k), o.resets(k)) {
+			return fmt.Errorf("different reset arcs for transition %s", v)
+		}
+		if !slices.Equal(net.probArcs(k), o.probArcs(k)) {
+			return fmt.Errorf("different probabilistic arcs for transition %s", v)
+		}
+		if !slices.Equal(prioSet(net.Prio[k]), prioSet(o.Prio[k])) {
+			return fmt.Errorf("different priorities for transition %s", v)
+		}
+	}
+	if !slices.Equal(net.Notes, o.Notes) {
+		return fmt.Errorf("different notes")
+	}
+	return nil
+}
+
+// prioSet returns the transitions in v in increasing order and without
+// duplicates, which is the canonical form of a priority declaration.
+func prioSet(v []int) []int {
+	return slices.Compact(slices.Sorted(slices.Values(v)))
+}
+
+// Clone returns a deep copy of net, meaning that every slice and map in the
+// result, including the markings in Cond, Inhib, Pre and Delta, and the
+// priorities in Prio, is a fresh copy. Hence modifying the result never
+// affects net, and conversely.
+func (net *Net) Clone() *Net {
+	res := &Net{
+		Name:       net.Name,
+		Pl:         slices.Clone(net.Pl),
+		Tr:         slices.Clone(net.Tr),
+		Tlabel:     slices.Clone(net.Tlabel),
+		Plabel:     slices.Clone(net.Plabel),
+		Time:       slices.Clone(net.Time),
+		Cond:       cloneMarkings(net.Cond),
+		Inhib:      cloneMarkings(net.Inhib),
+		Pre:        cloneMarkings(net.Pre),
+		Delta:      cloneMarkings(net.Delta),
+		Initial:    slices.Clone(net.Initial),
+		Prio:       make([][]int, len(net.Prio)),
+		Disabled:   slices.Clone(net.Disabled),
+		Weight:     slices.Clone(net.Weight),
+		ServerKind: slices.Clone(net.ServerKind),
+		Notes:      slices.Clone(net.Notes),
+	}
+	for k, v := range net.Prio {
+		res.Prio[k] = slices.Clone(v)
+	}
+	if net.Reset != nil {
+		res.Reset = make([][]int, len(net.Reset))
+		for k, v := range net.Reset {
+			res.Reset[k] = slices.Clone(v)
+		}
+	}
+	if net.ArcProb != nil {
+		res.ArcProb = make([][]ProbArc, len(net.ArcProb))
+		for k, v := range net.ArcProb {
+			res.ArcProb[k] = slices.Clone(v)
+		}
+	}
+	if net.NodeColor != nil {
+		res.NodeColor = make(map[string]string, len(net.NodeColor))
+		for k, v := range net.NodeColor {
+			res.NodeColor[k] = v
+		}
+	}
+	if net.NamedMarkings != nil {
+		res.NamedMarkings = make(map[string]Marking, len(net.NamedMarkings))
+		for k, v := range net.NamedMarkings {
+			res.NamedMarkings[k] = slices.Clone(v)
+		}
+	}
+	res.indexNodes()
+	return res
+}
+
+// cloneMarkings returns a deep copy of a slice of markings.
+func cloneMarkings(s []Marking) []Marking {
+	if s == nil {
+		return nil
+	}
+	res := make([]Marking, len(s))
+	for k, m := range s {
+		res[k] = slices.Clone(m)
+	}
+	return res
+}
@@ -0,0 +1,33 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import "testing"
+
+func TestClone(t *testing.T) {
+	net, err := ParseFile("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/demo.net; %s", err)
+	}
+	orig, err := ParseFile("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/demo.net; %s", err)
+	}
+	c := net.Clone()
+	if err := net.compare(c); err != nil {
+		t.Fatalf("Clone: result different from the original net; %s", err)
+	}
+	c.Initial[0].Mult = 42
+	c.Initial = c.Initial.AddToPlace(1, 3)
+	c.Delta[0][0].Mult = 7
+	c.Cond[1][0].Mult = 7
+	c.Prio[2][0] = 5
+	c.Pl[0] = "foo"
+	c.Tlabel[0] = "bar"
+	c.Time[0].Left.Value = 8
+	if err := orig.compare(net); err != nil {
+		t.Errorf("Clone: original net modified after changing its clone; %s", err)
+	}
+}
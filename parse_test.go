@@ -5,7 +5,12 @@
 package nets
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -36,3 +41,822 @@ func TestParse(t *testing.T) {
 		}
 	}
 }
+
+func TestParseUnicode(t *testing.T) {
+	net, err := ParseString("pl {pé} : {état initial} (1)\ntr t0 : ∅ {pé}*2 -> {ü}\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if !slices.Equal(net.Pl, []string{"{pé}", "{ü}"}) || net.Plabel[0] != "{état initial}" || net.Tlabel[0] != "∅" {
+		t.Errorf("Wrong names or labels, actual %q %q %q", net.Pl, net.Plabel, net.Tlabel)
+	}
+	if s := net.Mtoa(net.Delta[0]); s != "{pé}*-2 {ü}" {
+		t.Errorf("Wrong delta for t0, actual %s", s)
+	}
+}
+
+func TestParseColor(t *testing.T) {
+	net, err := Parse(strings.NewReader("tr t0 p0 -> p1\nnt color 0 {p1 red}\nnt color 0 {t0 blue}\nnt color 1 {bad}\nnt n1 1 {p0 green}\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	expected := map[string]string{"p1": "red", "t0": "blue"}
+	if len(net.NodeColor) != len(expected) {
+		t.Errorf("Wrong number of colors, expected %v, actual %v", expected, net.NodeColor)
+	}
+	for k, v := range expected {
+		if net.NodeColor[k] != v {
+			t.Errorf("Wrong color for %s, expected %s, actual %s", k, v, net.NodeColor[k])
+		}
+	}
+	var buf strings.Builder
+	if err := net.Dot(&buf); err != nil {
+		t.Fatalf("Error in Dot; %s", err)
+	}
+	if !strings.Contains(buf.String(), `pl_1 [shape=circle, label="p1", style=filled, fillcolor="red"]`) {
+		t.Errorf("Missing color in Dot output:\n%s", buf.String())
+	}
+}
+
+func TestSelfCheck(t *testing.T) {
+	for _, v := range []string{"abp.net", "demo.net", "ifip.net", "sokoban_3.net"} {
+		file, err := os.Open("testdata/" + v)
+		if err != nil {
+			t.Fatalf("Error opening file %s; %s", v, err)
+		}
+		net, err := Parse(file)
+		file.Close()
+		if err != nil {
+			t.Fatalf("Error parsing file %s; %s", v, err)
+		}
+		if err := net.SelfCheck(); err != nil {
+			t.Errorf("Error in file %s; %s", v, err)
+		}
+	}
+	// a read arc with a weight greater than the weight of the input arc
+	net, err := Parse(strings.NewReader("tr t0 p0*2 p0?3 -> p1\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if err := net.SelfCheck(); err != nil {
+		t.Errorf("Error with read arcs; %s", err)
+	}
+}
+
+func TestParseCollect(t *testing.T) {
+	input := `foo
+tr t0 p0 -> p1
+tr t1 [3,2] p1 -> p2
+tr t2 p2?x -> p0
+pl p2 (1)
+`
+	net, errs := ParseCollect(strings.NewReader(input))
+	if len(errs) != 3 {
+		t.Errorf("ParseCollect: expected 3 errors, actual %d: %v", len(errs), errs)
+	}
+	if len(net.Tr) != 3 {
+		t.Errorf("ParseCollect: expected 3 transitions, actual %v", net.Tr)
+	}
+	_, errs = ParseCollect(strings.NewReader(input[4:19]))
+	if len(errs) != 0 {
+		t.Errorf("ParseCollect: expected no errors, actual %v", errs)
+	}
+}
+
+func TestParseEmptyLabel(t *testing.T) {
+	net, err := Parse(strings.NewReader("tr t0 : {} p0 -> p1\npl p0 : {} (1)\ntr t1 : {a} p1 -> p0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if net.Tlabel[0] != "" || net.Plabel[0] != "" {
+		t.Errorf("Explicit empty labels should be stored as empty strings, actual %q and %q", net.Tlabel[0], net.Plabel[0])
+	}
+	if net.Tlabel[1] != "{a}" {
+		t.Errorf("Wrong label for t1, expected {a}, actual %q", net.Tlabel[1])
+	}
+	if strings.Contains(net.String(), "{}") {
+		t.Errorf("Empty labels should not be printed:\n%s", net.String())
+	}
+}
+
+func TestParseNamedMarkings(t *testing.T) {
+	input := "mk goal p1*2 p3\npl p1 (1)\nmk bad p2 mk empty\ntr t0 p1 -> p2\n"
+	if _, err := Parse(strings.NewReader(input)); err == nil {
+		t.Errorf("Named markings should be rejected without option Markings")
+	}
+	net, err := ParseWithOptions(strings.NewReader(input), ParseOptions{Markings: true})
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	expected := map[string]string{"goal": "p1*2 p3", "bad": "p2", "empty": ""}
+	if len(net.NamedMarkings) != len(expected) {
+		t.Errorf("Wrong number of named markings, actual %v", net.NamedMarkings)
+	}
+	for k, v := range expected {
+		if actual := net.Mtoa(net.NamedMarkings[k]); actual != v {
+			t.Errorf("Wrong marking %s, expected %q, actual %q", k, v, actual)
+		}
+	}
+}
+
+func TestSimplifyNames(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl {p1} (1)\npl {a b}\npl {tr}\npl p2\npl {p2}\ntr {t1} {p1} -> {a b} p2 {p2}\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	net.SimplifyNames()
+	expected := []string{"p1", "{a b}", "{tr}", "p2", "{p2}"}
+	if !slices.Equal(net.Pl, expected) {
+		t.Errorf("SimplifyNames: expected places %v, actual %v", expected, net.Pl)
+	}
+	if net.Tr[0] != "t1" {
+		t.Errorf("SimplifyNames: expected transition t1, actual %s", net.Tr[0])
+	}
+	if err := net.SelfCheck(); err != nil {
+		t.Errorf("SimplifyNames: %s", err)
+	}
+}
+
+func TestWriteScenario(t *testing.T) {
+	net, err := ParseFile("testdata/abp.net")
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	seq := net.Simulate(20, 1)
+	var b strings.Builder
+	if err := net.WriteScenario(&b, seq); err != nil {
+		t.Fatalf("WriteScenario: %s", err)
+	}
+	if !strings.HasSuffix(b.String(), "\n") || strings.Count(b.String(), "\n") != 1 {
+		t.Errorf("WriteScenario: expected a single line, actual %q", b.String())
+	}
+	// we read back the scenario and check that we get the same sequence
+	actual := []int{}
+	for _, v := range strings.Fields(b.String()) {
+		k, ok := net.TransitionIndex(v)
+		if !ok {
+			t.Fatalf("WriteScenario: unknown transition %s in %q", v, b.String())
+		}
+		actual = append(actual, k)
+	}
+	if !slices.Equal(actual, seq) {
+		t.Errorf("WriteScenario: expected %v, actual %v", seq, actual)
+	}
+	net, err = ParseString("tr t0 p0 -> p1\ntr {t 1} p1 -> p0\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	b.Reset()
+	if err := net.WriteScenario(&b, []int{0, 1, 0}); err != nil || b.String() != "t0 {t 1} t0\n" {
+		t.Errorf("WriteScenario: expected \"t0 {t 1} t0\", actual %q (%v)", b.String(), err)
+	}
+	b.Reset()
+	if err := net.WriteScenario(&b, []int{0, 2}); err == nil || b.Len() != 0 {
+		t.Errorf("WriteScenario: expected error with invalid transition index")
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	tables := []string{
+		"#!/usr/bin/env tina\nnet demo\npl p0 (1)\ntr t0 p0 -> p1\n",
+		"\uFEFF#!/usr/bin/env tina\nnet demo\npl p0 (1)\ntr t0 p0 -> p1\n",
+		"\uFEFF# generated by tool\r\nnet demo\npl p0 (1)\ntr t0 p0 -> p1\n",
+		"\uFEFFnet demo\npl p0 (1)\ntr t0 p0 -> p1\n#!",
+	}
+	for _, v := range tables {
+		net, err := Parse(strings.NewReader(v))
+		if err != nil {
+			t.Errorf("Error parsing net %q; %s", v, err)
+			continue
+		}
+		if net.Name != "demo" || len(net.Pl) != 2 || len(net.Tr) != 1 {
+			t.Errorf("Wrong net when parsing %q:\n%s", v, net)
+		}
+	}
+}
+
+func TestParsePlusSign(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (+2)\ntr t0 p0*+2 p1?+1 -> p2*+3\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	expected, err := Parse(strings.NewReader("pl p0 (2)\ntr t0 p0*2 p1?1 -> p2*3\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if err := net.compare(expected); err != nil {
+		t.Errorf("Weights with explicit + sign: %s", err)
+	}
+	for _, v := range []string{"tr t0 p0*-2 -> p1\n", "tr t0 p0*+ -> p1\n", "tr t0 p0?+-1 -> p1\n", "pl p0 (-1)\n", "pl p0 (++1)\n"} {
+		if _, err := Parse(strings.NewReader(v)); err == nil {
+			t.Errorf("Parse(%q): expected error", v)
+		}
+	}
+}
+
+func TestParseNotes(t *testing.T) {
+	net, err := Parse(strings.NewReader("tr t0 p0 -> p1\nnt color 0 {p1 red}\nnt n1 1 {Sender\\\\nprocess}\nnt color 0 {t0 blue}\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	expected := []Note{{"color", 0, "{p1 red}"}, {"n1", 1, "{Sender\\\\nprocess}"}, {"color", 0, "{t0 blue}"}}
+	if !slices.Equal(net.Notes, expected) {
+		t.Errorf("Wrong notes, expected %v, actual %v", expected, net.Notes)
+	}
+	file, err := os.Open("testdata/abp.net")
+	if err != nil {
+		t.Fatalf("Error opening file abp.net; %s", err)
+	}
+	defer file.Close()
+	net, err = Parse(file)
+	if err != nil {
+		t.Fatalf("Error parsing file abp.net; %s", err)
+	}
+	actual, err := Parse(strings.NewReader(net.String()))
+	if err != nil {
+		t.Fatalf("Error parsing the output of Fprint; %s", err)
+	}
+	if len(net.Notes) != 21 || !slices.Equal(actual.Notes, net.Notes) {
+		t.Errorf("Notes are not preserved by Fprint, expected %v, actual %v", net.Notes, actual.Notes)
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	tables := []struct {
+		file   string
+		pl, tr int
+	}{
+		{"abp.net", 12, 16},
+		{"demo.net", 4, 7},
+		{"ifip.net", 5, 5},
+	}
+	for _, v := range tables {
+		net, err := ParseFile("testdata/" + v.file)
+		if err != nil {
+			t.Errorf("Error parsing file %s; %s", v.file, err)
+			continue
+		}
+		if len(net.Pl) != v.pl || len(net.Tr) != v.tr {
+			t.Errorf("Wrong net in %s, expected %d/%d, actual %d/%d", v.file, v.pl, v.tr, len(net.Pl), len(net.Tr))
+		}
+	}
+	if _, err := ParseFile("testdata/missing.net"); err == nil {
+		t.Errorf("ParseFile: expected error with missing file")
+	}
+	path := t.TempDir() + "/bad.net"
+	if err := os.WriteFile(path, []byte("tr t0 [3,1] p0 -> p1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseFile(path); err == nil || !strings.HasPrefix(err.Error(), "error parsing "+path+":") {
+		t.Errorf("ParseFile: expected error mentioning %s, actual %v", path, err)
+	}
+}
+
+func TestParseString(t *testing.T) {
+	// the example from the package documentation
+	input := `
+     tr t1 p1 p2*2 -> p3 p4 p5
+     tr t2 [0,2] p4 -> p2
+     tr t3 : a p5 -> p2
+     tr t3 p3 -> p3
+     tr t4 [0,3] p3 -> p1
+     pl p1 (1)
+     pl p2 (2)
+`
+	net, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if len(net.Pl) != 5 || len(net.Tr) != 4 {
+		t.Errorf("Wrong net, expected 5 places and 4 transitions, actual %d and %d", len(net.Pl), len(net.Tr))
+	}
+	bad := "tr t0 p0 -> p1\ntr t1 [2,1] p1 -> p0\n"
+	_, err1 := ParseString(bad)
+	_, err2 := Parse(strings.NewReader(bad))
+	if err1 == nil || err2 == nil || err1.Error() != err2.Error() {
+		t.Errorf("ParseString and Parse should return the same error, actual %v and %v", err1, err2)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tables := []struct {
+		input     string
+		line, col int
+	}{
+		{"tr t0 p0 -> p1\ntr t1 [3,1] p1 -> p0\n", 2, 7},
+		{"pl p0 (1)\npl p1 : a : b\n", 2, 11},
+		{"net demo\n\n  foo\n", 3, 3},
+		{"tr {é} p0 -> p1\ntr {ü} : {ß} [3,1] p1 -> p0\n", 2, 14},
+		{"pl {p→q} (1)\npl {∀} (2) @\n", 2, 12},
+	}
+	for _, v := range tables {
+		_, err := ParseString(v.input)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("Parse(%q): expected a ParseError, actual %v", v.input, err)
+			continue
+		}
+		if perr.Line != v.line || perr.Col != v.col {
+			t.Errorf("Parse(%q): expected error at %d:%d, actual %d:%d (%s)", v.input, v.line, v.col, perr.Line, perr.Col, perr)
+		}
+		if !strings.HasPrefix(err.Error(), "error parsing net: "+perr.Msg) {
+			t.Errorf("Parse(%q): wrong error message %q", v.input, err)
+		}
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	input := `net demo
+tr t0 [4,2] p0 -> p1
+pl p0 (1)
+tr t1 p1 -> p2
+tr t4 p2?x -> p1
+tr t2 p2 -> p0
+pl p1 : a : b
+tr t3 p0 -> p3
+`
+	net, errs := ParseAll(strings.NewReader(input))
+	expected := []int{2, 5, 7}
+	if len(errs) != len(expected) {
+		t.Fatalf("ParseAll: expected %d errors, actual %d: %v", len(expected), len(errs), errs)
+	}
+	for k, err := range errs {
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("ParseAll: expected a ParseError, actual %v", err)
+			continue
+		}
+		if perr.Line != expected[k] {
+			t.Errorf("ParseAll: expected error at line %d, actual %d (%s)", expected[k], perr.Line, perr)
+		}
+	}
+	if net == nil {
+		t.Fatal("ParseAll: expected a partial net, actual nil")
+	}
+	for _, tr := range []string{"t1", "t2", "t3"} {
+		if !slices.Contains(net.Tr, tr) {
+			t.Errorf("ParseAll: expected transition %s in partial net, actual %v", tr, net.Tr)
+		}
+	}
+	if net.Initial.Get(slices.Index(net.Pl, "p0")) != 1 {
+		t.Errorf("ParseAll: wrong initial marking in partial net, actual %s", net.Mtoa(net.Initial))
+	}
+	// errors at the start of the input
+	input = `foo
+tr t0 p0 -> p1
+tr t1 [3,2] p1 -> p2
+tr t2 p2?x -> p0
+pl p2 (1)
+`
+	net, errs = ParseAll(strings.NewReader(input))
+	if len(errs) != 3 {
+		t.Errorf("ParseAll: expected 3 errors, actual %d: %v", len(errs), errs)
+	}
+	if len(net.Tr) != 3 {
+		t.Errorf("ParseAll: expected 3 transitions, actual %v", net.Tr)
+	}
+	_, errs = ParseAll(strings.NewReader(input[4:19]))
+	if len(errs) != 0 {
+		t.Errorf("ParseAll: expected no errors, actual %v", errs)
+	}
+}
+
+func TestMconvert(t *testing.T) {
+	tables := []struct {
+		s        string
+		expected int
+		ok       bool
+	}{
+		{"12", 12, true},
+		{"3K", 3000, true},
+		{"2M", 2000000, true},
+		{"2G", 2000000000, true},
+		{"2147483647", 2147483647, true},
+		{"-4K", -4000, true},
+		{"0E", 0, true},
+		{"2147483648", 0, false},
+		{"3G", 0, false},
+		{"2E", 0, false},
+		{"9E", 0, false},
+		{"9223372036854775807", 0, false},
+		{"9223372036854775808", 0, false},
+		{"3X", 0, false},
+		{"K", 0, false},
+	}
+	for _, tt := range tables {
+		actual, err := mconvert(tt.s)
+		if (err == nil) != tt.ok || (tt.ok && actual != tt.expected) {
+			t.Errorf("mconvert(%q): expected (%d, %v), actual (%d, %v)", tt.s, tt.expected, tt.ok, actual, err)
+		}
+	}
+	_, err := ParseString("pl p0 (1)\npl p1 (9E)\n")
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 2 || !strings.Contains(err.Error(), "9E overflows") {
+		t.Errorf("Parse: expected overflow error at line 2, actual %v", err)
+	}
+}
+
+func TestFprintSorted(t *testing.T) {
+	opts := ParseOptions{Probabilities: true}
+	input1 := "pl a (1)\npl b (2)\npl c\ntr t1 [1,3] a b?2 c?-1 -> c\ntr t0 b c?! -> a%0.5 c*2%0.5\ntr t2 : lbl a ->\npr t2 > t1 t0\n"
+	input2 := "pl c\npl b (2)\npl a (1)\ntr t2 : lbl a ->\ntr t0 c?! b -> c*2%0.5 a%0.5\ntr t1 [1,3] c?-1 b?2 a -> c\npr t2 > t0 t1\n"
+	net1, err := ParseWithOptions(strings.NewReader(input1), opts)
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	net2, err := ParseWithOptions(strings.NewReader(input2), opts)
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if net1.String() == net2.String() {
+		t.Fatalf("Fprint should follow the order of declarations")
+	}
+	before := net2.String()
+	var buf1, buf2 strings.Builder
+	net1.FprintSorted(&buf1)
+	net2.FprintSorted(&buf2)
+	if buf1.String() != buf2.String() {
+		t.Errorf("FprintSorted: expected same output, actual\n%s\nand\n%s", buf1.String(), buf2.String())
+	}
+	expected := []string{"pl a (1)\npl b (2)\npl c\ntr t0", "tr t0  b c?! -> a%0.5 c*2%0.5\n", "tr t1 [1,3] a b?2 c?-1 -> c\n", "pr t2 > t0 t1\n"}
+	for _, v := range expected {
+		if !strings.Contains(buf2.String(), v) {
+			t.Errorf("FprintSorted: expected %q in\n%s", v, buf2.String())
+		}
+	}
+	if net2.String() != before {
+		t.Errorf("FprintSorted should not modify the net")
+	}
+	net3, err := ParseWithOptions(strings.NewReader(buf2.String()), opts)
+	if err != nil {
+		t.Fatalf("Error parsing output of FprintSorted; %s", err)
+	}
+	if err := net3.SelfCheck(); err != nil {
+		t.Errorf("Error parsing output of FprintSorted; %s", err)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	opts := ParseOptions{Probabilities: true}
+	inputs := []string{
+		"net n0\npl p0 : {a b} (2)\npl p1\ntr t0 : lbl ]1,4] p0*2 p1?3 p2?-1 -> p1%0.5 p2%0.5\ntr t1 [0,w[ p1 p0?! -> p0\ntr t2 -> p2\npr t2 < t0 t1\nnt color 0 {p1 red}\nnt server 0 {t2 infinite}\nnt n1 1 {hello}\n",
+		"tr t0 p0 -> p1\ntr t1 p1 -> p0\npr t0 t1 > t2\n",
+	}
+	for _, v := range []string{"abp.net", "demo.net", "ifip.net", "sokoban_3.net"} {
+		b, err := os.ReadFile("testdata/" + v)
+		if err != nil {
+			t.Fatalf("Error opening file %s; %s", v, err)
+		}
+		inputs = append(inputs, string(b))
+	}
+	for _, v := range inputs {
+		net, err := ParseWithOptions(strings.NewReader(v), opts)
+		if err != nil {
+			t.Fatalf("Error parsing net; %s", err)
+		}
+		net2, err := ParseWithOptions(strings.NewReader(net.String()), opts)
+		if err != nil {
+			t.Fatalf("Error parsing output of Fprint; %s\n%s", err, net.String())
+		}
+		if err := net.compare(net2); err != nil {
+			t.Errorf("Round trip failed; %s\n%s", err, net.String())
+		}
+	}
+	// disabled transitions are lost in the round trip
+	net, err := ParseString("pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p0\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	net.Disable(1)
+	if err := net.SelfCheck(); err == nil {
+		t.Errorf("SelfCheck should fail on a net with disabled transitions")
+	}
+	// priorities are printed in canonical form
+	net = NewNet("")
+	for _, v := range []string{"t0", "t1", "t2"} {
+		net.AddTransition(v, "", TimeInterval{})
+	}
+	net.Prio[0] = []int{2, 1, 2}
+	if !strings.Contains(net.String(), "pr t0 > t1 t2\n") {
+		t.Errorf("Priorities should be printed in canonical form:\n%s", net.String())
+	}
+	net2, err := Parse(strings.NewReader(net.String()))
+	if err != nil {
+		t.Fatalf("Error parsing output of Fprint; %s", err)
+	}
+	if !net.Equal(net2) {
+		t.Errorf("Round trip failed for priorities:\n%s", net.String())
+	}
+	// notes are taken into account
+	net2.Notes = append(net2.Notes, Note{"n1", 0, "{x}"})
+	if net.Equal(net2) {
+		t.Errorf("Nets with different notes should not be equal")
+	}
+}
+
+func TestSetAddUnion(t *testing.T) {
+	s := []int{}
+	for _, v := range []int{5, 1, 3, 5, 0, 7, 1} {
+		s = setAdd(s, v)
+	}
+	if !slices.Equal(s, []int{0, 1, 3, 5, 7}) {
+		t.Errorf("setAdd: expected [0 1 3 5 7], actual %v", s)
+	}
+	s2 := setAdd(s, 4)
+	if !slices.Equal(s, []int{0, 1, 3, 5, 7}) || !slices.Equal(s2, []int{0, 1, 3, 4, 5, 7}) {
+		t.Errorf("setAdd should not modify its argument, actual %v and %v", s, s2)
+	}
+	tables := []struct {
+		s1, s2, expected []int
+	}{
+		{nil, nil, []int{}},
+		{[]int{1, 2}, nil, []int{1, 2}},
+		{nil, []int{3}, []int{3}},
+		{[]int{0, 2, 4, 6}, []int{1, 2, 3, 8, 9}, []int{0, 1, 2, 3, 4, 6, 8, 9}},
+	}
+	for _, tt := range tables {
+		if actual := setUnion(tt.s1, tt.s2); !slices.Equal(actual, tt.expected) {
+			t.Errorf("setUnion(%v, %v): expected %v, actual %v", tt.s1, tt.s2, tt.expected, actual)
+		}
+	}
+}
+
+// BenchmarkParsePriorities parses a net with 3000 transitions, where half of
+// them have priority over the other half, and computes the closure of the
+// priority relation.
+func BenchmarkParsePriorities(b *testing.B) {
+	const n = 3000
+	var buf strings.Builder
+	for k := range n {
+		fmt.Fprintf(&buf, "tr t%d p%d -> p%d\n", k, k, (k+1)%n)
+	}
+	buf.WriteString("pr")
+	for k := n - 1; k >= 0; k-- {
+		if k == n/2-1 {
+			buf.WriteString(" >")
+		}
+		fmt.Fprintf(&buf, " t%d", k)
+	}
+	buf.WriteString("\n")
+	input := buf.String()
+	b.ResetTimer()
+	for range b.N {
+		net, err := Parse(strings.NewReader(input))
+		if err != nil {
+			b.Fatalf("Error parsing net; %s", err)
+		}
+		if err := net.PrioClosure(); err != nil {
+			b.Fatalf("Error in PrioClosure; %s", err)
+		}
+	}
+}
+
+// BenchmarkParseLarge parses a generated net with 20000 transitions, which is
+// mostly made of identifiers and numbers.
+func BenchmarkParseLarge(b *testing.B) {
+	const n = 20000
+	var buf strings.Builder
+	buf.WriteString("net large\n")
+	for k := range n {
+		fmt.Fprintf(&buf, "pl place_%d (%d)\n", k, k%3)
+	}
+	for k := range n {
+		fmt.Fprintf(&buf, "tr transition_%d : label_%d [%d,w[ place_%d*2 place_%d?1 -> place_%d place_%d*3\n", k, k, k%5, k, (k+7)%n, (k+1)%n, (k+2)%n)
+	}
+	input := buf.String()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for range b.N {
+		if _, err := Parse(strings.NewReader(input)); err != nil {
+			b.Fatalf("Error parsing net; %s", err)
+		}
+	}
+}
+
+func TestParseWithHint(t *testing.T) {
+	b, err := os.ReadFile("testdata/sokoban_3.net")
+	if err != nil {
+		t.Fatalf("Error opening file; %s", err)
+	}
+	net, err := Parse(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	for _, hint := range [][2]int{{0, 0}, {-1, 10}, {10, 10}, {57, 452}, {1000, 1000}} {
+		net2, err := ParseWithHint(bytes.NewReader(b), hint[0], hint[1])
+		if err != nil {
+			t.Fatalf("Error in ParseWithHint(%v); %s", hint, err)
+		}
+		if err := net.compare(net2); err != nil {
+			t.Errorf("ParseWithHint(%v) differs from Parse; %s", hint, err)
+		}
+	}
+	if _, err := ParseWithHint(strings.NewReader("tr t0 [3,1] ->\n"), 1, 1); err == nil {
+		t.Errorf("ParseWithHint should return errors")
+	}
+}
+
+func BenchmarkParseSokoban(b *testing.B) {
+	input, err := os.ReadFile("testdata/sokoban_3.net")
+	if err != nil {
+		b.Fatalf("Error opening file; %s", err)
+	}
+	b.Run("Parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := Parse(bytes.NewReader(input)); err != nil {
+				b.Fatalf("Error parsing file; %s", err)
+			}
+		}
+	})
+	b.Run("ParseWithHint", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			if _, err := ParseWithHint(bytes.NewReader(input), 57, 452); err != nil {
+				b.Fatalf("Error parsing file; %s", err)
+			}
+		}
+	})
+}
+
+func TestParseTruncated(t *testing.T) {
+	tables := []struct {
+		input, msg string
+	}{
+		{"pl {p0", `found "{p0"`},
+		{"tr t0 : {a", `found "{a"`},
+		{"tr t0 : {a\\", `found "{a\\"`},
+		{"tr t0 : {a\\b}", `found "{a\\"`},
+		{"tr t0 p0 -", `found "-"`},
+		{"tr t0 p0?", `found "EOF"`},
+		{"tr t0 p0*", `found "EOF"`},
+		{"tr t0 p0?-", "empty value"},
+		{"tr t0 [1,", `found "EOF"`},
+		{"pl p0 (", `found "EOF"`},
+		{"pl p0 (3", `found "EOF"`},
+		{"pl p0\x00 pl p1", `found "\x00"`},
+	}
+	for _, v := range tables {
+		net, err := ParseString(v.input)
+		if err == nil || net != nil {
+			t.Errorf("Parse(%q): expected an error", v.input)
+			continue
+		}
+		if !strings.Contains(err.Error(), v.msg) {
+			t.Errorf("Parse(%q): expected %s in error, actual %q", v.input, v.msg, err)
+		}
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	for _, file := range []string{"abp.net", "demo.net", "ifip.net"} {
+		data, err := os.ReadFile("testdata/" + file)
+		if err != nil {
+			f.Fatalf("Error opening file %s; %s", file, err)
+		}
+		f.Add(string(data))
+	}
+	for _, s := range []string{
+		"tr t0 p0 -> p1\n",
+		"pl {p0",
+		"tr t0 : {a",
+		"tr t0 : {a\\",
+		"tr t0 p0 -",
+		"tr t0 p0?",
+		"tr t0 p0?-",
+		"tr t0 [1,",
+		"tr t0 ]1,w",
+		"pl p0 (",
+		"pl p0 (3",
+		"pr t0 >",
+	} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		net, err := ParseString(s)
+		if err != nil {
+			if net != nil {
+				t.Errorf("Parse(%q) returned a net and an error", s)
+			}
+			return
+		}
+		if net == nil {
+			t.Fatalf("Parse(%q) returned a nil net without an error", s)
+		}
+		_ = net.String()
+	})
+}
+
+func TestParseStrict(t *testing.T) {
+	tables := []struct {
+		input     string
+		line, col int
+	}{
+		{"pl p0 (1)\ntr t0 p0 -> p1\npl p0 (2)\n", 3, 7},
+		{"pl p0 : a\npl p0 : b\n", 2, 7},
+		{"tr t0 [0,2] p0 -> p1\ntr t0 [0,3] p1 -> p0\n", 2, 7},
+		{"tr t0 : a p0 -> p1\ntr t1 p1 -> p0\ntr t0 : {b} p1 -> p0\n", 3, 7},
+	}
+	for _, v := range tables {
+		if _, err := ParseString(v.input); err != nil {
+			t.Errorf("Parse(%q): unexpected error in default mode; %s", v.input, err)
+		}
+		_, err := ParseStrict(strings.NewReader(v.input))
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("ParseStrict(%q): expected a ParseError, actual %v", v.input, err)
+			continue
+		}
+		if perr.Line != v.line || perr.Col != v.col {
+			t.Errorf("ParseStrict(%q): expected error at %d:%d, actual %d:%d (%s)", v.input, v.line, v.col, perr.Line, perr.Col, perr)
+		}
+	}
+	input := "pl p0 : a (1)\ntr t0 : b [0,2] p0 -> p1\npl p0 : a (1) -> t1\ntr t0 : b [0,2] p1 -> p0\n"
+	net, err := ParseStrict(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("ParseStrict(%q): unexpected error; %s", input, err)
+	}
+	if s := net.Mtoa(net.Initial); s != "p0" || len(net.Tr) != 2 || len(net.Delta[0]) != 0 {
+		t.Errorf("ParseStrict: wrong net\n%s", net.String())
+	}
+}
+
+func TestParseNodeKind(t *testing.T) {
+	tables := []struct {
+		input, msg string
+		line, col  int
+	}{
+		{"tr t2 p0 -> p1\ntr t1 t2 -> p1\n", "name t2 is a transition, expected a place", 2, 7},
+		{"tr t1 p0 -> p1\ntr t2 p1 -> t1\n", "name t1 is a transition, expected a place", 2, 13},
+		{"pl p0 (1)\npl p1 p0 -> t0\n", "name p0 is a place, expected a transition", 2, 7},
+		{"tr t0 p0 -> p1\npl p1 -> p0\n", "name p0 is a place, expected a transition", 2, 10},
+	}
+	for _, v := range tables {
+		_, err := ParseString(v.input)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Errorf("Parse(%q): expected a ParseError, actual %v", v.input, err)
+			continue
+		}
+		if perr.Msg != v.msg || perr.Line != v.line || perr.Col != v.col {
+			t.Errorf("Parse(%q): expected %q at %d:%d, actual %s", v.input, v.msg, v.line, v.col, perr)
+		}
+	}
+	// a place and a transition can share a name when both are declared
+	net, err := ParseString("pl a (1)\ntr a a -> b\npl b -> a\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if len(net.Pl) != 2 || len(net.Tr) != 1 || net.Mtoa(net.Delta[0]) != "a*-1" {
+		t.Errorf("Wrong net\n%s", net.String())
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	input := `net demo # name of the net
+pl p0 (1) # after a marking
+pl p1 : lab# after a label
+tr t0 [0,2]# after an interval
+  p0 -> p1 # after an arc list
+tr t1 [1, # inside an interval
+  3] p1*2#after a weight
+  p0?1 # after a read arc
+  -> p0
+pr t0 > t1 # after a priority
+nt n1 0 {a # b} # after a note
+tr t2 : {#} p1 -> p0 #`
+	net, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	expected, err := ParseString("net demo\npl p0 (1)\npl p1 : lab\ntr t0 [0,2] p0 -> p1\ntr t1 [1,3] p1*2 p0?1 -> p0\npr t0 > t1\nnt n1 0 {a # b}\ntr t2 : {#} p1 -> p0\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if err := expected.compare(net); err != nil {
+		t.Errorf("Wrong net with comments; %s\n%s", err, net.String())
+	}
+	if _, err := ParseString("tr t0 : # label\np0 -> p1\n"); err == nil {
+		t.Errorf("Parse: expected an error for an empty label")
+	}
+}
+
+func TestParseIntervalMultiplier(t *testing.T) {
+	net, err := ParseString("tr t0 [0,1K] p0 -> p1\ntr t1 ]2K,3M[ p1 -> p0\ntr t2 [1K,w[ p0 ->\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	for k, v := range []string{"[0,1000]", "]2000,3000000[", "[1000,w["} {
+		if s := net.Time[k].String(); s != v {
+			t.Errorf("Wrong interval for %s, expected %s, actual %s", net.Tr[k], v, s)
+		}
+	}
+	if !strings.Contains(net.String(), "tr t0 [0,1000] ") {
+		t.Errorf("Wrong output for t0\n%s", net.String())
+	}
+	for _, s := range []string{"tr t0 [2K,1K]\n", "tr t0 [0,3G]\n", "tr t0 [0,K]\n", "tr t0 [wK,w[\n"} {
+		if _, err := ParseString(s); err == nil {
+			t.Errorf("Parse(%q): expected an error", s)
+		}
+	}
+}
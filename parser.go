@@ -9,11 +9,12 @@ package nets
 //
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
 	"io"
 	"math"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -25,25 +26,203 @@ type parser struct {
 	pl, tr map[string]int // list of place and trans. identifiers
 	tok    token          // last read token
 	ahead  bool           // true if there is a token stored in tok
+	// in collect mode, we try to recover from errors and accumulate them in
+	// errs instead of stopping at the first one.
+	collect bool
+	errs    []error
+	opts    ParseOptions // extensions of the format
+	// in strict mode, we record the attributes of the places and transitions
+	// that have been declared, to detect conflicting declarations.
+	plDecl, trDecl map[int]declared
+}
+
+// declared records which attributes of a node have been set explicitly in a
+// declaration: its label, and its initial marking (for places) or time
+// interval (for transitions).
+type declared struct {
+	label, value bool
+}
+
+// ParseError is the type of errors found when parsing a .net file. Line and
+// Col give the position of the error in the input (starting from 1) and Msg
+// describes the problem. Errors returned by the parsing functions wrap a
+// ParseError, which can be retrieved using errors.As.
+type ParseError struct {
+	Line, Col int
+	Msg       string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%s at line: %d column: %d", e.Msg, e.Line, e.Col)
 }
 
 // Parse returns a pointer to a Net structure from a textual representation of a
 // TPN. We return a nil pointer and an error if there was a problem while
 // reading the specification.
 func Parse(r io.Reader) (*Net, error) {
-	p := &parser{
-		s:     &scanner{r: bufio.NewReader(r), pos: &textPos{}},
-		net:   &Net{},
-		pl:    make(map[string]int),
-		tr:    make(map[string]int),
-		ahead: false,
+	return ParseWithOptions(r, ParseOptions{})
+}
+
+// ParseFile returns the Net described in the .net file at the given path. It
+// opens the file, parses its content, and closes it, even when there is an
+// error. Parse errors mention the name of the file, such as in "error parsing
+// testdata/demo.net: ...".
+func ParseFile(path string) (*Net, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	p := newParser(file, ParseOptions{})
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return p.net, nil
+}
+
+// ParseString returns the Net described by the string s, in the .net format.
+// This is the same as calling Parse on a reader for s.
+func ParseString(s string) (*Net, error) {
+	return Parse(strings.NewReader(s))
+}
+
+// newParser returns a parser reading from r with the given options.
+func newParser(r io.Reader, opts ParseOptions) *parser {
+	return &parser{
+		s:    newScanner(r),
+		net:  &Net{},
+		pl:   make(map[string]int),
+		tr:   make(map[string]int),
+		opts: opts,
+	}
+}
+
+// checkDecl is used in strict mode to check that a declaration does not set an
+// attribute of a node that has already been declared with a different value,
+// in which case we return an error at position pos. Argument decl is the table
+// of declared attributes for the kind of node (places or transitions), kind
+// and name are used in the error message, attr is the name of the attribute,
+// and same tells whether the old and new values are equal. We return false
+// when the attribute has already been set to the same value, meaning that the
+// declaration can be ignored.
+func (p *parser) checkDecl(decl *map[int]declared, index int, pos textPos, kind, name, attr string, same bool) (bool, error) {
+	if !p.opts.Strict {
+		return true, nil
+	}
+	if *decl == nil {
+		*decl = make(map[int]declared)
+	}
+	d := (*decl)[index]
+	set := &d.value
+	if attr == "label" {
+		set = &d.label
+	}
+	if *set {
+		if !same {
+			return false, pos.errorf("%s %s declared twice with different %ss", kind, name, attr)
+		}
+		return false, nil
+	}
+	*set = true
+	(*decl)[index] = d
+	return true, nil
+}
+
+// ParseOptions is used to enable extensions of the .net format when parsing a
+// net. With the default value (all options to false) we only accept the
+// standard format.
+type ParseOptions struct {
+	// Markings enables the declaration of named markings, with lines of the
+	// form "mk name p1*2 p3", meaning the marking with two tokens in p1 and
+	// one token in p3. Named markings are stored in field NamedMarkings of
+	// the net. They are not printed back by Fprint, since this declaration is
+	// not part of the format. Places that do not appear in other
+	// declarations are created, like with arcs, and the name mk cannot be
+	// used as a place or transition name.
+	Markings bool
+	// Weights enables the declaration of transition weights, with lines of
+	// the form "wt t 0.25", used for a stochastic interpretation of the net
+	// (see SampleTransition). Weights are stored in field Weight of the net.
+	// Like with named markings, they are not printed back by Fprint and the
+	// name wt cannot be used as a place or transition name.
+	Weights bool
+	// Strict makes it an error to declare the same place or transition
+	// several times with conflicting attributes, meaning two different
+	// labels, two different initial markings, or two different time
+	// intervals. This is useful to catch typos in names, that would
+	// otherwise silently fuse two nodes. Declarations that only add arcs to
+	// an existing node are still allowed, and so are declarations that
+	// repeat the same attribute; in this case the attribute is used only
+	// once, so that a marking is not counted twice.
+	Strict bool
+	// Probabilities enables the annotation of output arcs with a probability,
+	// such as "tr t p0 -> p1%0.4 p2*2%0.6". Annotated arcs are alternative
+	// branches of the transition, stored in field ArcProb of the net, and are
+	// not accounted for in Delta (see SampleOutcome). They are printed back by
+	// Fprint, so this option must be set to parse the result.
+	Probabilities bool
+}
+
+// ParseStrict is a variant of Parse where option Strict is set, meaning that
+// we return an error if the same place or transition is declared several times
+// with conflicting attributes. The error gives the position of the second
+// declaration.
+func ParseStrict(r io.Reader) (*Net, error) {
+	return ParseWithOptions(r, ParseOptions{Strict: true})
+}
+
+// ParseWithHint is a variant of Parse where placeHint and transHint are
+// estimates of the number of places and transitions in the net. We use them to
+// allocate the slices of the net, and the tables used to find nodes by name,
+// with the right capacity from the start, which reduces the number of
+// allocations when parsing large nets. The hints do not need to be exact, and
+// we ignore values that are not positive, in which case we behave like Parse.
+func ParseWithHint(r io.Reader, placeHint, transHint int) (*Net, error) {
+	p := newParser(r, ParseOptions{})
+	p.reserve(placeHint, transHint)
+	if err := p.parse(); err != nil {
+		return nil, fmt.Errorf("error parsing net: %w", err)
 	}
+	return p.net, nil
+}
+
+// ParseWithOptions is a variant of Parse where we can enable extensions of the
+// .net format using opts.
+func ParseWithOptions(r io.Reader, opts ParseOptions) (*Net, error) {
+	p := newParser(r, opts)
 	if err := p.parse(); err != nil {
-		return nil, fmt.Errorf("error parsing net: %s", err)
+		return nil, fmt.Errorf("error parsing net: %w", err)
 	}
 	return p.net, nil
 }
 
+// ParseAll is a variant of Parse that does not stop at the first error. When
+// we find an error in a declaration, such as a bad time interval, an unknown
+// keyword or a malformed arc, we skip the input until the start of the next
+// declaration, meaning the next tr, pl, pr, nt or net keyword (or the end of
+// file), and continue parsing from there. Hence at most one error is reported
+// for each declaration.
+//
+// We return the net built from all the declarations, that may be partial,
+// together with the list of all the errors found, in the order they appear.
+// Errors related to a position in the input wrap a value of type *ParseError,
+// that can be retrieved using errors.As. The list of errors is empty when the
+// input is valid.
+func ParseAll(r io.Reader) (*Net, []error) {
+	p := newParser(r, ParseOptions{})
+	p.collect = true
+	_ = p.parse()
+	return p.net, p.errs
+}
+
+// ParseCollect returns the net described by r together with all the errors
+// found when parsing it, recovering at declaration boundaries. It is the same
+// as ParseAll, which documents the recovery strategy, and is kept so that
+// linters written against this name keep working.
+func ParseCollect(r io.Reader) (*Net, []error) {
+	return ParseAll(r)
+}
+
 // scan returns the next token from the underlying scanner.
 // If a token has been unscanned then read that instead.
 func (p *parser) scan() token {
@@ -63,6 +242,28 @@ func (p *parser) unscan() {
 	p.ahead = true
 }
 
+// reserve allocates the tables of the parser, and the slices of the net, with
+// a capacity of np places and nt transitions. We do nothing for values that
+// are not positive.
+func (p *parser) reserve(np, nt int) {
+	if np > 0 {
+		p.pl = make(map[string]int, np)
+		p.net.Pl = make([]string, 0, np)
+		p.net.Plabel = make([]string, 0, np)
+	}
+	if nt > 0 {
+		p.tr = make(map[string]int, nt)
+		p.net.Tr = make([]string, 0, nt)
+		p.net.Tlabel = make([]string, 0, nt)
+		p.net.Time = make([]TimeInterval, 0, nt)
+		p.net.Cond = make([]Marking, 0, nt)
+		p.net.Inhib = make([]Marking, 0, nt)
+		p.net.Pre = make([]Marking, 0, nt)
+		p.net.Delta = make([]Marking, 0, nt)
+		p.net.Prio = make([][]int, 0, nt)
+	}
+}
+
 // checkPL returns the index of a place in the net and creates one if necessary.
 // We do not support placer labels at the moment.
 func (p *parser) checkPL(s string) int {
@@ -99,45 +300,98 @@ func (p *parser) checkTR(s string) int {
 	return n
 }
 
+// checkArcPL is like checkPL but for the name of a place in an arc of a tr
+// declaration. We return an error if the name is already used by a transition,
+// and not by a place, since this is most probably a mistake. A place can have
+// the same name as a transition if it has been declared before.
+func (p *parser) checkArcPL(tok token) (int, error) {
+	if _, ok := p.pl[tok.s]; !ok {
+		if _, ok := p.tr[tok.s]; ok {
+			return -1, tok.pos.errorf("name %s is a transition, expected a place", tok.s)
+		}
+	}
+	return p.checkPL(tok.s), nil
+}
+
+// checkArcTR is the dual of checkArcPL for the name of a transition in an arc
+// of a pl declaration.
+func (p *parser) checkArcTR(tok token) (int, error) {
+	if _, ok := p.tr[tok.s]; !ok {
+		if _, ok := p.pl[tok.s]; ok {
+			return -1, tok.pos.errorf("name %s is a place, expected a transition", tok.s)
+		}
+	}
+	return p.checkTR(tok.s), nil
+}
+
 func (p *parser) parse() error {
 	for {
-		switch tok := p.scan(); tok.tok {
-		case tokEOF:
+		tok := p.scan()
+		if tok.tok == tokEOF {
+			p.net.plIndex, p.net.trIndex = p.pl, p.tr
 			return nil
-		case tokNET:
-			tok = p.scan()
-			if tok.tok != tokIDENT {
-				return fmt.Errorf(" found %q; expected identifier after NET at %s", tok.s, tok.pos.String())
-			}
-			p.net.Name = tok.s
-		case tokTR:
-			if e := p.parseTR(); e != nil {
-				return e
-			}
-		case tokPL:
-			if e := p.parsePL(); e != nil {
-				return e
-			}
-		case tokPRIO:
-			if e := p.parsePRIO(); e != nil {
-				return e
-			}
-		case tokNOTE:
-			if e := p.parseNOTE(); e != nil {
-				return e
+		}
+		if err := p.parseDecl(tok); err != nil {
+			if !p.collect {
+				return err
 			}
-		default:
-			return fmt.Errorf(" found %q; expected keywords, %s",
-				tok.s, tok.pos.String())
+			p.errs = append(p.errs, fmt.Errorf("error parsing net: %w", err))
+			p.resync()
+		}
+	}
+}
+
+// parseDecl parses a declaration starting with token tok.
+func (p *parser) parseDecl(tok token) error {
+	switch tok.tok {
+	case tokNET:
+		tok = p.scan()
+		if tok.tok != tokIDENT {
+			return tok.pos.errorf("found %q; expected identifier after NET", tok.s)
+		}
+		p.net.Name = tok.s
+	case tokTR:
+		return p.parseTR()
+	case tokPL:
+		return p.parsePL()
+	case tokPRIO:
+		return p.parsePRIO()
+	case tokNOTE:
+		return p.parseNOTE()
+	case tokIDENT:
+		if p.isExtension(tok) && tok.s == "mk" {
+			return p.parseMK()
+		}
+		if p.isExtension(tok) && tok.s == "wt" {
+			return p.parseWT()
+		}
+		return tok.pos.errorf("found %q; expected keywords", tok.s)
+	default:
+		return tok.pos.errorf("found %q; expected keywords", tok.s)
+	}
+	return nil
+}
+
+// resync is used to recover from an error, in collect mode, by skipping tokens
+// until the start of the next declaration, meaning one of the keywords tr, pl,
+// pr, nt or net (or the end of file). The last token read, that may be the
+// cause of the error, is also considered.
+func (p *parser) resync() {
+	tok := p.tok
+	for {
+		switch tok.tok {
+		case tokEOF, tokTR, tokPL, tokPRIO, tokNOTE, tokNET:
+			p.unscan()
+			return
 		}
+		tok = p.scan()
 	}
 }
 
 func (p *parser) parseTR() error {
-	var err error
 	tok := p.scan()
 	if tok.tok != tokIDENT {
-		return fmt.Errorf(" found %q, expected valid transition name at %s", tok.s, tok.pos.String())
+		return tok.pos.errorf("found %q, expected valid transition name", tok.s)
 	}
 	index := p.checkTR(tok.s)
 	// we shouldcheck for an (optional) label then (also optional) time
@@ -151,42 +405,49 @@ func (p *parser) parseTR() error {
 		switch tok := p.scan(); tok.tok {
 		case tokLABEL:
 			if haslabel || hastinterval || hasarcs {
-				return fmt.Errorf(" bad label declaration, at %s", tok.pos.String())
+				return tok.pos.errorf("bad label declaration")
 			}
 			haslabel = true // to avoid double label decl
-			p.net.Tlabel[index] = tok.s
+			label := checkLabel(tok.s)
+			if _, err := p.checkDecl(&p.trDecl, index, tok.pos, "transition", p.net.Tr[index], "label", label == p.net.Tlabel[index]); err != nil {
+				return err
+			}
+			p.net.Tlabel[index] = label
 		case tokTIMINGC:
 			if hastinterval || hasarcs {
-				return fmt.Errorf(" bad time interval declaration, at %s", tok.pos.String())
+				return tok.pos.errorf("bad time interval declaration")
 			}
 			hastinterval = true // to avoid double time interval decl
 			tgc := TimeInterval{}
 			arr := strings.Fields(tok.s)
 			if len(arr) != 4 {
-				return fmt.Errorf(" bad time interval declaration, %s at %s", tok.s, tok.pos.String())
+				return tok.pos.errorf("bad time interval declaration, %s", tok.s)
 			}
 			if arr[0] == "[" {
 				tgc.Left.Bkind = BCLOSE
 			} else {
 				tgc.Left.Bkind = BOPEN
 			}
-			v1, err := strconv.Atoi(arr[1])
+			v1, err := mconvert(arr[1])
 			if err != nil {
-				return fmt.Errorf(" in timing interval, %s at %s", tok.s, tok.pos.String())
+				return tok.pos.errorf("in timing interval, %s (%s)", tok.s, err)
 			}
 			if (v1 < 0) || (v1 >= math.MaxInt32) {
-				return fmt.Errorf(" coefficient in time interval must be positive and less than 2^31, %s at %s", tok.s, tok.pos.String())
+				return tok.pos.errorf("coefficient in time interval must be positive and less than 2^31, %s", tok.s)
 			}
 			tgc.Left.Value = v1
 			if arr[2] == "w" {
 				tgc.Right.Bkind = BINFTY
 			} else {
-				v2, err := strconv.Atoi(arr[2])
-				if (err != nil) || (v2 < v1) {
-					return fmt.Errorf(" in timing interval, %s at %s", tok.s, tok.pos.String())
+				v2, err := mconvert(arr[2])
+				if err != nil {
+					return tok.pos.errorf("in timing interval, %s (%s)", tok.s, err)
+				}
+				if v2 < v1 {
+					return tok.pos.errorf("in timing interval, %s", tok.s)
 				}
 				if (v2 < 0) || (v2 >= math.MaxInt32) {
-					return fmt.Errorf(" coefficient in time interval must be positive and less than 2^31, %s at %s", tok.s, tok.pos.String())
+					return tok.pos.errorf("coefficient in time interval must be positive and less than 2^31, %s", tok.s)
 				}
 				tgc.Right.Value = v2
 				if arr[3] == "[" {
@@ -195,19 +456,29 @@ func (p *parser) parseTR() error {
 					tgc.Right.Bkind = BCLOSE
 				}
 			}
+			if _, err := p.checkDecl(&p.trDecl, index, tok.pos, "transition", p.net.Tr[index], "time interval", tgc == p.net.Time[index]); err != nil {
+				return err
+			}
 			if err := p.net.Time[index].intersectWith(tgc); err != nil {
-				return fmt.Errorf(" %s: for transition %s, at %s", err, p.net.Tr[index], tok.pos.String())
+				return tok.pos.errorf("%s: for transition %s", err, p.net.Tr[index])
 			}
 		case tokARROW:
 			if afterArrow {
-				return fmt.Errorf(" cannot have two arrows (->) in tr declaration at %s", tok.pos.String())
+				return tok.pos.errorf("cannot have two arrows (->) in tr declaration")
 			}
 			hasarcs = true // to avoid label and time interval decl after declaring arcs
 			afterArrow = true
 		case tokIDENT:
 			// tinput  ::= <place>{<arc>}
 			// toutput ::= <place>{<normal_arc>}
-			pindex := p.checkPL(tok.s)
+			if p.isExtension(tok) {
+				p.unscan()
+				return nil
+			}
+			pindex, err := p.checkArcPL(tok)
+			if err != nil {
+				return err
+			}
 			hasarcs = true
 			tok = p.scan()
 			mult := 1
@@ -215,26 +486,31 @@ func (p *parser) parseTR() error {
 			switch tok.tok {
 			case tokREAD:
 				if afterArrow {
-					return fmt.Errorf(" read arcs in outputs of transition at %s", tok.pos.String())
+					return tok.pos.errorf("read arcs in outputs of transition")
 				}
 				mult, err = mconvert(tok.s)
 				if err != nil {
-					return fmt.Errorf(" in multiplicity, %s (%s) at %s", tok.s, err, tok.pos.String())
+					return tok.pos.errorf("in multiplicity, %s (%s)", tok.s, err)
 				}
 				p.net.Cond[index] = p.net.Cond[index].updateIfGreater(pindex, mult)
 			case tokINHIBITOR:
 				if afterArrow {
-					return fmt.Errorf(" inhibitor arcs in outputs of transition at %s", tok.pos.String())
+					return tok.pos.errorf("inhibitor arcs in outputs of transition")
 				}
 				mult, err = mconvert(tok.s)
 				if err != nil {
-					return fmt.Errorf(" in multiplicity, %s (%s) at %s", tok.s, err, tok.pos.String())
+					return tok.pos.errorf("in multiplicity, %s (%s)", tok.s, err)
 				}
 				p.net.Inhib[index] = p.net.Inhib[index].updateIfLess(pindex, mult)
+			case tokRESET:
+				if afterArrow {
+					return tok.pos.errorf("reset arcs in outputs of transition")
+				}
+				p.net.addReset(index, pindex)
 			case tokSTAR:
 				mult, err = mconvert(tok.s)
 				if err != nil {
-					return fmt.Errorf(" in multiplicity, %s (%s) at %s", tok.s, err, tok.pos.String())
+					return tok.pos.errorf("in multiplicity, %s (%s)", tok.s, err)
 				}
 				ok = true
 				fallthrough
@@ -246,7 +522,13 @@ func (p *parser) parseTR() error {
 					p.unscan()
 				}
 				if afterArrow {
-					p.net.Delta[index] = p.net.Delta[index].AddToPlace(pindex, mult)
+					prob, err := p.parsePROB(index, pindex, mult)
+					if err != nil {
+						return err
+					}
+					if !prob {
+						p.net.Delta[index] = p.net.Delta[index].AddToPlace(pindex, mult)
+					}
 				} else {
 					p.net.Delta[index] = p.net.Delta[index].AddToPlace(pindex, -mult)
 					p.net.Pre[index] = p.net.Pre[index].AddToPlace(pindex, -mult)
@@ -260,12 +542,39 @@ func (p *parser) parseTR() error {
 	}
 }
 
+// parsePROB checks whether the output arc from transition t to place pl, with
+// weight mult, is followed by a probability annotation, such as p%0.3, in
+// which case we add the corresponding probabilistic arc to the net and return
+// true. Annotations are only allowed with option Probabilities.
+func (p *parser) parsePROB(t, pl, mult int) (bool, error) {
+	tok := p.scan()
+	if tok.tok != tokPROB {
+		p.unscan()
+		return false, nil
+	}
+	if !p.opts.Probabilities {
+		return false, tok.pos.errorf("probability annotation %%%s on arc of transition %s needs option Probabilities", tok.s, p.net.Tr[t])
+	}
+	prob, err := strconv.ParseFloat(tok.s, 64)
+	if err != nil || prob < 0 || prob > 1 {
+		return false, tok.pos.errorf("probability of arc must be a number between 0 and 1, %s", tok.s)
+	}
+	p.net.addProbArc(t, ProbArc{Pl: pl, Mult: mult, Prob: prob})
+	total := 0.0
+	for _, a := range p.net.probArcs(t) {
+		total += a.Prob
+	}
+	if total > 1+1e-9 {
+		return false, tok.pos.errorf("probabilities of the arcs of transition %s sum to more than 1", p.net.Tr[t])
+	}
+	return true, nil
+}
+
 func (p *parser) parsePL() error {
 	//   pldesc ::= ’pl’ <place> {":" <label>} {(<marking>)} {<pinput> -> <poutput>}
-	var err error
 	tok := p.scan()
 	if tok.tok != tokIDENT {
-		return fmt.Errorf(" found %q, expected valid place name at %s", tok.s, tok.pos.String())
+		return tok.pos.errorf("found %q, expected valid place name", tok.s)
 	}
 	index := p.checkPL(tok.s)
 	afterArrow := false // in case we have tr declarations
@@ -276,31 +585,57 @@ func (p *parser) parsePL() error {
 		switch tok := p.scan(); tok.tok {
 		case tokLABEL:
 			if haslabel || hasinitm || hasarcs {
-				return fmt.Errorf(" bad label declaration, at %s", tok.pos.String())
+				return tok.pos.errorf("bad label declaration")
 			}
 			haslabel = true
-			p.net.Plabel[index] = tok.s
+			label := checkLabel(tok.s)
+			if _, err := p.checkDecl(&p.plDecl, index, tok.pos, "place", p.net.Pl[index], "label", label == p.net.Plabel[index]); err != nil {
+				return err
+			}
+			p.net.Plabel[index] = label
 		case tokMARKING:
 			if hasinitm || hasarcs {
-				return fmt.Errorf(" bad marking declaration, at %s", tok.pos.String())
+				return tok.pos.errorf("bad marking declaration")
 			}
 			plm, err := mconvert(tok.s)
 			if err != nil {
-				return fmt.Errorf(" in marking, %s (%s) at %s", tok.s, err, tok.pos.String())
+				return tok.pos.errorf("in marking, %s (%s)", tok.s, err)
 			}
 			hasinitm = true
-			p.net.Initial = p.net.Initial.AddToPlace(index, plm)
+			add, err := p.checkDecl(&p.plDecl, index, tok.pos, "place", p.net.Pl[index], "marking", plm == p.net.Initial.Get(index))
+			if err != nil {
+				return err
+			}
+			if !add {
+				continue
+			}
+			if n := len(p.net.Initial); plm != 0 && (n == 0 || p.net.Initial[n-1].Pl < index) {
+				// the common case of places declared in order; since the
+				// initial marking is not shared during parsing, we can
+				// append to it instead of using AddToPlace, that always
+				// returns a copy.
+				p.net.Initial = append(p.net.Initial, Atom{index, plm})
+			} else {
+				p.net.Initial = p.net.Initial.AddToPlace(index, plm)
+			}
 		case tokARROW:
 			if afterArrow {
-				return fmt.Errorf(" cannot have two arrows (->) in pl declaration at %s", tok.pos.String())
+				return tok.pos.errorf("cannot have two arrows (->) in pl declaration")
 			}
 			hasarcs = true // to avoid label and time interval decl after declaring arcs
 			afterArrow = true
 		case tokIDENT:
 			// then tok.s is the name of a transition
+			if p.isExtension(tok) {
+				p.unscan()
+				return nil
+			}
 			//    pinput  ::= <transition>{<normal_arc>}
 			//    poutput ::= <transition>{arc}
-			tindex := p.checkTR(tok.s)
+			tindex, err := p.checkArcTR(tok)
+			if err != nil {
+				return err
+			}
 			hasarcs = true
 			tok = p.scan()
 			mult := 1
@@ -308,26 +643,31 @@ func (p *parser) parsePL() error {
 			switch tok.tok {
 			case tokREAD:
 				if !afterArrow {
-					return fmt.Errorf(" read arcs in inputs of place, at %s", tok.pos.String())
+					return tok.pos.errorf("read arcs in inputs of place")
 				}
 				mult, err = mconvert(tok.s)
 				if err != nil {
-					return fmt.Errorf(" in multiplicity, %s (%s) at %s", tok.s, err, tok.pos.String())
+					return tok.pos.errorf("in multiplicity, %s (%s)", tok.s, err)
 				}
 				p.net.Cond[tindex] = p.net.Cond[tindex].updateIfGreater(index, mult)
 			case tokINHIBITOR:
 				if !afterArrow {
-					return fmt.Errorf(" inhibitor arcs in inputs of place at %s", tok.pos.String())
+					return tok.pos.errorf("inhibitor arcs in inputs of place")
 				}
 				mult, err = mconvert(tok.s)
 				if err != nil {
-					return fmt.Errorf(" in multiplicity, %s (%s) at %s", tok.s, err, tok.pos.String())
+					return tok.pos.errorf("in multiplicity, %s (%s)", tok.s, err)
 				}
 				p.net.Inhib[tindex] = p.net.Inhib[tindex].updateIfLess(index, mult)
+			case tokRESET:
+				if !afterArrow {
+					return tok.pos.errorf("reset arcs in inputs of place")
+				}
+				p.net.addReset(tindex, index)
 			case tokSTAR:
 				mult, err = mconvert(tok.s)
 				if err != nil {
-					return fmt.Errorf(" in multiplicity, %s (%s) at %s", tok.s, err, tok.pos.String())
+					return tok.pos.errorf("in multiplicity, %s (%s)", tok.s, err)
 				}
 				ok = true
 				fallthrough
@@ -356,16 +696,148 @@ func (p *parser) parsePL() error {
 func (p *parser) parseNOTE() error {
 	tok := p.scan()
 	if tok.tok != tokIDENT {
-		return fmt.Errorf(" found %q, expected a note identifier at %s", tok.s, tok.pos.String())
+		return tok.pos.errorf("found %q, expected a note identifier", tok.s)
 	}
+	name := tok.s
 	tok = p.scan()
 	if tok.tok != tokINT {
-		return fmt.Errorf(" found %q, expected a note index at %s", tok.s, tok.pos.String())
+		return tok.pos.errorf("found %q, expected a note index", tok.s)
+	}
+	flag, err := strconv.Atoi(tok.s)
+	if err != nil {
+		return tok.pos.errorf("in note index, %s (%s)", tok.s, err)
 	}
 	tok = p.scan()
 	if tok.tok != tokIDENT {
-		return fmt.Errorf(" found %q, expected a note body at %s", tok.s, tok.pos.String())
+		return tok.pos.errorf("found %q, expected a note body", tok.s)
+	}
+	p.net.Notes = append(p.net.Notes, Note{Name: name, Flag: flag, Body: tok.s})
+	switch name {
+	case "color":
+		p.parseColor(tok.s)
+	case "server":
+		return p.parseServer(tok)
+	}
+	return nil
+}
+
+// parseServer checks if the body of a server note, in token tok, is of the
+// form {t kind}, where t is the name of a transition and kind is either
+// single, infinite or a positive integer, in which case we record the server
+// kind of t. We silently ignore notes that do not follow this convention, but
+// we return an error if t is not the name of a transition declared before the
+// note.
+func (p *parser) parseServer(tok token) error {
+	body := tok.s
+	if !strings.HasPrefix(body, "{") || !strings.HasSuffix(body, "}") {
+		return nil
+	}
+	arr := strings.Fields(body[1 : len(body)-1])
+	if len(arr) != 2 {
+		return nil
+	}
+	kind, err := parseServerKind(arr[1])
+	if err != nil {
+		return nil
+	}
+	t, ok := p.tr[arr[0]]
+	if !ok {
+		return tok.pos.errorf("unknown transition %s in server note", arr[0])
+	}
+	p.net.SetServer(t, kind)
+	return nil
+}
+
+// parseColor checks if the body of a color note is of the form {node color},
+// in which case we record the color of the node in the net. We silently ignore
+// notes that do not follow this convention.
+func (p *parser) parseColor(body string) {
+	if !strings.HasPrefix(body, "{") || !strings.HasSuffix(body, "}") {
+		return
+	}
+	arr := strings.Fields(body[1 : len(body)-1])
+	if len(arr) != 2 {
+		return
+	}
+	if p.net.NodeColor == nil {
+		p.net.NodeColor = make(map[string]string)
+	}
+	p.net.NodeColor[arr[0]] = arr[1]
+}
+
+// isExtension reports whether tok is a keyword starting a declaration that is
+// an extension of the format enabled in the parser options. These keywords are
+// scanned as identifiers, so we need to check them explicitly at every place
+// where a declaration may end.
+func (p *parser) isExtension(tok token) bool {
+	if tok.tok != tokIDENT {
+		return false
+	}
+	return (p.opts.Markings && tok.s == "mk") || (p.opts.Weights && tok.s == "wt")
+}
+
+// parseMK parses the declaration of a named marking, when option Markings is
+// set.
+//
+//	mkdesc ::= 'mk' <name> (<place>{<normal_arc>})*
+func (p *parser) parseMK() error {
+	tok := p.scan()
+	if tok.tok != tokIDENT {
+		return tok.pos.errorf("found %q, expected a marking name", tok.s)
+	}
+	name := tok.s
+	if _, ok := p.net.NamedMarkings[name]; ok {
+		return tok.pos.errorf("marking %s declared twice", name)
+	}
+	m := Marking{}
+	for {
+		tok = p.scan()
+		if tok.tok != tokIDENT || p.isExtension(tok) {
+			p.unscan()
+			break
+		}
+		pindex := p.checkPL(tok.s)
+		mult := 1
+		tok = p.scan()
+		if tok.tok == tokSTAR {
+			var err error
+			mult, err = mconvert(tok.s)
+			if err != nil {
+				return tok.pos.errorf("in multiplicity, %s (%s)", tok.s, err)
+			}
+		} else {
+			p.unscan()
+		}
+		m = m.AddToPlace(pindex, mult)
+	}
+	if p.net.NamedMarkings == nil {
+		p.net.NamedMarkings = make(map[string]Marking)
+	}
+	p.net.NamedMarkings[name] = m
+	return nil
+}
+
+// parseWT parses the declaration of a transition weight, when option Weights
+// is set.
+//
+//	wtdesc ::= 'wt' <transition> (INT | REAL)
+func (p *parser) parseWT() error {
+	tok := p.scan()
+	if tok.tok != tokIDENT {
+		return tok.pos.errorf("found %q, expected a transition name", tok.s)
+	}
+	index := p.checkTR(tok.s)
+	p.s.decimal = true
+	tok = p.scan()
+	p.s.decimal = false
+	if tok.tok != tokINT && tok.tok != tokREAL {
+		return tok.pos.errorf("found %q, expected a weight", tok.s)
 	}
+	w, err := strconv.ParseFloat(tok.s, 64)
+	if err != nil {
+		return tok.pos.errorf("in weight, %s (%s)", tok.s, err)
+	}
+	p.net.SetWeight(index, w)
 	return nil
 }
 
@@ -382,7 +854,7 @@ func (p *parser) parsePRIO() error {
 		pre = setAdd(pre, n)
 	}
 	if tok.tok != tokGT && tok.tok != tokLT {
-		return fmt.Errorf("found %q, expected priority > or < at %s", tok.s, tok.pos.String())
+		return tok.pos.errorf("found %q, expected priority > or <", tok.s)
 	}
 	if tok.tok == tokGT {
 		isgt = true
@@ -409,38 +881,54 @@ func (p *parser) parsePRIO() error {
 	}
 }
 
+// checkLabel returns the label that we store for a label declaration. An
+// explicit empty label, written {}, is the same as no label at all and is
+// stored as the empty string. This way printing a net never outputs a label
+// declaration for it.
+func checkLabel(s string) string {
+	if s == "{}" {
+		return ""
+	}
+	return s
+}
+
 // setAdd takes a sorted list of integers (here transitions index), s, and adds
-// v to it.
+// v to it. We use a binary search to find the position of v. The result is a
+// new slice, unless v is already in s, in which case we return s.
 func setAdd(s []int, v int) []int {
-	if len(s) == 0 {
-		return []int{v}
+	i := sort.SearchInts(s, v)
+	if i < len(s) && s[i] == v {
+		return s
 	}
-	for i := range s {
-		if s[i] == v {
-			return s
-		}
-		if s[i] > v {
-			res := make([]int, len(s)+1)
-			copy(res[:i], s[:i])
-			copy(res[i+1:], s[i:])
-			res[i] = v
-			return res
-		}
-	}
-	res := make([]int, len(s))
-	copy(res, s)
-	res = append(res, v)
+	res := make([]int, len(s)+1)
+	copy(res[:i], s[:i])
+	copy(res[i+1:], s[i:])
+	res[i] = v
 	return res
 }
 
 // setUnion does set union between two slices of sorted integers, s1 and s2.
+// We merge the two slices in a single pass, so the complexity is linear in the
+// size of the result. The result is always a new slice.
 func setUnion(s1, s2 []int) []int {
-	res := make([]int, len(s1))
-	copy(res, s1)
-	for _, v := range s2 {
-		res = setAdd(res, v)
+	res := make([]int, 0, len(s1)+len(s2))
+	i1, i2 := 0, 0
+	for i1 < len(s1) && i2 < len(s2) {
+		switch {
+		case s1[i1] == s2[i2]:
+			res = append(res, s1[i1])
+			i1++
+			i2++
+		case s1[i1] < s2[i2]:
+			res = append(res, s1[i1])
+			i1++
+		default:
+			res = append(res, s2[i2])
+			i2++
+		}
 	}
-	return res
+	res = append(res, s1[i1:]...)
+	return append(res, s2[i2:]...)
 }
 
 // setIncluded returns true if all the elements in slice s1 are also in s2. This
@@ -481,45 +969,44 @@ func setMember(s []int, v int) int {
 
 // mconvert is used to convert values found on markings and weights into
 // integers. We take into account the possibility that s ends with a
-// "multiplier", such as `3K` (3000), which is valid in Tina.
+// "multiplier", such as `3K` (3000), which is valid in Tina. We return an
+// error if the result overflows, meaning its absolute value is greater than
+// math.MaxInt32, since we assume that markings and weights fit into a 32 bits
+// integer.
 func mconvert(s string) (int, error) {
 	if len(s) == 0 {
 		return 0, errors.New("empty value in weights or marking")
 	}
-	iv, err := strconv.Atoi(s)
-
+	// we use 64 bits integers so that multipliers T, P and E also fit on 32
+	// bits architectures
+	var mult int64 = 1
+	switch s[len(s)-1] {
+	case 'K':
+		mult = 1e3
+	case 'M':
+		mult = 1e6
+	case 'G':
+		mult = 1e9
+	case 'T':
+		mult = 1e12
+	case 'P':
+		mult = 1e15
+	case 'E':
+		mult = 1e18
+	}
+	digits := s
+	if mult != 1 {
+		digits = s[:len(s)-1]
+	}
+	iv, err := strconv.ParseInt(digits, 10, 64)
 	if err != nil {
-		if ch := s[len(s)-1]; ch == 'K' || ch == 'M' || ch == 'G' || ch == 'T' || ch == 'P' || ch == 'E' {
-			iv, err = strconv.Atoi(s[:len(s)-1])
-			if err != nil {
-				return 0, fmt.Errorf("not a valid weight or marking; %s", err)
-			}
-			if iv > math.MaxInt32 {
-				return 0, fmt.Errorf("overflow: max value is 2^31 (Int32.MaxValue); %v", s)
-			}
-			v := iv
-			switch ch {
-			case 'K':
-				return v * 1000, nil
-			case 'M':
-				return v * 1000000, nil
-			case 'G':
-				return v * 1000000000, nil
-			case 'T':
-				return v, fmt.Errorf("multiplier T is not supported: max marking or weight is 2^31 (Int32.MaxValue); %v", ch)
-			case 'P':
-				return v, fmt.Errorf("multiplier P is not supported: max marking or weight is 2^31 (Int32.MaxValue); %v", ch)
-			case 'E':
-				return v, fmt.Errorf("multiplier E is not supported: max marking or weight is 2^31 (Int32.MaxValue); %v", ch)
-			default:
-				return v, fmt.Errorf("not a valid multiplier in weight or marking; %v", ch)
-			}
+		if errors.Is(err, strconv.ErrRange) {
+			return 0, fmt.Errorf("value %s overflows integer range (max value is 2^31-1)", s)
 		}
+		return 0, fmt.Errorf("not a valid weight or marking; %s", err)
 	}
-
-	v := iv
-	if iv > math.MaxInt32 {
-		return v, fmt.Errorf("overflow: max value is 2^31 (Int32.MaxValue); %v", s)
+	if iv > math.MaxInt32/mult || iv < -math.MaxInt32/mult {
+		return 0, fmt.Errorf("value %s overflows integer range (max value is 2^31-1)", s)
 	}
-	return v, nil
+	return int(iv * mult), nil
 }
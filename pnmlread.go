@@ -0,0 +1,221 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/dalzilio/nets/internal/pnml"
+)
+
+// ParsePNML returns a Net from a P/T net in PNML format, such as the models
+// used in the Model Checking Contest, or the files generated with method Pnml.
+// We read places, with their initial marking, transitions and arcs, with their
+// weights, and ignore graphical information. Several arcs between the same
+// place and transition are merged by adding their weights. Hence a pair of
+// input/output arcs, which is how Pnml marshals read arcs, becomes a
+// transition that consumes and produces the same tokens.
+//
+// We also accept the extensions generated with PnmlWithOptions. Inhibitor arcs
+// are arcs with attribute type="inhibitor"; when there are several inhibitor
+// arcs between the same nodes, we keep the one with the smallest weight. Read
+// arcs are arcs with attribute type="test" (or type="read"); when there are
+// several read arcs between the same nodes, we keep the largest weight. Time
+// intervals are given in toolspecific elements of transitions, with tool
+// "tina", using the format described in PnmlOptions.
+//
+// Names are obtained from ids, after removing the prefix 'pl_' for places and
+// 'tr_' for transitions, if any. This is the convention used by Pnml, so that
+// a net without read arcs (or any net, when using option ReadArcs of
+// PnmlWithOptions) is unchanged after a round-trip. When the name of a
+// node, in the PNML file, is different from its id, we use it as the label of
+// the node, after removing the prefix "name: ", if any.
+//
+// We return an error if the file does not contain exactly one net of type
+// ptnet, or if it uses features that have no equivalent in our format, such as
+// hierarchical pages, reference nodes, arcs with a type other than normal,
+// inhibitor, test or read, or toolspecific elements (except for time
+// intervals).
+func ParsePNML(r io.Reader) (*Net, error) {
+	doc, err := pnml.Read(r)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode PNML file; %s", err)
+	}
+	if len(doc.NETS) != 1 {
+		return nil, fmt.Errorf("PNML file should contain exactly one net, not %d", len(doc.NETS))
+	}
+	pn := doc.NETS[0]
+	if !strings.HasSuffix(pn.Thetype, "/ptnet") {
+		return nil, fmt.Errorf("unsupported PNML net type %q (only P/T nets are supported)", pn.Thetype)
+	}
+	if len(pn.TOOLS) != 0 {
+		return nil, fmt.Errorf("unsupported toolspecific element (tool %s) in net %s", pn.TOOLS[0].Tool, pn.ID)
+	}
+	net := &Net{Name: pn.NAME}
+	if net.Name == "" {
+		net.Name = pn.ID
+	}
+	pl := make(map[string]int)
+	tr := make(map[string]int)
+	// arcs may refer to nodes declared in another page, so we read all the
+	// nodes before the arcs.
+	for _, page := range pn.PAGES {
+		if err := pnmlCheckPage(page); err != nil {
+			return nil, err
+		}
+		for _, v := range page.PLACES {
+			if len(v.TOOLS) != 0 {
+				return nil, fmt.Errorf("unsupported toolspecific element (tool %s) in place %s", v.TOOLS[0].Tool, v.ID)
+			}
+			if _, ok := pl[v.ID]; ok {
+				return nil, fmt.Errorf("duplicate place id %s", v.ID)
+			}
+			name := strings.TrimPrefix(v.ID, "pl_")
+			pl[v.ID] = len(net.Pl)
+			net.Pl = append(net.Pl, name)
+			net.Plabel = append(net.Plabel, pnmlLabel(name, v.NAME))
+			if init := strings.TrimSpace(v.INIT); init != "" {
+				m, err := strconv.Atoi(init)
+				if err != nil || m < 0 {
+					return nil, fmt.Errorf("bad initial marking %q for place %s", v.INIT, v.ID)
+				}
+				net.Initial = net.Initial.AddToPlace(pl[v.ID], m)
+			}
+		}
+		for _, v := range page.TRANS {
+			if _, ok := tr[v.ID]; ok {
+				return nil, fmt.Errorf("duplicate transition id %s", v.ID)
+			}
+			name := strings.TrimPrefix(v.ID, "tr_")
+			tr[v.ID] = net.addTransition(name)
+			net.Tlabel[tr[v.ID]] = pnmlLabel(name, v.NAME)
+			for _, tool := range v.TOOLS {
+				if tool.Tool != pnml.TinaTool || tool.Interval == nil {
+					return nil, fmt.Errorf("unsupported toolspecific element (tool %s) in transition %s", tool.Tool, v.ID)
+				}
+				i, err := pnmlReadInterval(*tool.Interval)
+				if err != nil {
+					return nil, fmt.Errorf("bad time interval for transition %s; %s", v.ID, err)
+				}
+				net.Time[tr[v.ID]] = i
+			}
+		}
+	}
+	// read arcs are added after the other arcs, since the condition of a
+	// transition depends on the weights of its input arcs.
+	var reads []Atom
+	var readTr []int
+	for _, page := range pn.PAGES {
+		for _, v := range page.ARCS {
+			if v.TYPE != "" && v.TYPE != "normal" && v.TYPE != pnml.InhibitorArc && v.TYPE != pnml.ReadArc && v.TYPE != "read" {
+				return nil, fmt.Errorf("unsupported type %q for arc %s", v.TYPE, v.ID)
+			}
+			mult := 1
+			if w := strings.TrimSpace(v.WEIGHT); w != "" {
+				mult, err = strconv.Atoi(w)
+				if err != nil || mult <= 0 {
+					return nil, fmt.Errorf("bad inscription %q for arc %s", v.WEIGHT, v.ID)
+				}
+			}
+			p, srcpl := pl[v.SOURCE]
+			t, tgttr := tr[v.TARGET]
+			if v.TYPE == pnml.InhibitorArc {
+				if !srcpl || !tgttr {
+					return nil, fmt.Errorf("inhibitor arc %s should go from a place to a transition", v.ID)
+				}
+				net.Inhib[t] = net.Inhib[t].updateIfLess(p, mult)
+				continue
+			}
+			if v.TYPE == pnml.ReadArc || v.TYPE == "read" {
+				if !srcpl || !tgttr {
+					return nil, fmt.Errorf("read arc %s should go from a place to a transition", v.ID)
+				}
+				reads = append(reads, Atom{p, mult})
+				readTr = append(readTr, t)
+				continue
+			}
+			if srcpl && tgttr {
+				net.Cond[t] = net.Cond[t].AddToPlace(p, mult)
+				net.Pre[t] = net.Pre[t].AddToPlace(p, -mult)
+				net.Delta[t] = net.Delta[t].AddToPlace(p, -mult)
+				continue
+			}
+			t, srctr := tr[v.SOURCE]
+			p, tgtpl := pl[v.TARGET]
+			if srctr && tgtpl {
+				net.Delta[t] = net.Delta[t].AddToPlace(p, mult)
+				continue
+			}
+			return nil, fmt.Errorf("arc %s should go from a place to a transition, or from a transition to a place", v.ID)
+		}
+	}
+	for k, a := range reads {
+		net.Cond[readTr[k]] = net.Cond[readTr[k]].updateIfGreater(a.Pl, a.Mult)
+	}
+	net.indexNodes()
+	return net, nil
+}
+
+// pnmlCheckPage returns an error if page uses features of hierarchical nets or
+// toolspecific elements.
+func pnmlCheckPage(page pnml.PageDecl) error {
+	switch {
+	case len(page.PAGES) != 0:
+		return fmt.Errorf("unsupported hierarchical page %s", page.PAGES[0].ID)
+	case len(page.REFPLACE) != 0:
+		return fmt.Errorf("unsupported reference place %s", page.REFPLACE[0].ID)
+	case len(page.REFTRANS) != 0:
+		return fmt.Errorf("unsupported reference transition %s", page.REFTRANS[0].ID)
+	case len(page.TOOLS) != 0:
+		return fmt.Errorf("unsupported toolspecific element (tool %s) in page %s", page.TOOLS[0].Tool, page.ID)
+	}
+	return nil
+}
+
+// pnmlLabel returns the label of a node with the given name from the text of
+// its PNML name element.
+func pnmlLabel(name, text string) string {
+	text = strings.TrimSpace(text)
+	if text == "" || text == name {
+		return ""
+	}
+	return strings.TrimPrefix(text, name+": ")
+}
+
+// pnmlReadInterval returns the time interval corresponding to i, or an error
+// if it is not valid.
+func pnmlReadInterval(i pnml.Interval) (TimeInterval, error) {
+	kind := func(s string) (bool, error) {
+		switch s {
+		case pnmlBkind[BCLOSE]:
+			return true, nil
+		case pnmlBkind[BOPEN]:
+			return false, nil
+		}
+		return false, fmt.Errorf("unknown bound kind %q", s)
+	}
+	loClosed, err := kind(i.Left)
+	if err != nil {
+		return TimeInterval{}, err
+	}
+	if i.EFT < 0 {
+		return TimeInterval{}, fmt.Errorf("negative earliest firing time %d", i.EFT)
+	}
+	if i.LFT == "" {
+		return NewUnboundedInterval(i.EFT, loClosed), nil
+	}
+	hi, err := strconv.Atoi(i.LFT)
+	if err != nil {
+		return TimeInterval{}, fmt.Errorf("bad latest firing time %q", i.LFT)
+	}
+	hiClosed, err := kind(i.Right)
+	if err != nil {
+		return TimeInterval{}, err
+	}
+	return NewInterval(i.EFT, hi, loClosed, hiClosed)
+}
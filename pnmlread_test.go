@@ -0,0 +1,212 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParsePNMLRoundTrip(t *testing.T) {
+	net, err := Parse(strings.NewReader("net pt\npl p0 : start (2)\ntr t0 : go p0*2 -> p1 p2*3\ntr t1 p1 p2 -> p0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	var buf bytes.Buffer
+	if err := net.Pnml(&buf); err != nil {
+		t.Fatalf("Error in Pnml; %s", err)
+	}
+	net2, err := ParsePNML(&buf)
+	if err != nil {
+		t.Fatalf("Error in ParsePNML; %s", err)
+	}
+	if err := net.compare(net2); err != nil {
+		t.Errorf("ParsePNML: net changed after a round-trip; %s", err)
+	}
+}
+
+func TestPnmlWithInhibitor(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0 p1?-3 -> p1\ntr t1 p1?-1 p2?-2 -> p2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	var buf bytes.Buffer
+	if err := net.Pnml(&buf); err == nil {
+		t.Errorf("Pnml should fail with inhibitor arcs")
+	}
+	buf.Reset()
+	if err := net.PnmlWithInhibitor(&buf); err != nil {
+		t.Fatalf("Error in PnmlWithInhibitor; %s", err)
+	}
+	expected := []string{
+		`<net type="http://www.pnml.org/version-2009/grammar/ptnet"`,
+		`<arc id="inh-p1-t0" source="pl_p1" target="tr_t0" type="inhibitor">`,
+		`<arc id="inh-p1-t1" source="pl_p1" target="tr_t1" type="inhibitor"></arc>`,
+	}
+	for _, v := range expected {
+		if !strings.Contains(buf.String(), v) {
+			t.Errorf("PnmlWithInhibitor: expected %s in\n%s", v, buf.String())
+		}
+	}
+	net2, err := ParsePNML(&buf)
+	if err != nil {
+		t.Fatalf("Error in ParsePNML; %s", err)
+	}
+	if err := net.compare(net2); err != nil {
+		t.Errorf("PnmlWithInhibitor: net changed after a round-trip; %s", err)
+	}
+}
+
+func TestPnmlTime(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 ]2,5] p0 -> p1\ntr t1 [3,w[ p1 -> p0\ntr t2 [0,w[ p1 -> p0\ntr t3 [0,0] p1 ->\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	var buf bytes.Buffer
+	if err := net.Pnml(&buf); err != nil {
+		t.Fatalf("Error in Pnml; %s", err)
+	}
+	if strings.Contains(buf.String(), "toolspecific") {
+		t.Errorf("Pnml should not output time intervals by default:\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := net.PnmlWithOptions(&buf, PnmlOptions{Time: true}); err != nil {
+		t.Fatalf("Error in PnmlWithOptions; %s", err)
+	}
+	expected := []string{
+		`<toolspecific tool="tina" version="3">`,
+		`<interval left="open" eft="2" lft="5" right="closed"></interval>`,
+		`<interval left="closed" eft="3"></interval>`,
+	}
+	for _, v := range expected {
+		if !strings.Contains(buf.String(), v) {
+			t.Errorf("PnmlWithOptions: expected %s in\n%s", v, buf.String())
+		}
+	}
+	if n := strings.Count(buf.String(), "<toolspecific"); n != 3 {
+		t.Errorf("PnmlWithOptions: expected 3 toolspecific elements, actual %d", n)
+	}
+	net2, err := ParsePNML(&buf)
+	if err != nil {
+		t.Fatalf("Error in ParsePNML; %s", err)
+	}
+	if err := net.compare(net2); err != nil {
+		t.Errorf("PnmlWithOptions: net changed after a round-trip; %s", err)
+	}
+	// bad intervals are rejected
+	for _, v := range []string{`left="closed" eft="-1"`, `left="half" eft="1"`, `left="closed" eft="3" lft="2" right="closed"`, `left="closed" eft="3" lft="x" right="closed"`} {
+		input := strings.Replace(pnmlMCC, `<transition id="t1">`, `<transition id="t1"><toolspecific tool="tina" version="3"><interval `+v+`/></toolspecific>`, 1)
+		if _, err := ParsePNML(strings.NewReader(input)); err == nil {
+			t.Errorf("ParsePNML: expected error with interval %s", v)
+		}
+	}
+}
+
+func TestPnmlReadArcs(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\npl p1 (3)\ntr t0 p0 p1?2 -> p2\ntr t1 p1*2 p1?3 p2 -> p0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	var buf bytes.Buffer
+	if err := net.PnmlWithOptions(&buf, PnmlOptions{ReadArcs: true}); err != nil {
+		t.Fatalf("Error in PnmlWithOptions; %s", err)
+	}
+	expected := []string{
+		`<arc id="rd-p1-t0" source="pl_p1" target="tr_t0" type="test">`,
+		`<arc id="rd-p1-t1" source="pl_p1" target="tr_t1" type="test">`,
+		`<arc id="p2t-p1-t1" source="pl_p1" target="tr_t1">`,
+	}
+	for _, v := range expected {
+		if !strings.Contains(buf.String(), v) {
+			t.Errorf("PnmlWithOptions: expected %s in\n%s", v, buf.String())
+		}
+	}
+	for _, v := range []string{`id="p2t-p1-t0"`, `id="t2p-t0-p1"`, `id="t2p-t1-p1"`} {
+		if strings.Contains(buf.String(), v) {
+			t.Errorf("PnmlWithOptions: unexpected arc %s in\n%s", v, buf.String())
+		}
+	}
+	net2, err := ParsePNML(&buf)
+	if err != nil {
+		t.Fatalf("Error in ParsePNML; %s", err)
+	}
+	if err := net.compare(net2); err != nil {
+		t.Errorf("PnmlWithOptions: net changed after a round-trip; %s", err)
+	}
+	// read arcs may appear before input arcs and use type read
+	input := strings.Replace(pnmlMCC, `<arc id="a1"`, `<arc id="r1" source="p1" target="t1" type="read"><inscription><text>3</text></inscription></arc><arc id="a1"`, 1)
+	net, err = ParsePNML(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error in ParsePNML; %s", err)
+	}
+	if s := net.Mtoa(net.Cond[0]); s != "p1*3" {
+		t.Errorf("ParsePNML: expected condition p1*3, actual %s", s)
+	}
+	if s := net.Mtoa(net.Pre[0]); s != "p1*-1" {
+		t.Errorf("ParsePNML: expected pre p1*-1, actual %s", s)
+	}
+}
+
+const pnmlMCC = `<?xml version="1.0" encoding="UTF-8"?>
+<pnml xmlns="http://www.pnml.org/version-2009/grammar/pnml">
+  <net id="mcc" type="http://www.pnml.org/version-2009/grammar/ptnet">
+    <page id="page0">
+      <place id="p1">
+        <name><graphics><offset x="0" y="0"/></graphics><text>p1</text></name>
+        <initialMarking><text>1</text></initialMarking>
+      </place>
+      <place id="p2"><name><text>buffer</text></name></place>
+      <transition id="t1"><name><text>t1</text></name></transition>
+      <arc id="a1" source="p1" target="t1"/>
+      <arc id="a2" source="t1" target="p2"><inscription><text>2</text></inscription></arc>
+    </page>
+  </net>
+</pnml>
+`
+
+func TestParsePNML(t *testing.T) {
+	net, err := ParsePNML(strings.NewReader(pnmlMCC))
+	if err != nil {
+		t.Fatalf("Error in ParsePNML; %s", err)
+	}
+	if net.Name != "mcc" || len(net.Pl) != 2 || len(net.Tr) != 1 {
+		t.Fatalf("ParsePNML: wrong net %s with places %v and transitions %v", net.Name, net.Pl, net.Tr)
+	}
+	if net.Plabel[0] != "" || net.Plabel[1] != "buffer" {
+		t.Errorf("ParsePNML: wrong labels %q", net.Plabel)
+	}
+	if s := net.Mtoa(net.Initial); s != "p1" {
+		t.Errorf("ParsePNML: expected initial marking p1, actual %s", s)
+	}
+	if s := net.Mtoa(net.Delta[0]); s != "p1*-1 p2*2" {
+		t.Errorf("ParsePNML: expected delta p1*-1 p2*2, actual %s", s)
+	}
+	if s := net.Mtoa(net.Cond[0]); s != "p1" {
+		t.Errorf("ParsePNML: expected condition p1, actual %s", s)
+	}
+}
+
+func TestParsePNMLErrors(t *testing.T) {
+	tables := []struct {
+		old, new string
+	}{
+		{`grammar/ptnet"`, `grammar/symmetricnet"`},
+		{`<page id="page0">`, `<page id="page0"><page id="sub"/>`},
+		{`<transition id="t1">`, `<transition id="t1"><toolspecific tool="tina" version="3"/>`},
+		{`<arc id="a1"`, `<arc id="a1" type="reset"`},
+		{`<arc id="a2"`, `<arc id="a2" type="inhibitor"`},
+		{`<arc id="a2"`, `<arc id="a2" type="test"`},
+		{`source="t1" target="p2"`, `source="t1" target="t1"`},
+		{`<text>2</text></inscription>`, `<text>-2</text></inscription>`},
+		{`<place id="p2">`, `<place id="p1">`},
+	}
+	for _, tt := range tables {
+		input := strings.Replace(pnmlMCC, tt.old, tt.new, 1)
+		if _, err := ParsePNML(strings.NewReader(input)); err == nil {
+			t.Errorf("ParsePNML: expected error after replacing %s with %s", tt.old, tt.new)
+		}
+	}
+}
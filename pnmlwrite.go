@@ -7,14 +7,17 @@ package nets
 import (
 	"fmt"
 	"io"
+	"strconv"
 
 	"github.com/dalzilio/nets/internal/pnml"
 )
 
 // Pnml marshall a Net into a P/T net in PNML format and writes the output on an
 // io.Writer. Because of limitations in the PNML format, we return an error if
-// the net has inhibitor arcs. We also drop timing information on transitions
-// and replace read arcs with "tests"; meaning a pair of input/output arcs.
+// the net has inhibitor, reset or probabilistic arcs. We also drop timing
+// information on transitions and replace read arcs with "tests"; meaning a
+// pair of input/output arcs. Use PnmlWithOptions to keep inhibitor arcs, read
+// arcs and timing information.
 //
 // This method is only useful if you create or modify an object of type Net. It
 // is preferable to use the `ndrio` program to transform a .net file into a PNML
@@ -23,13 +26,66 @@ import (
 // We combine names and labels for the naming of places and transitions in the
 // PNML file but we build the id by adding a prefix ('pl_' for places and 'tr_'
 // for transitions), because it is possible to use the same name as a place and
-// as a transition in a .net file.
+// as a transition in a .net file. This is the convention used by ParsePNML, so
+// that the result can be read back.
 func (net *Net) Pnml(w io.Writer) error {
+	return net.PnmlWithOptions(w, PnmlOptions{})
+}
+
+// PnmlWithInhibitor is like Pnml but also accepts nets with inhibitor arcs. It
+// is the same as calling PnmlWithOptions with option Inhibitors.
+func (net *Net) PnmlWithInhibitor(w io.Writer) error {
+	return net.PnmlWithOptions(w, PnmlOptions{Inhibitors: true})
+}
+
+// PnmlOptions is used to enable extensions of the P/T nets format when
+// marshalling a net with PnmlWithOptions. With the default value (all options
+// to false) we only generate standard P/T nets.
+type PnmlOptions struct {
+	// Inhibitors enables the marshalling of inhibitor arcs. They are written
+	// as arcs from the place to the transition with attribute
+	// type="inhibitor", and with the weight of the arc as inscription, which is
+	// the extension of P/T nets supported by tools such as LoLA or TAPAAL.
+	Inhibitors bool
+	// Time enables the marshalling of the time interval of transitions. Every
+	// transition with a non-trivial interval (different from [0,w[) gets a
+	// toolspecific element, with attributes tool="tina" and version="3",
+	// containing an interval element. For instance, interval ]2,5] is written
+	// <interval left="open" eft="2" lft="5" right="closed"/>, and interval
+	// [3,w[ is written <interval left="closed" eft="3"/>, meaning that
+	// attributes lft and right are omitted when there is no right bound.
+	Time bool
+	// ReadArcs enables the marshalling of read arcs as arcs from the place to
+	// the transition with attribute type="test", instead of a pair of input
+	// and output arcs. Input and output arcs are then obtained from Pre and
+	// Delta, meaning that we only consume the tokens that are actually
+	// consumed by the transition, and we add a test arc, with the weight of
+	// the condition, for every place where the condition in Cond is stronger
+	// than what is consumed.
+	ReadArcs bool
+}
+
+// PnmlWithOptions is like Pnml but with options to marshal features that are
+// not part of P/T nets, like inhibitor arcs, read arcs and time intervals. The
+// result can be read back using ParsePNML. We still return an error if the net
+// has reset or probabilistic arcs, or inhibitor arcs when option Inhibitors is
+// not set.
+func (net *Net) PnmlWithOptions(w io.Writer, opts PnmlOptions) error {
 	for k, v := range net.Inhib {
-		if len(v) != 0 {
+		if len(v) != 0 && !opts.Inhibitors {
 			return fmt.Errorf("cannot marshal net with inhibitor arcs; see transition %s", net.Tr[k])
 		}
 	}
+	for k, v := range net.Reset {
+		if len(v) != 0 {
+			return fmt.Errorf("cannot marshal net with reset arcs; see transition %s", net.Tr[k])
+		}
+	}
+	for k, v := range net.ArcProb {
+		if len(v) != 0 {
+			return fmt.Errorf("cannot marshal net with probabilistic arcs; see transition %s", net.Tr[k])
+		}
+	}
 	places := make([]pnml.Place, len(net.Pl))
 	trans := make([]pnml.Trans, len(net.Tr))
 	for k, v := range net.Pl {
@@ -47,6 +103,14 @@ func (net *Net) Pnml(w io.Writer) error {
 			Out:   []pnml.Arc{},
 		}
 		pre := net.Cond[k]
+		if opts.ReadArcs {
+			pre = net.Pre[k].Scale(-1)
+			for _, m := range net.Cond[k] {
+				if m.Mult > pre.Get(m.Pl) {
+					trans[k].Read = append(trans[k].Read, pnml.Arc{Place: &places[m.Pl], Mult: int(m.Mult)})
+				}
+			}
+		}
 		for _, m := range pre {
 			trans[k].In = append(trans[k].In, pnml.Arc{Place: &places[m.Pl], Mult: int(m.Mult)})
 		}
@@ -54,6 +118,25 @@ func (net *Net) Pnml(w io.Writer) error {
 		for _, m := range post {
 			trans[k].Out = append(trans[k].Out, pnml.Arc{Place: &places[m.Pl], Mult: int(m.Mult)})
 		}
+		if opts.Time && !net.Time[k].Trivial() {
+			trans[k].Time = pnmlInterval(net.Time[k])
+		}
+		for _, m := range net.Inhib[k] {
+			trans[k].Inhib = append(trans[k].Inhib, pnml.Arc{Place: &places[m.Pl], Mult: int(m.Mult)})
+		}
 	}
 	return pnml.Write(w, net.Name, places, trans)
 }
+
+// pnmlBkind gives the names of the kinds of bounds used in PNML files.
+var pnmlBkind = map[Bkind]string{BCLOSE: "closed", BOPEN: "open"}
+
+// pnmlInterval returns the PNML representation of time interval i.
+func pnmlInterval(i TimeInterval) *pnml.Interval {
+	res := &pnml.Interval{Left: pnmlBkind[i.Left.Bkind], EFT: i.Left.Value}
+	if i.Right.Bkind != BINFTY {
+		res.LFT = strconv.Itoa(i.Right.Value)
+		res.Right = pnmlBkind[i.Right.Bkind]
+	}
+	return res
+}
@@ -0,0 +1,44 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+// resets returns the sorted list of places reset by transition t.
+func (net *Net) resets(t int) []int {
+	if t < len(net.Reset) {
+		return net.Reset[t]
+	}
+	return nil
+}
+
+// addReset adds a reset arc between place p and transition t.
+func (net *Net) addReset(t, p int) {
+	for len(net.Reset) <= t {
+		net.Reset = append(net.Reset, nil)
+	}
+	net.Reset[t] = setAdd(net.Reset[t], p)
+}
+
+// hasResets reports whether some transition of the net has a reset arc.
+func (net *Net) hasResets() bool {
+	for _, v := range net.Reset {
+		if len(v) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// applyResets returns the marking obtained from m, the result of adding delta
+// to a marking when firing transition t without taking into account its reset
+// arcs, where the marking of every place reset by t is replaced with the number
+// of tokens produced by t in this place. Value delta is Delta[t], or one of the
+// outcomes of t when it has probabilistic arcs (see outcomes).
+func (net *Net) applyResets(m Marking, t int, delta Marking) Marking {
+	for _, p := range net.resets(t) {
+		post := delta.Get(p) - net.Pre[t].Get(p)
+		m = m.AddToPlace(p, post-m.Get(p))
+	}
+	return m
+}
@@ -0,0 +1,50 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestResetArcs(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\npl p1 (3)\ntr t0 p0 p1?! -> p2\ntr t1 p2 -> p0 p1*2\npl p1 -> t1?!\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if !slices.Equal(net.resets(0), []int{1}) || !slices.Equal(net.resets(1), []int{1}) {
+		t.Errorf("Wrong reset arcs, actual %v", net.Reset)
+	}
+	if err := net.SelfCheck(); err != nil {
+		t.Errorf("SelfCheck: %s", err)
+	}
+	m := net.fire(net.Initial, 0)
+	if expected := "p2"; net.Mtoa(m) != expected {
+		t.Errorf("Firing t0: expected %s, actual %s", expected, net.Mtoa(m))
+	}
+	m = net.fire(m, 1)
+	if expected := "p0 p1*2"; net.Mtoa(m) != expected {
+		t.Errorf("Firing t1: expected %s, actual %s", expected, net.Mtoa(m))
+	}
+	if ok, _ := net.SequenceEnabled(net.Initial, []int{0, 1, 0, 1}); !ok {
+		t.Errorf("SequenceEnabled: sequence t0 t1 t0 t1 should be enabled")
+	}
+	// p1 is reset, so it cannot be part of an invariant
+	inv, err := net.PInvariants()
+	if err != nil {
+		t.Fatalf("Error computing invariants; %s", err)
+	}
+	if expected := [][]int{{1, 0, 1}}; !slices.EqualFunc(inv, expected, slices.Equal) {
+		t.Errorf("Wrong invariants: expected %v, actual %v", expected, inv)
+	}
+	var b strings.Builder
+	if err := net.Pnml(&b); err == nil {
+		t.Errorf("Pnml: expected error on net with reset arcs")
+	}
+	if _, err := Parse(strings.NewReader("tr t0 p0 -> p1?!\n")); err == nil {
+		t.Errorf("Parse: expected error with reset arc in outputs")
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// SafeMarking is a compact representation of the markings of 1-safe nets,
+// meaning nets where every place holds at most one token in every reachable
+// marking. A SafeMarking is a Bitset over place indexes, where a place is in
+// the set when it has one token. This representation uses one bit for each
+// place of the net, instead of two ints for each marked place with Marking.
+// Use IsOneSafe to check that a net is 1-safe.
+type SafeMarking Bitset
+
+// NewSafeMarking returns an empty SafeMarking for a net with n places.
+func NewSafeMarking(n int) SafeMarking {
+	return SafeMarking(NewBitset(n))
+}
+
+// Set puts one token in place p. It panics if p is out of range.
+func (m SafeMarking) Set(p int) {
+	Bitset(m).Set(p)
+}
+
+// Clear removes the token in place p. It panics if p is out of range.
+func (m SafeMarking) Clear(p int) {
+	Bitset(m).Clear(p)
+}
+
+// Get returns the number of tokens in place p, either 0 or 1, like with the
+// method of the same name for Marking.
+func (m SafeMarking) Get(p int) int {
+	if Bitset(m).Test(p) {
+		return 1
+	}
+	return 0
+}
+
+// Equal reports whether m and m2 are the same marking.
+func (m SafeMarking) Equal(m2 SafeMarking) bool {
+	return Bitset(m).Equal(Bitset(m2))
+}
+
+// Clone returns a copy of m.
+func (m SafeMarking) Clone() SafeMarking {
+	return append(SafeMarking(nil), m...)
+}
+
+// Key returns a string that identifies marking m, for instance to use m as the
+// key of a map. Two markings of the same net have the same key if and only if
+// they are equal.
+func (m SafeMarking) Key() string {
+	buf := make([]byte, 8*len(m))
+	for k, w := range m {
+		binary.LittleEndian.PutUint64(buf[8*k:], w)
+	}
+	return string(buf)
+}
+
+// ToMarking returns marking m as a Marking.
+func (m SafeMarking) ToMarking() Marking {
+	res := Marking{}
+	Bitset(m).ForEach(func(p int) {
+		res = append(res, Atom{p, 1})
+	})
+	return res
+}
+
+// FromMarking returns marking m as a SafeMarking of the net. We return an
+// error if a place of m is not in the net, or if its multiplicity is not 1.
+func (net *Net) FromMarking(m Marking) (SafeMarking, error) {
+	res := NewSafeMarking(len(net.Pl))
+	for _, a := range m {
+		if a.Pl < 0 || a.Pl >= len(net.Pl) {
+			return nil, fmt.Errorf("invalid place index %d in marking", a.Pl)
+		}
+		if a.Mult != 1 {
+			return nil, fmt.Errorf("marking is not 1-safe, place %s has %d tokens", net.Pl[a.Pl], a.Mult)
+		}
+		res.Set(a.Pl)
+	}
+	return res, nil
+}
+
+// IsEnabledSafe is the same as IsEnabled for a SafeMarking.
+func (net *Net) IsEnabledSafe(m SafeMarking, t int) bool {
+	if net.IsDisabled(t) {
+		return false
+	}
+	for _, v := range net.Cond[t] {
+		if m.Get(v.Pl) < v.Mult {
+			return false
+		}
+	}
+	for _, v := range net.Inhib[t] {
+		if m.Get(v.Pl) >= v.Mult {
+			return false
+		}
+	}
+	return true
+}
+
+// FireSafe is the same as Fire for a SafeMarking. It returns the marking
+// obtained by firing transition t at marking m, and true if t is enabled at m
+// (see IsEnabledSafe). We return m unchanged and false when t is not enabled,
+// or when the result is not 1-safe, meaning that a place would have more than
+// one token. We take into account reset arcs and we never modify m. Like with
+// Fire, probabilistic arcs are ignored.
+func (net *Net) FireSafe(m SafeMarking, t int) (SafeMarking, bool) {
+	if !net.IsEnabledSafe(m, t) {
+		return m, false
+	}
+	res := m.Clone()
+	for _, p := range net.resets(t) {
+		// after adding Delta, the marking of p is the number of tokens
+		// produced by t
+		if -net.Pre[t].Get(p) == 1 {
+			res.Set(p)
+		} else {
+			res.Clear(p)
+		}
+	}
+	for _, v := range net.Delta[t] {
+		switch res.Get(v.Pl) + v.Mult {
+		case 0:
+			res.Clear(v.Pl)
+		case 1:
+			res.Set(v.Pl)
+		default:
+			return m, false
+		}
+	}
+	return res, true
+}
+
+// IsOneSafe reports whether the net is 1-safe, meaning that every place has at
+// most one token in every reachable marking, ignoring timing constraints and
+// priorities. We explore the reachable markings, like with Reachable, and stop
+// as soon as we find a marking that is not 1-safe. We return an error if we
+// find more than bound markings before reaching a conclusion.
+func (net *Net) IsOneSafe(bound int) (bool, error) {
+	safe := func(m Marking) bool {
+		for _, a := range m {
+			if a.Mult != 1 {
+				return false
+			}
+		}
+		return true
+	}
+	if !safe(net.Initial) {
+		return false, nil
+	}
+	var visited MarkingSet
+	if _, err := visited.Add(net.Initial); err != nil {
+		return false, err
+	}
+	for queue := []Marking{net.Initial}; len(queue) != 0; queue = queue[1:] {
+		m := queue[0]
+		for _, t := range net.AllEnabled(m) {
+			for _, m2 := range net.successors(m, t) {
+				if !safe(m2) {
+					return false, nil
+				}
+				added, err := visited.Add(m2)
+				if err != nil {
+					return false, err
+				}
+				if added {
+					if visited.Len() > bound {
+						return false, fmt.Errorf("more than %d reachable markings", bound)
+					}
+					queue = append(queue, m2)
+				}
+			}
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"os"
+	"testing"
+)
+
+func TestIsOneSafe(t *testing.T) {
+	tables := []struct {
+		input string
+		safe  bool
+	}{
+		{"pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p0\n", true},
+		{"pl p0 (2)\ntr t0 p0 -> p1\n", false},
+		{"pl p0 (1)\ntr t0 p0 -> p1 p2\ntr t1 p1 p2 -> p0\n", true},
+		{"pl p0 (1)\ntr t0 p0 -> p0 p1\ntr t1 p1 ->\n", false},
+		{"pl p0 (1)\ntr t0 p0 p1?-1 -> p0 p1\ntr t1 p1 ->\n", true},
+		{"pl p0 (1)\ntr t0 p0 -> p0 p1\ntr t1 p1?! -> p1\n", false},
+	}
+	for _, v := range tables {
+		net, err := ParseString(v.input)
+		if err != nil {
+			t.Fatalf("Error parsing net; %s", err)
+		}
+		safe, err := net.IsOneSafe(100)
+		if err != nil {
+			t.Fatalf("IsOneSafe(%q): %s", v.input, err)
+		}
+		if safe != v.safe {
+			t.Errorf("IsOneSafe(%q): expected %v, actual %v", v.input, v.safe, safe)
+		}
+	}
+	net, err := ParseFile("testdata/ifip.net")
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	if safe, err := net.IsOneSafe(1000); err != nil || safe {
+		t.Errorf("IsOneSafe(ifip.net): expected false, actual %v (%v)", safe, err)
+	}
+	net, err = ParseFile("testdata/sokoban_3.net")
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	if _, err := net.IsOneSafe(100); err == nil {
+		t.Errorf("IsOneSafe(sokoban_3.net) should return an error with a small bound")
+	}
+}
+
+func TestSafeMarking(t *testing.T) {
+	net, err := ParseString("pl p0 (1)\npl p1\npl p2\ntr t0 p0 -> p1 p2\ntr t1 p1 p2 -> p0\ntr t2 p2?1 p1?! -> p1\ntr t3 p1 -> p1*2\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	m, err := net.FromMarking(net.Initial)
+	if err != nil {
+		t.Fatalf("FromMarking: %s", err)
+	}
+	if !m.ToMarking().Equal(net.Initial) {
+		t.Errorf("ToMarking(FromMarking(%v)): actual %v", net.Initial, m.ToMarking())
+	}
+	// we fire all the transitions along the cycle t0 t2 t1 and compare the
+	// results with Fire
+	mk := net.Initial
+	for _, tr := range []int{0, 2, 1} {
+		for t2 := range net.Tr {
+			if net.IsEnabledSafe(m, t2) != net.IsEnabled(mk, t2) {
+				t.Errorf("IsEnabledSafe(%s, %s) differs from IsEnabled", net.Mtoa(mk), net.Tr[t2])
+			}
+		}
+		m2, ok := net.FireSafe(m, tr)
+		if !ok {
+			t.Fatalf("FireSafe(%s, %s): not enabled", net.Mtoa(mk), net.Tr[tr])
+		}
+		mk2 := net.fire(mk, tr)
+		if !m2.ToMarking().Equal(mk2) {
+			t.Errorf("FireSafe(%s, %s): expected %s, actual %s", net.Mtoa(mk), net.Tr[tr], net.Mtoa(mk2), net.Mtoa(m2.ToMarking()))
+		}
+		if !m.ToMarking().Equal(mk) {
+			t.Errorf("FireSafe(%s, %s) should not modify its argument", net.Mtoa(mk), net.Tr[tr])
+		}
+		m, mk = m2, mk2
+	}
+	m, _ = net.FromMarking(Marking{{Pl: 1, Mult: 1}})
+	if _, ok := net.FireSafe(m, 3); ok {
+		t.Errorf("FireSafe(p1, t3) should fail since the result is not 1-safe")
+	}
+	if m.Get(1) != 1 || m.Get(0) != 0 {
+		t.Errorf("Get: wrong value for %s", net.Mtoa(m.ToMarking()))
+	}
+	m0, _ := net.FromMarking(net.Initial)
+	if m0.Key() == m.Key() || m0.Equal(m) {
+		t.Errorf("Key and Equal should differ for different markings")
+	}
+	if _, err := net.FromMarking(Marking{{Pl: 0, Mult: 2}}); err == nil {
+		t.Errorf("FromMarking should fail for multiplicity 2")
+	}
+	if _, err := net.FromMarking(Marking{{Pl: 3, Mult: 1}}); err == nil {
+		t.Errorf("FromMarking should fail for an unknown place")
+	}
+}
+
+// BenchmarkSafeMarking compares the exploration of the first reachable
+// markings of the sokoban_3 net, whose places hold at most one token, with
+// Marking and SafeMarking.
+func BenchmarkSafeMarking(b *testing.B) {
+	input, err := os.ReadFile("testdata/sokoban_3.net")
+	if err != nil {
+		b.Fatalf("Error opening file; %s", err)
+	}
+	net, err := ParseString(string(input))
+	if err != nil {
+		b.Fatalf("Error parsing file; %s", err)
+	}
+	const bound = 10000
+	b.Run("Marking", func(b *testing.B) {
+		b.ReportAllocs()
+		for range b.N {
+			var visited MarkingSet
+			visited.Add(net.Initial)
+			for queue := []Marking{net.Initial}; len(queue) != 0 && visited.Len() < bound; queue = queue[1:] {
+				for _, t := range net.AllEnabled(queue[0]) {
+					m := net.fire(queue[0], t)
+					if added, _ := visited.Add(m); added {
+						queue = append(queue, m)
+					}
+				}
+			}
+		}
+	})
+	b.Run("SafeMarking", func(b *testing.B) {
+		b.ReportAllocs()
+		m0, err := net.FromMarking(net.Initial)
+		if err != nil {
+			b.Fatalf("FromMarking: %s", err)
+		}
+		for range b.N {
+			visited := map[string]bool{m0.Key(): true}
+			for queue := []SafeMarking{m0}; len(queue) != 0 && len(visited) < bound; queue = queue[1:] {
+				for t := range net.Tr {
+					if m, ok := net.FireSafe(queue[0], t); ok && !visited[m.Key()] {
+						visited[m.Key()] = true
+						queue = append(queue, m)
+					}
+				}
+			}
+		}
+	})
+}
@@ -7,24 +7,57 @@ package nets
 import (
 	"bufio"
 	"bytes"
+	"io"
 	"strings"
+	"unicode/utf8"
 )
 
 // scanner adds a position field for easy error reporting. We also include a
 // bytes buffer that is reused between scanning methods.
 type scanner struct {
-	r   *bufio.Reader
-	pos *textPos
-	buf bytes.Buffer
+	r     *bufio.Reader
+	pos   *textPos
+	start textPos // position of the first character of the current token
+	buf   bytes.Buffer
+	size  int // size in bytes of the last rune read, used by unread
+	// decimal is set when a decimal value, such as 0.25, is allowed in the
+	// current context, meaning the weight of a transition. Otherwise, we only
+	// scan integers.
+	decimal bool
+}
+
+// newScanner returns a scanner reading from r. We skip the byte order mark
+// (BOM) that some editors add at the beginning of UTF-8 files, so that a
+// leading comment, such as a "#!" line, is recognized as such.
+func newScanner(r io.Reader) *scanner {
+	br := bufio.NewReader(r)
+	if ch, _, err := br.ReadRune(); err == nil && ch != '\uFEFF' {
+		_ = br.UnreadRune()
+	}
+	return &scanner{r: br, pos: &textPos{}}
 }
 
 // read reads the next rune from the bufferred reader.
 // Returns the rune(0) if an error occurs (or io.EOF is returned).
+//
+// The .net format is ASCII, except inside names and labels between braces, so
+// we read bytes and only fall back to decoding runes when we find a byte that
+// is not ASCII.
 func (s *scanner) read() rune {
-	ch, _, err := s.r.ReadRune()
+	b, err := s.r.ReadByte()
 	if err != nil {
+		s.size = 0
 		return eof
 	}
+	ch := rune(b)
+	s.size = 1
+	if b >= utf8.RuneSelf {
+		_ = s.r.UnreadByte()
+		if ch, s.size, err = s.r.ReadRune(); err != nil {
+			s.size = 0
+			return eof
+		}
+	}
 	if s.pos.ahead != 0 {
 		s.pos.ahead--
 	} else {
@@ -38,15 +71,32 @@ func (s *scanner) read() rune {
 	return ch
 }
 
-// unread places the previously read rune back on the reader.
+// unread places the previously read rune back on the reader. We do nothing
+// after reading the end of file, which is returned again by the next read.
 func (s *scanner) unread() {
-	_ = s.r.UnreadRune()
+	switch {
+	case s.size == 0:
+		return
+	case s.size == 1:
+		_ = s.r.UnreadByte()
+	default:
+		_ = s.r.UnreadRune()
+	}
 	s.pos.ahead++
 }
 
-// returns a token with the current position in the file
+// returns a token with the position of its first character in the file
 func (s *scanner) position(t tokenKind, lit string) token {
-	return token{tok: t, pos: *s.pos, s: lit}
+	return token{tok: t, pos: s.start, s: lit}
+}
+
+// illegal returns an illegal token for the unexpected character ch, that may
+// be the end of file.
+func (s *scanner) illegal(ch rune) token {
+	if ch == eof {
+		return s.position(tokILLEGAL, "EOF")
+	}
+	return s.position(tokILLEGAL, string(ch))
 }
 
 // scan returns the next token and literal value.
@@ -57,6 +107,7 @@ func (s *scanner) scan() token {
 	for isWhitespace(ch) {
 		ch = s.read()
 	}
+	s.start = textPos{line: s.pos.line, col: s.pos.col - s.pos.ahead}
 
 	switch {
 	case isLetter(ch):
@@ -64,6 +115,14 @@ func (s *scanner) scan() token {
 		return s.scanIdent()
 	case isDigit(ch):
 		value := s.scanNumber(ch)
+		if !s.decimal {
+			return s.position(tokINT, value)
+		}
+		if ch := s.read(); ch == '.' {
+			// this is a decimal value, such as in a weight declaration
+			return s.position(tokREAL, value+"."+s.scanNumber(0))
+		}
+		s.unread()
 		return s.position(tokINT, value)
 	case ch == eof:
 		return s.position(tokEOF, "EOF")
@@ -71,11 +130,19 @@ func (s *scanner) scan() token {
 		return s.scanLabel()
 	case ch == '?' || ch == '*':
 		return s.scanArc(ch)
+	case ch == '%':
+		// probability of an output arc, such as p%0.3
+		value := s.scanNumber(0)
+		if ch := s.read(); ch == '.' {
+			return s.position(tokPROB, value+"."+s.scanNumber(0))
+		}
+		s.unread()
+		return s.position(tokPROB, value)
 	case ch == '-':
 		if ch1 := s.read(); ch1 == '>' {
 			return s.position(tokARROW, "->")
 		}
-		return s.position(tokILLEGAL, string(ch))
+		return s.illegal(ch)
 	case ch == '(':
 		return s.scanMarking()
 	case (ch == '[') || (ch == ']'):
@@ -86,16 +153,10 @@ func (s *scanner) scan() token {
 	case ch == '<':
 		return s.position(tokLT, string(ch))
 	case ch == '#':
-		// this is a comment, we skip until '\n'
-		for {
-			ch = s.read()
-			if ch == eof || ch == '\n' || ch == '\r' {
-				s.unread()
-				return s.scan()
-			}
-		}
+		s.skipComment()
+		return s.scan()
 	default:
-		return s.position(tokILLEGAL, string(ch))
+		return s.illegal(ch)
 	}
 }
 
@@ -115,17 +176,38 @@ func (s *scanner) scanTimingConstraint() token {
 			return s.position(tokTIMINGC, s.buf.String())
 		case ch == ',':
 			s.buf.WriteRune(' ')
-		case isDigit(ch) || (ch == 'w'):
+		case isDigit(ch) || (ch == 'w') || isMultiplier(ch):
 			s.buf.WriteRune(ch)
 		case isWhitespace(ch):
+		case ch == '#':
+			s.skipComment()
 		default:
-			return s.position(tokILLEGAL, string(ch))
+			return s.illegal(ch)
+		}
+	}
+}
+
+// skipComment skips the rest of a comment, starting with character '#' (that
+// has already been read), until the end of line. Comments can start anywhere
+// on a line, except inside names and labels between braces.
+func (s *scanner) skipComment() {
+	for {
+		ch := s.read()
+		if ch == eof || ch == '\n' || ch == '\r' {
+			s.unread()
+			return
 		}
 	}
 }
 
 func (s *scanner) scanArc(r rune) token {
 	ch := s.read()
+	if ch == '+' {
+		// we accept weights with an explicit sign, such as p*+2 or p?+1
+		if ch = s.read(); !isDigit(ch) {
+			return s.illegal(ch)
+		}
+	}
 	switch {
 	case (r == '?'):
 		switch {
@@ -135,8 +217,10 @@ func (s *scanner) scanArc(r rune) token {
 		case ch == '-':
 			weight := s.scanNumber(0)
 			return s.position(tokINHIBITOR, weight)
+		case ch == '!':
+			return s.position(tokRESET, "?!")
 		default:
-			return s.position(tokILLEGAL, string(ch))
+			return s.illegal(ch)
 		}
 	case (r == '*'):
 		switch {
@@ -144,10 +228,10 @@ func (s *scanner) scanArc(r rune) token {
 			weight := s.scanNumber(ch)
 			return s.position(tokSTAR, weight)
 		default:
-			return s.position(tokILLEGAL, string(ch))
+			return s.illegal(ch)
 		}
 	default:
-		return s.position(tokILLEGAL, string(ch))
+		return s.illegal(ch)
 	}
 }
 
@@ -160,31 +244,12 @@ func (s *scanner) scanLabel() token {
 		ch = s.read()
 	}
 
-	if ch == eof || ch == '}' || ch == '\\' {
-		return s.position(tokILLEGAL, string(ch))
+	if ch == eof || ch == '}' || ch == '\\' || ch == '#' {
+		return s.illegal(ch)
 	}
 
 	if ch == '{' {
-		// we accept any chain between braces, and in which characters {, }, and \ are prefixed by \
-		s.buf.WriteRune('{')
-		for ch != '}' {
-			ch = s.read()
-			if ch == eof || ch == '\n' || ch == '\r' {
-				return s.position(tokILLEGAL, s.buf.String())
-			}
-			if ch == '\\' {
-				s.buf.WriteRune(ch)
-				// we possibly have an escaped character
-				ch = s.read()
-				s.buf.WriteRune(ch)
-				if ch != '{' && ch != '}' && ch != '\\' {
-					return s.position(tokILLEGAL, s.buf.String())
-				}
-				ch = s.read()
-			}
-			s.buf.WriteRune(ch)
-		}
-		return s.position(tokLABEL, s.buf.String())
+		return s.scanBraces(tokLABEL)
 	}
 
 	// Read every subsequent ident character into the buffer.
@@ -192,11 +257,10 @@ func (s *scanner) scanLabel() token {
 	// We do not accept "escaped" label names at the moment
 	for {
 		switch {
-		case isWhitespace(ch):
+		case isWhitespace(ch) || ch == '#' || ch == eof:
+			// labels end at the first space, or at the start of a comment
 			s.unread()
 			return s.position(tokLABEL, s.buf.String())
-		case ch == eof:
-			return s.position(tokILLEGAL, "EOF")
 		default:
 			s.buf.WriteRune(ch)
 		}
@@ -204,14 +268,47 @@ func (s *scanner) scanLabel() token {
 	}
 }
 
+// scanBraces reads a name (or label) between braces, when the opening brace has
+// already been read, and returns a token of the given kind. We accept any chain
+// of characters on a single line, in which characters {, }, and \ are prefixed
+// by \. We return an illegal token if the name is not terminated, for instance
+// when we reach the end of file.
+func (s *scanner) scanBraces(kind tokenKind) token {
+	s.buf.WriteRune('{')
+	for {
+		ch := s.read()
+		switch {
+		case ch == eof || ch == '\n' || ch == '\r':
+			return s.position(tokILLEGAL, s.buf.String())
+		case ch == '\\':
+			// we must have an escaped character
+			s.buf.WriteRune(ch)
+			ch = s.read()
+			if ch != '{' && ch != '}' && ch != '\\' {
+				return s.position(tokILLEGAL, s.buf.String())
+			}
+			s.buf.WriteRune(ch)
+		case ch == '}':
+			s.buf.WriteRune(ch)
+			return s.position(kind, s.buf.String())
+		default:
+			s.buf.WriteRune(ch)
+		}
+	}
+}
+
 func (s *scanner) scanMarking() token {
+	if ch := s.read(); ch != '+' {
+		// we accept markings with an explicit sign, such as (+2)
+		s.unread()
+	}
 	value := s.scanNumber(0)
 	ch := s.read()
 	switch {
 	case ch == ')':
 		return s.position(tokMARKING, value)
 	default:
-		return s.position(tokILLEGAL, string(ch))
+		return s.illegal(ch)
 	}
 }
 
@@ -230,49 +327,34 @@ func (s *scanner) scanIdent() token {
 	}
 
 	if ch == '{' {
-		// we accept any chain between braces, and in which characters {, }, and \ are prefixed by \
-		s.buf.WriteRune('{')
-		for ch != '}' {
-			ch = s.read()
-			if ch == eof || ch == '\n' || ch == '\r' {
-				return s.position(tokILLEGAL, s.buf.String())
-			}
-			if ch == '\\' {
-				s.buf.WriteRune(ch)
-				// we possibly have an escaped character
-				ch = s.read()
-				s.buf.WriteRune(ch)
-				if ch != '{' && ch != '}' && ch != '\\' {
-					return s.position(tokILLEGAL, s.buf.String())
-				}
-				ch = s.read()
-			}
-			s.buf.WriteRune(ch)
-		}
-		return s.position(tokIDENT, s.buf.String())
+		return s.scanBraces(tokIDENT)
 	}
 
-	// otherwise read the identifier and match it against reserved word
+	// otherwise read the identifier and match it against reserved word. The
+	// characters of identifiers are all ASCII, so we can write bytes.
 	for isLetter(ch) || isDigit(ch) || isIdentChar(ch) {
-		s.buf.WriteRune(ch)
+		s.buf.WriteByte(byte(ch))
 		ch = s.read()
 	}
 	s.unread()
-	switch strings.ToUpper(s.buf.String()) {
-	case "TR":
-		return s.position(tokTR, "tr")
-	case "NET":
-		return s.position(tokNET, "net")
-	case "PL":
-		return s.position(tokPL, "pl")
-	case "PR":
-		return s.position(tokPRIO, "pr")
-	case "NT":
-		return s.position(tokNOTE, "nt")
+	lit := s.buf.String()
+	if len(lit) <= 3 {
+		switch strings.ToUpper(lit) {
+		case "TR":
+			return s.position(tokTR, "tr")
+		case "NET":
+			return s.position(tokNET, "net")
+		case "PL":
+			return s.position(tokPL, "pl")
+		case "PR":
+			return s.position(tokPRIO, "pr")
+		case "NT":
+			return s.position(tokNOTE, "nt")
+		}
 	}
 
 	// If not reserved then return as a regular identifier.
-	return s.position(tokIDENT, s.buf.String())
+	return s.position(tokIDENT, lit)
 }
 
 // scanNumber scan the input for digits and return the resulting number as a
@@ -281,15 +363,15 @@ func (s *scanner) scanNumber(c rune) string {
 	// Create a buffer and read the current character into it.
 	s.buf.Reset()
 	if c != 0 {
-		s.buf.WriteRune(c)
+		s.buf.WriteByte(byte(c))
 	}
 	ch := s.read()
 	for isDigit(ch) {
-		s.buf.WriteRune(ch)
+		s.buf.WriteByte(byte(ch))
 		ch = s.read()
 	}
-	if ch == 'K' || ch == 'M' || ch == 'G' || ch == 'T' || ch == 'P' || ch == 'E' {
-		s.buf.WriteRune(ch)
+	if isMultiplier(ch) {
+		s.buf.WriteByte(byte(ch))
 		return s.buf.String()
 	}
 	s.unread()
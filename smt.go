@@ -0,0 +1,129 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteSMTReachability writes on an io.Writer an SMT-LIB2 encoding of the
+// bounded reachability problem for marking target: the resulting formula is
+// satisfiable if and only if target is reachable from the initial marking of
+// the net by firing at most steps transitions, ignoring timing constraints and
+// priorities. The problem uses integer linear arithmetic (logic QF_LIA) and
+// can be solved with tools such as Z3 or cvc5, for instance with the command
+// z3 file.smt2.
+//
+// We declare one integer variable, m_p_i, for the marking of place p after i
+// steps. Each step is either the firing of an enabled transition, using the
+// constraints in Cond and Inhib and the effect in Delta (and Reset), or a
+// stuttering step where the marking is unchanged. A transition with
+// probabilistic arcs can be fired in one way for each of its outcomes (see
+// SampleOutcome). Disabled transitions are ignored. We return an error if
+// steps is negative.
+func (net *Net) WriteSMTReachability(w io.Writer, target Marking, steps int) error {
+	if steps < 0 {
+		return fmt.Errorf("negative number of steps (%d) in SMT encoding", steps)
+	}
+	bw := bufio.NewWriter(w)
+	if net.Name != "" {
+		fmt.Fprintf(bw, "; net %s\n", net.Name)
+	}
+	fmt.Fprintf(bw, "; reachability of %s in at most %d steps\n", smtComment(net.Mtoa(target)), steps)
+	fmt.Fprint(bw, "(set-logic QF_LIA)\n")
+	for i := 0; i <= steps; i++ {
+		for p, v := range net.Pl {
+			fmt.Fprintf(bw, "(declare-const %s Int) ; %s\n", smtVar(p, i), smtComment(v))
+			fmt.Fprintf(bw, "(assert (>= %s 0))\n", smtVar(p, i))
+		}
+	}
+	// initial marking
+	init := make([]string, len(net.Pl))
+	for p := range net.Pl {
+		init[p] = fmt.Sprintf("(= %s %d)", smtVar(p, 0), net.Initial.Get(p))
+	}
+	fmt.Fprintf(bw, "(assert %s)\n", smtAnd(init))
+	// transition relation
+	for i := 0; i < steps; i++ {
+		var moves []string
+		for t := range net.Tr {
+			if net.IsDisabled(t) {
+				continue
+			}
+			for _, d := range net.outcomes(t) {
+				moves = append(moves, net.smtFire(t, d, i))
+			}
+		}
+		stutter := make([]string, len(net.Pl))
+		for p := range net.Pl {
+			stutter[p] = fmt.Sprintf("(= %s %s)", smtVar(p, i+1), smtVar(p, i))
+		}
+		moves = append(moves, smtAnd(stutter))
+		fmt.Fprintf(bw, "(assert (or\n  %s))\n", strings.Join(moves, "\n  "))
+	}
+	// target marking
+	goal := make([]string, len(net.Pl))
+	for p := range net.Pl {
+		goal[p] = fmt.Sprintf("(= %s %d)", smtVar(p, steps), target.Get(p))
+	}
+	fmt.Fprintf(bw, "(assert %s)\n", smtAnd(goal))
+	fmt.Fprint(bw, "(check-sat)\n(get-model)\n")
+	return bw.Flush()
+}
+
+// smtFire returns the constraint stating that transition t is fired at step i,
+// meaning t is enabled in the marking after i steps and the marking after i+1
+// steps is the result of firing t, with effect delta (see outcomes).
+func (net *Net) smtFire(t int, delta Marking, i int) string {
+	res := []string{}
+	for _, v := range net.Cond[t] {
+		res = append(res, fmt.Sprintf("(>= %s %d)", smtVar(v.Pl, i), v.Mult))
+	}
+	for _, v := range net.Inhib[t] {
+		res = append(res, fmt.Sprintf("(< %s %d)", smtVar(v.Pl, i), v.Mult))
+	}
+	for p := range net.Pl {
+		if setMember(net.resets(t), p) >= 0 {
+			// reset arc: the marking of p is what is produced by t
+			res = append(res, fmt.Sprintf("(= %s %d)", smtVar(p, i+1), delta.Get(p)-net.Pre[t].Get(p)))
+			continue
+		}
+		switch d := delta.Get(p); {
+		case d > 0:
+			res = append(res, fmt.Sprintf("(= %s (+ %s %d))", smtVar(p, i+1), smtVar(p, i), d))
+		case d < 0:
+			res = append(res, fmt.Sprintf("(= %s (- %s %d))", smtVar(p, i+1), smtVar(p, i), -d))
+		default:
+			res = append(res, fmt.Sprintf("(= %s %s)", smtVar(p, i+1), smtVar(p, i)))
+		}
+	}
+	return fmt.Sprintf("%s ; %s", smtAnd(res), smtComment(net.Tr[t]))
+}
+
+// smtVar returns the name of the variable for the marking of place p after i
+// steps. We use indexes since place names may contain characters that are not
+// allowed in SMT-LIB symbols.
+func smtVar(p, i int) string {
+	return fmt.Sprintf("m_%d_%d", p, i)
+}
+
+// smtAnd returns the conjunction of the constraints in c.
+func smtAnd(c []string) string {
+	switch len(c) {
+	case 0:
+		return "true"
+	case 1:
+		return c[0]
+	}
+	return "(and " + strings.Join(c, " ") + ")"
+}
+
+// smtComment removes line breaks from s so that it can be used in a comment.
+func smtComment(s string) string {
+	return strings.NewReplacer("\n", " ", "\r", " ").Replace(s)
+}
@@ -0,0 +1,37 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteSMTReachability(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1?1 p0?-1 -> p0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	var b strings.Builder
+	if err := net.WriteSMTReachability(&b, Marking{{Pl: 0, Mult: 1}, {Pl: 1, Mult: 1}}, 2); err != nil {
+		t.Fatalf("WriteSMTReachability: %s", err)
+	}
+	for _, v := range []string{
+		"(set-logic QF_LIA)",
+		"(declare-const m_1_2 Int) ; p1",
+		"(assert (and (= m_0_0 1) (= m_1_0 0)))",
+		"(and (>= m_0_0 1) (= m_0_1 (- m_0_0 1)) (= m_1_1 (+ m_1_0 1))) ; t0",
+		"(and (>= m_1_1 1) (< m_0_1 1) (= m_0_2 (+ m_0_1 1)) (= m_1_2 m_1_1)) ; t1",
+		"(assert (and (= m_0_2 1) (= m_1_2 1)))",
+		"(check-sat)",
+	} {
+		if !strings.Contains(b.String(), v) {
+			t.Errorf("WriteSMTReachability: missing %q in\n%s", v, b.String())
+		}
+	}
+	if err := net.WriteSMTReachability(&b, net.Initial, -1); err == nil {
+		t.Errorf("WriteSMTReachability: expected error with negative number of steps")
+	}
+}
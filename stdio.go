@@ -8,10 +8,13 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"slices"
 	"strconv"
+	"strings"
 )
 
-// Mtoa converts a marking into a string
+// Mtoa converts a marking into a string. Multiplicity Omega is printed as w,
+// such as in "p0 p1*w".
 func (net *Net) Mtoa(m Marking) string {
 	var buf bytes.Buffer
 	for k, v := range m {
@@ -19,7 +22,11 @@ func (net *Net) Mtoa(m Marking) string {
 			buf.WriteRune(' ')
 		}
 		buf.WriteString(net.Pl[v.Pl])
-		if v.Mult != 1 {
+		switch v.Mult {
+		case 1:
+		case Omega:
+			buf.WriteString("*w")
+		default:
 			buf.WriteRune('*')
 			buf.WriteString(strconv.Itoa(int(v.Mult)))
 		}
@@ -27,7 +34,73 @@ func (net *Net) Mtoa(m Marking) string {
 	return buf.String()
 }
 
-func (net *Net) printTransition(cond, inhibcond, inpt, delta Marking) string {
+// Atoma is the inverse of Mtoa: it converts a string, such as "p1*7 p3 p4*4",
+// into a marking of the net. Places are separated by spaces and a place name
+// can be followed by a multiplicity, written *k, where the default is 1. We
+// accept the same values than in a .net file, such as 3K, and also negative
+// multiplicities, that are printed by Mtoa, and the multiplicity w, for Omega,
+// as in the markings of a coverability graph. When a place appears several
+// times, multiplicities are added. We return an error if a place is not in the
+// net or if a multiplicity is not valid.
+func (net *Net) Atoma(s string) (Marking, error) {
+	var res Marking
+	for {
+		s = strings.TrimLeft(s, " \t\n\r")
+		if len(s) == 0 {
+			break
+		}
+		// the end of the current item, taking into account names between
+		// braces that may contain spaces
+		end, inbraces := 0, false
+		for ; end < len(s); end++ {
+			c := s[end]
+			if c == '\\' && inbraces {
+				end++
+				continue
+			}
+			if c == '{' {
+				inbraces = true
+			}
+			if c == '}' {
+				inbraces = false
+			}
+			if !inbraces && (c == ' ' || c == '\t' || c == '\n' || c == '\r') {
+				break
+			}
+		}
+		item := s[:end]
+		s = s[end:]
+		name, mult := item, 1
+		if k := strings.LastIndexByte(item, '*'); k >= 0 && !strings.Contains(item[k:], "}") {
+			name, mult = item[:k], Omega
+			if item[k+1:] != "w" {
+				v, err := mconvert(item[k+1:])
+				if err != nil {
+					return nil, fmt.Errorf("bad multiplicity in %q; %s", item, err)
+				}
+				mult = v
+			}
+		}
+		p, ok := net.PlaceIndex(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown place %q in marking", name)
+		}
+		switch {
+		case res.Get(p) == Omega:
+			// ω plus any value is still ω
+		case mult == Omega:
+			res = res.updateIfGreater(p, Omega)
+		default:
+			res = res.AddToPlace(p, mult)
+		}
+	}
+	if res == nil {
+		res = Marking{}
+	}
+	return res, nil
+}
+
+func (net *Net) printTransition(cond, inhibcond, inpt, delta Marking, prob []ProbArc, reset []int) string {
 	var left, right bytes.Buffer
 	for p, pname := range net.Pl {
 		inp := inpt.Get(p)
@@ -47,17 +120,46 @@ func (net *Net) printTransition(cond, inhibcond, inpt, delta Marking) string {
 		if inhibp := inhibcond.Get(p); inhibp != 0 {
 			fmt.Fprintf(&left, " %s?-%d", pname, inhibp)
 		}
-		if readp := cond.Get(p) + inp; readp != 0 {
+		if readp := cond.Get(p); readp > -inp {
+			// the condition is stronger than what we consume, which means we
+			// have a read arc.
 			fmt.Fprintf(&left, " %s?%d", pname, readp)
 		}
+		if setMember(reset, p) >= 0 {
+			fmt.Fprintf(&left, " %s?!", pname)
+		}
+	}
+	for _, a := range prob {
+		fmt.Fprintf(&right, " %s", net.Pl[a.Pl])
+		if a.Mult != 1 {
+			fmt.Fprintf(&right, "*%d", a.Mult)
+		}
+		fmt.Fprintf(&right, "%%%s", strconv.FormatFloat(a.Prob, 'f', -1, 64))
 	}
 	return fmt.Sprintf("%s ->%s\n", left.String(), right.String())
 }
 
-// FPrint formats the net structure and writes it to w.
+// FPrint formats the net structure and writes it to w. Places and transitions
+// are printed in their order of declaration, followed by priorities, in the
+// canonical form "pr t > t1 ... tn" with the ti in increasing order of index,
+// and notes. Hence, when the net has no disabled transitions, parsing the
+// result gives back a net that is Equal to the original one. Probabilistic
+// arcs are printed too, so the result must be parsed with option
+// Probabilities of ParseOptions when the net has some.
+//
+// Some information is lost in the process. Comments in the original file are
+// dropped, and so are the extensions enabled with ParseOptions, such as named
+// markings and weights, and the server kinds set without a server note.
+// Transitions that have been disabled are printed as comments, which means
+// that they are dropped when parsing the result (unless they appear in a
+// priority declaration, in which case they are declared again but without
+// arcs), and that the result is not Equal to the original net.
 func (net *Net) Fprint(w io.Writer) {
 	fmt.Fprintf(w, "#\n# net %s\n", net.Name)
 	fmt.Fprintf(w, "# %d places, %d transitions\n#\n\n", len(net.Pl), len(net.Tr))
+	if net.Name != "" {
+		fmt.Fprintf(w, "net %s\n", net.Name)
+	}
 
 	for k, v := range net.Pl {
 		fmt.Fprintf(w, "pl %s", v)
@@ -70,6 +172,10 @@ func (net *Net) Fprint(w io.Writer) {
 		fmt.Fprint(w, "\n")
 	}
 	for k, v := range net.Tr {
+		if net.IsDisabled(k) {
+			// disabled transitions are commented out
+			fmt.Fprint(w, "# ")
+		}
 		fmt.Fprintf(w, "tr %s ", v)
 		if net.Tlabel[k] != "" {
 			fmt.Fprintf(w, ": %s ", net.Tlabel[k])
@@ -80,17 +186,40 @@ func (net *Net) Fprint(w io.Writer) {
 		fmt.Fprint(w, net.printTransition(net.Cond[k],
 			net.Inhib[k],
 			net.Pre[k],
-			net.Delta[k]))
+			net.Delta[k],
+			net.probArcs(k),
+			net.resets(k)))
 	}
 	for k, v := range net.Prio {
 		if len(v) != 0 {
 			fmt.Fprintf(w, "pr %s >", net.Tr[k])
-			for _, t := range v {
+			for _, t := range prioSet(v) {
 				fmt.Fprintf(w, " %s", net.Tr[t])
 			}
 			fmt.Fprintf(w, "\n")
 		}
 	}
+	for _, v := range net.Notes {
+		fmt.Fprintf(w, "nt %s %d %s\n", v.Name, v.Flag, v.Body)
+	}
+}
+
+// FprintSorted is like Fprint but lists places and transitions in
+// lexicographic order of their names, instead of their order of declaration.
+// Arcs, priorities and probabilistic arcs are printed following this order
+// too, so that two nets that differ only by the order of their declarations
+// have the same output. Nodes with the same name keep their relative order.
+// This is useful to compare nets using textual diffs. The net is not modified.
+func (net *Net) FprintSorted(w io.Writer) {
+	order := func(names []string) []int {
+		res := make([]int, len(names))
+		for k := range res {
+			res[k] = k
+		}
+		slices.SortStableFunc(res, func(a, b int) int { return strings.Compare(names[a], names[b]) })
+		return res
+	}
+	net.permute(order(net.Pl), order(net.Tr)).Fprint(w)
 }
 
 // String returns a textual representation of the net structure.
@@ -99,3 +228,92 @@ func (net *Net) String() string {
 	net.Fprint(&buf)
 	return buf.String()
 }
+
+// SelfCheck prints the net using Fprint, parses the result, and checks that we
+// obtain a net equal to the original one. We return an error describing the
+// first difference found if the round-trip diverges. This is useful to check
+// that a net obtained after some transformations is still well-formed. Since
+// Fprint comments out disabled transitions, the check always fails on nets
+// with disabled transitions.
+func (net *Net) SelfCheck() error {
+	net2, err := ParseWithOptions(strings.NewReader(net.String()), ParseOptions{Probabilities: true})
+	if err != nil {
+		return fmt.Errorf("self check failed, cannot parse output of Fprint: %s", err)
+	}
+	if err := net.compare(net2); err != nil {
+		return fmt.Errorf("self check failed, %s", err)
+	}
+	return nil
+}
+
+// WriteScenario writes a firing sequence, given as a list of transition
+// indexes, in the textual format of scenario files (.scn) used by the stepper
+// simulator of Tina. A scenario is the list of the names of the transitions
+// fired, separated by spaces. We return an error if one of the indexes is not
+// a valid transition.
+func (net *Net) WriteScenario(w io.Writer, seq []int) error {
+	names := make([]string, len(seq))
+	for k, t := range seq {
+		if t < 0 || t >= len(net.Tr) {
+			return fmt.Errorf("invalid transition index %d at position %d in scenario", t, k)
+		}
+		names[k] = net.Tr[t]
+	}
+	_, err := fmt.Fprintln(w, strings.Join(names, " "))
+	return err
+}
+
+// SimplifyNames removes the braces around the names of places and transitions
+// that do not need them, for instance {p1} becomes p1. A name can be left
+// unquoted when it is a valid identifier for the scanner: it starts with a
+// letter, contains only letters, digits, and the characters _, ' and ., and is
+// not a keyword of the .net format. We keep the braces when the simplified
+// name would clash with the name of another node of the same kind. Colors
+// associated with renamed nodes in NodeColor are updated accordingly.
+func (net *Net) SimplifyNames() {
+	simplify := func(names []string) {
+		used := make(map[string]bool, len(names))
+		for _, v := range names {
+			used[v] = true
+		}
+		for k, v := range names {
+			s, ok := unquoteName(v)
+			if !ok || used[s] {
+				continue
+			}
+			delete(used, v)
+			used[s] = true
+			names[k] = s
+			if c, ok := net.NodeColor[v]; ok {
+				delete(net.NodeColor, v)
+				net.NodeColor[s] = c
+			}
+		}
+	}
+	simplify(net.Pl)
+	simplify(net.Tr)
+}
+
+// unquoteName returns the name s without its enclosing braces and true when
+// the result is a valid identifier that does not need quoting.
+func unquoteName(s string) (string, bool) {
+	if len(s) < 3 || s[0] != '{' || s[len(s)-1] != '}' {
+		return s, false
+	}
+	s = s[1 : len(s)-1]
+	for k, ch := range s {
+		switch {
+		case ch == '{' || ch == '}':
+			return s, false
+		case isLetter(ch):
+		case k > 0 && (isDigit(ch) || isIdentChar(ch)):
+		default:
+			return s, false
+		}
+	}
+	switch strings.ToLower(s) {
+	case "tr", "pl", "net", "pr", "nt", "mk", "wt":
+		return s, false
+	}
+	return s, true
+}
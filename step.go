@@ -0,0 +1,214 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ServerKind describes how many instances of a transition can fire at the same
+// time, in a step, when the transition is enabled several times. This is used
+// in performance models where a transition represents a station with several
+// servers. The default value, SingleServer, means that a transition fires at
+// most once in a step; a positive value k means that at most k instances can
+// fire; and InfiniteServer means that a transition can fire up to its enabling
+// degree.
+type ServerKind int
+
+const (
+	SingleServer   ServerKind = 0
+	InfiniteServer ServerKind = -1
+)
+
+func (s ServerKind) String() string {
+	switch {
+	case s == InfiniteServer:
+		return "infinite"
+	case s <= 1:
+		return "single"
+	default:
+		return strconv.Itoa(int(s))
+	}
+}
+
+// servers returns the number of instances of a transition, with enabling
+// degree d, that can fire in a step.
+func (s ServerKind) servers(d int) int {
+	switch {
+	case s == InfiniteServer:
+		return d
+	case s <= 1:
+		return min(d, 1)
+	default:
+		return min(d, int(s))
+	}
+}
+
+// parseServerKind returns the server kind described in a server note, that is
+// either single, infinite, or a positive integer.
+func parseServerKind(s string) (ServerKind, error) {
+	switch s {
+	case "single":
+		return SingleServer, nil
+	case "infinite":
+		return InfiniteServer, nil
+	}
+	k, err := strconv.Atoi(s)
+	if err != nil || k < 1 {
+		return SingleServer, fmt.Errorf("not a valid server kind, %s", s)
+	}
+	return ServerKind(k), nil
+}
+
+// Server returns the server kind of transition t.
+func (net *Net) Server(t int) ServerKind {
+	if t < len(net.ServerKind) {
+		return net.ServerKind[t]
+	}
+	return SingleServer
+}
+
+// SetServer sets the server kind of transition t.
+func (net *Net) SetServer(t int, s ServerKind) {
+	for len(net.ServerKind) < len(net.Tr) {
+		net.ServerKind = append(net.ServerKind, SingleServer)
+	}
+	net.ServerKind[t] = s
+}
+
+// EnablingDegree returns the number of times that transition t can fire
+// concurrently with itself at marking m, meaning the largest k such that the
+// tokens consumed by k instances of t are available, without counting the
+// tokens that are only tested (read arcs) more than once. We return 0 when t is
+// not enabled and 1 when t is enabled but does not consume any token.
+func (net *Net) EnablingDegree(m Marking, t int) int {
+	if !net.IsEnabled(m, t) {
+		return 0
+	}
+	return net.degree(m, t)
+}
+
+// degree returns the enabling degree of t at m, without testing inhibitor arcs
+// and assuming that t is enabled. For a place p consumed by t, we need
+// m(p) >= (k-1) * pre(p) + cond(p) in order to fire k instances of t.
+func (net *Net) degree(m Marking, t int) int {
+	d := -1
+	for _, a := range net.Pre[t] {
+		pre := -a.Mult
+		if pre <= 0 {
+			continue
+		}
+		k := (m.Get(a.Pl)-net.Cond[t].Get(a.Pl))/pre + 1
+		if d == -1 || k < d {
+			d = k
+		}
+	}
+	if d == -1 {
+		return 1
+	}
+	return max(d, 0)
+}
+
+// FireMaximalStep fires a maximal step of transitions at marking m, meaning a
+// multiset of transitions that can fire concurrently and that cannot be
+// extended with another transition. We return the resulting marking and the
+// step, where step[t] is the number of instances of transition t fired. The
+// number of instances of a transition is bounded by its server kind (see
+// ServerKind). We use a greedy strategy, where transitions are considered in
+// increasing order of index and take as many tokens as possible, so we only
+// compute one of the possible maximal steps. Transitions enabled at m are
+// considered with the tokens that are not consumed by the previous
+// transitions in the step, whereas inhibitor arcs are checked against m. Reset
+// arcs empty the remaining tokens of a place, so that the next transitions
+// cannot use them. We do not take into account timing constraints and
+// priorities, and, like with Fire, we ignore probabilistic arcs.
+func (net *Net) FireMaximalStep(m Marking) (Marking, []int) {
+	step := make([]int, len(net.Tr))
+	remaining := m.Clone()
+	produced := Marking{}
+	for t := range net.Tr {
+		if !net.IsEnabled(m, t) || !net.condHolds(remaining, t) {
+			continue
+		}
+		n := net.Server(t).servers(net.degree(remaining, t))
+		if n == 0 {
+			continue
+		}
+		step[t] = n
+		for _, a := range net.Pre[t] {
+			remaining = remaining.Add(Marking{Atom{a.Pl, n * a.Mult}})
+		}
+		for _, p := range net.resets(t) {
+			// reset arcs remove all the tokens left after consumption
+			remaining = remaining.AddToPlace(p, -remaining.Get(p))
+		}
+		for _, a := range net.post(t) {
+			produced = produced.Add(Marking{Atom{a.Pl, n * a.Mult}})
+		}
+	}
+	return remaining.Add(produced), step
+}
+
+// condHolds reports whether marking m satisfies the enabling condition of t,
+// without checking inhibitor arcs.
+func (net *Net) condHolds(m Marking, t int) bool {
+	for _, v := range net.Cond[t] {
+		if m.Get(v.Pl) < v.Mult {
+			return false
+		}
+	}
+	return true
+}
+
+// post returns the marking of the tokens produced by transition t, meaning
+// Delta minus Pre.
+func (net *Net) post(t int) Marking {
+	res := net.Delta[t]
+	for _, a := range net.Pre[t] {
+		res = res.Add(Marking{Atom{a.Pl, -a.Mult}})
+	}
+	return res
+}
+
+// AutoConcurrencyLimited returns the transitions that can never fire
+// concurrently with themselves, meaning that their enabling degree is at most
+// 1 in every reachable marking. We use the bounds on the marking of places
+// derived from the place invariants of the net (see EstimateStateSpace): a
+// transition is in the result when it consumes tokens from a bounded place
+// that can never hold enough tokens for two instances of the transition. Hence
+// the result is an under-approximation; other transitions may also be limited
+// to one instance in practice. We return nil if we fail to compute the
+// invariants.
+func (net *Net) AutoConcurrencyLimited() []int {
+	bounds, err := net.placeBounds()
+	if err != nil {
+		return nil
+	}
+	m := Marking{}
+	for p, b := range bounds {
+		if b < 0 {
+			// the marking of p is not bounded by an invariant
+			b = math.MaxInt32
+		}
+		if b != 0 {
+			m = append(m, Atom{Pl: p, Mult: b})
+		}
+	}
+	res := []int{}
+	for t := range net.Tr {
+		bounded := false
+		for _, a := range net.Pre[t] {
+			if a.Mult < 0 && bounds[a.Pl] >= 0 {
+				bounded = true
+			}
+		}
+		if bounded && net.degree(m, t) < 2 {
+			res = append(res, t)
+		}
+	}
+	return res
+}
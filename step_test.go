@@ -0,0 +1,64 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestFireMaximalStep(t *testing.T) {
+	input := `pl queue (5)
+pl q2 (5)
+tr serve queue -> done
+tr serve2 q2 -> done2
+tr serve3 q2 -> done3
+nt server 0 {serve infinite}
+nt server 0 {serve2 2}
+`
+	net, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if k := net.Server(0); k != InfiniteServer {
+		t.Errorf("Wrong server kind for serve, expected infinite, actual %s", k)
+	}
+	if d := net.EnablingDegree(net.Initial, 0); d != 5 {
+		t.Errorf("EnablingDegree: expected 5, actual %d", d)
+	}
+	_, err = Parse(strings.NewReader(input + "nt server 0 {serve4 infinite}\n"))
+	var perr *ParseError
+	if !errors.As(err, &perr) || perr.Line != 8 || !strings.Contains(err.Error(), "unknown transition serve4") {
+		t.Errorf("Parse: expected error on unknown transition in server note, actual %v", err)
+	}
+	m, step := net.FireMaximalStep(net.Initial)
+	if !slices.Equal(step, []int{5, 2, 1}) {
+		t.Errorf("FireMaximalStep: expected step [5 2 1], actual %v", step)
+	}
+	if actual := net.Mtoa(m); actual != "q2*2 done*5 done2*2 done3" {
+		t.Errorf("FireMaximalStep: unexpected marking %s", actual)
+	}
+}
+
+func TestAutoConcurrencyLimited(t *testing.T) {
+	// p0 + p1 = 3 and q0 + q1 = 1, while r is unbounded
+	net, err := Parse(strings.NewReader(`pl p0 (3)
+pl q0 (1)
+tr t0 p0*2 -> p1*2
+tr t1 p1 -> p0
+tr t2 q0 -> q1
+tr t3 q1 -> q0 r
+tr t4 r -> 
+tr t5 p0?2 -> 
+`))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if actual := net.AutoConcurrencyLimited(); !slices.Equal(actual, []int{0, 2, 3}) {
+		t.Errorf("AutoConcurrencyLimited: expected [0 2 3], actual %v", actual)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"math/rand"
+	"slices"
+)
+
+// TransitionWeight returns the weight of transition t, used in the stochastic
+// interpretation of the net. The default weight is 1.
+func (net *Net) TransitionWeight(t int) float64 {
+	if t < len(net.Weight) {
+		return net.Weight[t]
+	}
+	return 1
+}
+
+// SetWeight sets the weight of transition t. Weights should be positive.
+func (net *Net) SetWeight(t int, w float64) {
+	for len(net.Weight) < len(net.Tr) {
+		net.Weight = append(net.Weight, 1)
+	}
+	net.Weight[t] = w
+}
+
+// SampleTransition picks a transition among those enabled at marking m, with a
+// probability proportional to their weight, using rng as source of
+// randomness. This corresponds to a race between enabled transitions, like in
+// Generalized Stochastic Petri Nets, where we ignore timing constraints. We
+// return false if no transition with a positive weight is enabled at m.
+func (net *Net) SampleTransition(m Marking, rng *rand.Rand) (int, bool) {
+	enabled := net.AllEnabled(m)
+	total := 0.0
+	for _, t := range enabled {
+		if w := net.TransitionWeight(t); w > 0 {
+			total += w
+		}
+	}
+	if total == 0 {
+		return 0, false
+	}
+	r := rng.Float64() * total
+	last := 0
+	for _, t := range enabled {
+		w := net.TransitionWeight(t)
+		if w <= 0 {
+			continue
+		}
+		if r < w {
+			return t, true
+		}
+		r -= w
+		last = t
+	}
+	// we can only reach this point because of rounding errors
+	return last, true
+}
+
+// ProbArc is an output arc of a transition that is annotated with a
+// probability, such as p*2%0.3 in a .net file.
+type ProbArc struct {
+	Pl   int     // index of the output place
+	Mult int     // weight of the arc
+	Prob float64 // probability of the branch, between 0 and 1
+}
+
+// probArcs returns the probabilistic output arcs of transition t.
+func (net *Net) probArcs(t int) []ProbArc {
+	if t < len(net.ArcProb) {
+		return net.ArcProb[t]
+	}
+	return nil
+}
+
+// addProbArc adds a probabilistic output arc to transition t.
+func (net *Net) addProbArc(t int, a ProbArc) {
+	for len(net.ArcProb) <= t {
+		net.ArcProb = append(net.ArcProb, nil)
+	}
+	net.ArcProb[t] = append(net.ArcProb[t], a)
+}
+
+// SampleOutcome returns the effect of firing transition t, meaning the marking
+// that should be added to the current marking, when we choose randomly among
+// its probabilistic output arcs. Each annotated arc is an alternative branch:
+// when t fires, we produce the tokens of exactly one of these arcs, chosen
+// with the given probability, or of none of them when the probabilities sum to
+// less than 1. Tokens on output arcs without annotation, that are accounted
+// for in Delta[t], are always produced. The result is Delta[t] when t has no
+// probabilistic arcs.
+func (net *Net) SampleOutcome(t int, rng *rand.Rand) Marking {
+	arcs := net.probArcs(t)
+	res := net.Delta[t]
+	if len(arcs) == 0 {
+		return res
+	}
+	r := rng.Float64()
+	for _, a := range arcs {
+		if r < a.Prob {
+			return res.AddToPlace(a.Pl, a.Mult)
+		}
+		r -= a.Prob
+	}
+	return res
+}
+
+// outcomes returns the possible effects of firing transition t, meaning the
+// markings that SampleOutcome can return with a positive probability, without
+// duplicates. The result is just Delta[t] when t has no probabilistic arcs.
+// Analyses that must be sound for every run of the net, such as the
+// exploration of the reachable markings, consider all of them.
+func (net *Net) outcomes(t int) []Marking {
+	arcs := net.probArcs(t)
+	if len(arcs) == 0 {
+		return []Marking{net.Delta[t]}
+	}
+	res := []Marking{}
+	add := func(m Marking) {
+		if !slices.ContainsFunc(res, m.Equal) {
+			res = append(res, m)
+		}
+	}
+	total := 0.0
+	for _, a := range arcs {
+		if a.Prob > 0 {
+			add(net.Delta[t].AddToPlace(a.Pl, a.Mult))
+		}
+		total += a.Prob
+	}
+	if total < 1-1e-9 {
+		// no branch is chosen with probability 1 - total
+		add(net.Delta[t])
+	}
+	return res
+}
+
+// Simulate plays the token game on the net, ignoring timing constraints,
+// priorities and weights. Starting from the initial marking, we pick at each
+// step a transition uniformly at random among those enabled (see AllEnabled)
+// and fire it. We stop after the given number of steps, or earlier if we reach
+// a deadlock, and return the sequence of transitions fired. The branch taken
+// by a transition with probabilistic arcs is chosen like with SampleOutcome.
+// The run only depends on seed, so that it can be reproduced.
+func (net *Net) Simulate(steps int, seed int64) []int {
+	seq, _ := net.simulate(steps, seed, false)
+	return seq
+}
+
+// SimulateTrace is like Simulate but also returns the markings visited during
+// the run, starting with the initial marking. Hence the k-th marking is the
+// one reached after firing the first k transitions in the sequence, and there
+// is one more marking than transitions.
+func (net *Net) SimulateTrace(steps int, seed int64) ([]int, []Marking) {
+	return net.simulate(steps, seed, true)
+}
+
+func (net *Net) simulate(steps int, seed int64, trace bool) ([]int, []Marking) {
+	rng := rand.New(rand.NewSource(seed))
+	seq := []int{}
+	m := net.Initial
+	var markings []Marking
+	if trace {
+		markings = append(markings, m)
+	}
+	for k := 0; k < steps; k++ {
+		enabled := net.AllEnabled(m)
+		if len(enabled) == 0 {
+			break
+		}
+		t := enabled[rng.Intn(len(enabled))]
+		d := net.SampleOutcome(t, rng)
+		m = net.applyResets(m.Add(d), t, d)
+		seq = append(seq, t)
+		if trace {
+			markings = append(markings, m)
+		}
+	}
+	return seq, markings
+}
@@ -0,0 +1,194 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"io"
+	"math/rand"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSampleTransition(t *testing.T) {
+	input := "pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p0 -> p2\ntr t2 p1 -> p0\nwt t0 0.75\nwt t1 0.25\nwt t2 0\n"
+	net, err := ParseWithOptions(strings.NewReader(input), ParseOptions{Weights: true})
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if w := net.TransitionWeight(0); w != 0.75 {
+		t.Errorf("Wrong weight for t0, expected 0.75, actual %v", w)
+	}
+	// decimal values are only scanned in weights, so other numbers with a
+	// decimal point are reported as malformed integers
+	for _, v := range []struct{ input, msg string }{
+		{"nt n 1.5 {x}\n", `found "."`},
+		{"tr t0 p0 -> p1 2.5\n", `found "2"`},
+		{"wt t0 0.5 1.5\n", `found "1"`},
+	} {
+		if _, err := ParseWithOptions(strings.NewReader(v.input), ParseOptions{Weights: true}); err == nil || !strings.Contains(err.Error(), v.msg) {
+			t.Errorf("Parse(%q): expected error with %s, actual %v", v.input, v.msg, err)
+		}
+	}
+	rng := rand.New(rand.NewSource(1))
+	count := make([]int, 3)
+	for range 1000 {
+		tr, ok := net.SampleTransition(net.Initial, rng)
+		if !ok {
+			t.Fatalf("SampleTransition: no transition enabled")
+		}
+		count[tr]++
+	}
+	if count[2] != 0 || count[0] < 650 || count[0] > 850 {
+		t.Errorf("SampleTransition: unexpected distribution %v", count)
+	}
+	if _, ok := net.SampleTransition(Marking{Atom{1, 1}}, rng); ok {
+		t.Errorf("SampleTransition: t2 has a null weight and should never be chosen")
+	}
+}
+
+func TestSampleOutcome(t *testing.T) {
+	opts := ParseOptions{Probabilities: true}
+	net, err := ParseWithOptions(strings.NewReader("pl p0 (1)\ntr t0 p0 -> p1%0.25 p2*2%0.75 p3\ntr t1 p1 -> p0\n"), opts)
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	expected := []ProbArc{{Pl: 1, Mult: 1, Prob: 0.25}, {Pl: 2, Mult: 2, Prob: 0.75}}
+	if !slices.Equal(net.probArcs(0), expected) {
+		t.Errorf("Wrong probabilistic arcs, expected %v, actual %v", expected, net.probArcs(0))
+	}
+	if !strings.Contains(net.String(), "-> p3 p1%0.25 p2*2%0.75") {
+		t.Errorf("Wrong output for probabilistic arcs:\n%s", net)
+	}
+	if err := net.SelfCheck(); err != nil {
+		t.Errorf("SelfCheck: %s", err)
+	}
+	// probabilistic arcs are not part of Delta, so Fire never produces the
+	// tokens of several branches
+	if s := net.Mtoa(net.Delta[0]); s != "p0*-1 p3" {
+		t.Errorf("Delta should not include probabilistic arcs, actual %s", s)
+	}
+	rng := rand.New(rand.NewSource(1))
+	count := make(map[string]int)
+	for range 1000 {
+		count[net.Mtoa(net.SampleOutcome(0, rng))]++
+	}
+	if len(count) != 2 || count["p0*-1 p1 p3"] < 150 || count["p0*-1 p1 p3"] > 350 || count["p0*-1 p2*2 p3"] < 650 {
+		t.Errorf("SampleOutcome: unexpected distribution %v", count)
+	}
+	if m := net.SampleOutcome(1, rng); !m.Equal(net.Delta[1]) {
+		t.Errorf("SampleOutcome: expected %s, actual %s", net.Mtoa(net.Delta[1]), net.Mtoa(m))
+	}
+	for _, v := range []string{
+		"tr t0 p0 -> p1%1.5\n",
+		"tr t0 p0 -> p1%0.5 p2%0.6\n",
+		"tr t0 p0%0.5 -> p1\n",
+	} {
+		if _, err := ParseWithOptions(strings.NewReader(v), opts); err == nil {
+			t.Errorf("Parse(%q): expected error", v)
+		}
+	}
+	if _, err := Parse(strings.NewReader("tr t0 p0 -> p1%0.5\n")); err == nil || !strings.Contains(err.Error(), "option Probabilities") {
+		t.Errorf("Parse: probabilistic arcs should need option Probabilities, actual %v", err)
+	}
+}
+
+func TestSimulate(t *testing.T) {
+	net, err := ParseFile("testdata/abp.net")
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	seq := net.Simulate(100, 42)
+	if len(seq) != 100 {
+		t.Fatalf("Simulate: expected 100 steps, got %d", len(seq))
+	}
+	if !slices.Equal(seq, net.Simulate(100, 42)) {
+		t.Errorf("Simulate: two runs with the same seed should be equal")
+	}
+	if ok, k := net.SequenceEnabled(net.Initial, seq); !ok {
+		t.Errorf("Simulate: transition %s at position %d is not enabled", net.Tr[seq[k]], k)
+	}
+	seq2, markings := net.SimulateTrace(100, 42)
+	if !slices.Equal(seq, seq2) || len(markings) != len(seq)+1 {
+		t.Fatalf("SimulateTrace: expected the same run than Simulate with %d markings", len(seq)+1)
+	}
+	for k, tr := range seq {
+		if m, ok := net.Fire(markings[k], tr); !ok || !m.Equal(markings[k+1]) {
+			t.Errorf("SimulateTrace: wrong marking after step %d", k)
+		}
+	}
+	net, err = Parse(strings.NewReader("pl p0 (2)\ntr t0 p0 -> p1\ntr t1 p0 -> p2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	seq, markings = net.SimulateTrace(10, 1)
+	if len(seq) != 2 || len(markings) != 3 || len(net.AllEnabled(markings[2])) != 0 {
+		t.Errorf("SimulateTrace: expected to stop on a deadlock after 2 steps, got %v", seq)
+	}
+}
+
+func TestProbArcsAnalyses(t *testing.T) {
+	opts := ParseOptions{Probabilities: true}
+	// place p can hold an unbounded number of tokens
+	net, err := ParseWithOptions(strings.NewReader("tr t -> p%1.0\n"), opts)
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if net.StructurallyBounded() || net.IsConservative() {
+		t.Errorf("StructurallyBounded, IsConservative: the net is unbounded")
+	}
+	if ok, err := net.CoverableUpperBound(Marking{Atom{0, 1}}); err != nil || !ok {
+		t.Errorf("CoverableUpperBound: place p is coverable, actual %v, %v", ok, err)
+	}
+	if _, err := net.Reachable(100); err == nil {
+		t.Errorf("Reachable: expected error on an unbounded net")
+	}
+	if _, err := net.IncidenceMatrix(); err == nil {
+		t.Errorf("IncidenceMatrix: expected error with probabilistic arcs")
+	}
+	if err := net.Pnml(io.Discard); err == nil {
+		t.Errorf("Pnml: expected error with probabilistic arcs")
+	}
+	if s := net.Stats(); s.Arcs != 1 || s.MaxArcWeight != 1 {
+		t.Errorf("Stats: expected 1 arc, actual %+v", s)
+	}
+	// every branch is explored, and the tokens are preserved by all of them
+	net, err = ParseWithOptions(strings.NewReader("pl p0 (1)\ntr t p0 -> p1%0.5 p2*2%0.5\n"), opts)
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	markings, err := net.Reachable(100)
+	if err != nil {
+		t.Fatalf("Error in Reachable; %s", err)
+	}
+	actual := []string{}
+	for _, m := range markings {
+		actual = append(actual, net.Mtoa(m))
+	}
+	if expected := []string{"p0", "p1", "p2*2"}; !slices.Equal(actual, expected) {
+		t.Errorf("Reachable: expected %v, actual %v", expected, actual)
+	}
+	if inv, err := net.PInvariants(); err != nil || !slices.EqualFunc(inv, [][]int{{2, 2, 1}}, slices.Equal) {
+		t.Errorf("PInvariants: expected [[2 2 1]], actual %v, %v", inv, err)
+	}
+	if !net.StructurallyBounded() || !net.IsConservative() {
+		t.Errorf("StructurallyBounded, IsConservative: the net is conservative")
+	}
+	if ok, _ := net.IsOneSafe(100); ok {
+		t.Errorf("IsOneSafe: place p2 can hold two tokens")
+	}
+	var buf strings.Builder
+	if err := net.Dot(&buf); err != nil || !strings.Contains(buf.String(), `tr_0 -> pl_2 [label="2%0.5", style=dashed];`) {
+		t.Errorf("Dot: missing probabilistic arc in\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := net.WriteGraphML(&buf); err != nil || !strings.Contains(buf.String(), `<data key="probability">0.5</data>`) {
+		t.Errorf("WriteGraphML: missing probabilistic arc in\n%s", buf.String())
+	}
+	buf.Reset()
+	if err := net.WriteSMTReachability(&buf, Marking{Atom{2, 2}}, 1); err != nil || strings.Count(buf.String(), "; t") != 2 {
+		t.Errorf("WriteSMTReachability: expected one move for each branch in\n%s", buf.String())
+	}
+}
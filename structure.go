@@ -0,0 +1,403 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// arcKind is the type of arcs between places and transitions, as they are
+// written in a .net file.
+type arcKind uint8
+
+const (
+	arcIn      arcKind = iota // normal arc from a place to a transition
+	arcOut                    // normal arc from a transition to a place
+	arcRead                   // read (test) arc
+	arcInhibit                // inhibitor arc
+	arcReset                  // reset arc
+)
+
+func (k arcKind) String() string {
+	switch k {
+	case arcIn, arcOut:
+		return "normal"
+	case arcRead:
+		return "read"
+	case arcReset:
+		return "reset"
+	default:
+		return "inhibitor"
+	}
+}
+
+// forEachArc calls fn on every arc of transition t, in increasing order of
+// places, with the place index, the kind of arc, and its weight. Arc weights
+// are reconstructed from Pre, Delta, Cond and Inhib in the same way than when
+// printing the net. Reset arcs have weight 0.
+func (net *Net) forEachArc(t int, fn func(p int, kind arcKind, w int)) {
+	for p := range net.Pl {
+		inp := -net.Pre[t].Get(p)
+		if inp > 0 {
+			fn(p, arcIn, inp)
+		}
+		if outp := net.Delta[t].Get(p) + inp; outp > 0 {
+			fn(p, arcOut, outp)
+		}
+		if readp := net.Cond[t].Get(p); readp > inp {
+			fn(p, arcRead, readp)
+		}
+		if inhibp := net.Inhib[t].Get(p); inhibp != 0 {
+			fn(p, arcInhibit, inhibp)
+		}
+		if setMember(net.resets(t), p) >= 0 {
+			fn(p, arcReset, 0)
+		}
+	}
+}
+
+// IsolatedNodes returns the (sorted) list of places and transitions that have
+// no arcs at all. A place is isolated when no transition tests, inhibits,
+// consumes or produces tokens in it. Isolated nodes are legal but often the
+// sign of a node declared by mistake, for instance when a transition only
+// appears in a priority declaration.
+func (net *Net) IsolatedNodes() (places, transitions []int) {
+	connected := make([]bool, len(net.Pl))
+	for t := range net.Tr {
+		arcs := false
+		for _, m := range []Marking{net.Cond[t], net.Inhib[t], net.Pre[t], net.Delta[t]} {
+			for _, a := range m {
+				connected[a.Pl] = true
+				arcs = true
+			}
+		}
+		for _, p := range net.resets(t) {
+			connected[p] = true
+			arcs = true
+		}
+		for _, a := range net.probArcs(t) {
+			connected[a.Pl] = true
+			arcs = true
+		}
+		if !arcs {
+			transitions = append(transitions, t)
+		}
+	}
+	for p, ok := range connected {
+		if !ok {
+			places = append(places, p)
+		}
+	}
+	return places, transitions
+}
+
+// AsVASS returns a view of the net as a Vector Addition System. The result is
+// a pair of len(Tr) × len(Pl) matrices: guards[t][p] is the minimal number of
+// tokens required in place p for transition t to be enabled (including read
+// arcs, from net.Cond) and updates[t][p] is the change in the marking of p when
+// t fires (from net.Delta). This projection ignores inhibitor and reset arcs,
+// timing constraints and priorities. We return an error if the net is not
+// well-formed, or if a transition has probabilistic arcs, since the effect of
+// firing it cannot be given by a single vector.
+func (net *Net) AsVASS() (guards, updates [][]int, err error) {
+	if err := net.checkConsistency(); err != nil {
+		return nil, nil, err
+	}
+	for t := range net.Tr {
+		if len(net.probArcs(t)) != 0 {
+			return nil, nil, fmt.Errorf("transition %s has probabilistic arcs", net.Tr[t])
+		}
+	}
+	guards = make([][]int, len(net.Tr))
+	updates = make([][]int, len(net.Tr))
+	for t := range net.Tr {
+		guards[t] = make([]int, len(net.Pl))
+		updates[t] = make([]int, len(net.Pl))
+		for _, a := range net.Cond[t] {
+			guards[t][a.Pl] = a.Mult
+		}
+		for _, a := range net.Delta[t] {
+			updates[t][a.Pl] = a.Mult
+		}
+	}
+	return guards, updates, nil
+}
+
+// PresetNames returns the names of the places in the pre-set of transition t,
+// meaning the places from which t consumes tokens or that are tested by a read
+// arc, in increasing order of index. Inhibitor arcs are not taken into account
+// and each place is listed once, whatever the weight of its arcs.
+func (net *Net) PresetNames(t int) []string {
+	res := []string{}
+	net.forEachArc(t, func(p int, kind arcKind, _ int) {
+		if kind != arcIn && kind != arcRead {
+			return
+		}
+		if len(res) == 0 || res[len(res)-1] != net.Pl[p] {
+			res = append(res, net.Pl[p])
+		}
+	})
+	return res
+}
+
+// PostsetNames returns the names of the places in the post-set of transition
+// t, meaning the places in which t produces tokens, in increasing order of
+// index. A place consumed and produced by t (a self-loop) appears in both the
+// pre-set and the post-set of t.
+func (net *Net) PostsetNames(t int) []string {
+	res := []string{}
+	net.forEachArc(t, func(p int, kind arcKind, _ int) {
+		if kind == arcOut {
+			res = append(res, net.Pl[p])
+		}
+	})
+	return res
+}
+
+// ArcWeight returns the weight of the normal arc from place p to transition t,
+// pre, and of the arc from t to p, post. Both values are non-negative and 0
+// means that there is no arc. Weights are reconstructed in the same way than
+// when printing the net: pre is the number of tokens consumed by t in p (from
+// Pre) and post is the number of tokens produced, that is Delta[t].Get(p) +
+// pre. Hence a self-loop, where p is both consumed and produced by t, is
+// reported with two positive values, even when pre and post are equal and
+// the loop has no effect on the marking. Read, inhibitor and reset arcs are
+// not taken into account.
+func (net *Net) ArcWeight(t, p int) (pre, post int) {
+	pre = -net.Pre[t].Get(p)
+	return pre, net.Delta[t].Get(p) + pre
+}
+
+// InputPlaces returns the pre-set of transition t, as a sorted slice of place
+// indexes. This is the set of places that appear in Cond[t], meaning the places
+// from which t consumes tokens or that are tested by a read arc. Like with
+// PresetNames, inhibitor and reset arcs are not taken into account.
+func (net *Net) InputPlaces(t int) []int {
+	res := make([]int, len(net.Cond[t]))
+	for k, a := range net.Cond[t] {
+		res[k] = a.Pl
+	}
+	return res
+}
+
+// OutputPlaces returns the post-set of transition t, as a sorted slice of place
+// indexes. This is the set of places in which t produces tokens, meaning places
+// p such that Delta[t].Get(p) - Pre[t].Get(p) is positive.
+func (net *Net) OutputPlaces(t int) []int {
+	res := []int{}
+	for _, a := range net.post(t) {
+		if a.Mult > 0 {
+			res = append(res, a.Pl)
+		}
+	}
+	return res
+}
+
+// ProducingTransitions returns the pre-set of place p, as a sorted slice of
+// transition indexes. This is the set of transitions t such that p is in
+// OutputPlaces(t).
+func (net *Net) ProducingTransitions(p int) []int {
+	res := []int{}
+	for t := range net.Tr {
+		if net.Delta[t].Get(p)-net.Pre[t].Get(p) > 0 {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// ConsumingTransitions returns the post-set of place p, as a sorted slice of
+// transition indexes. This is the set of transitions t such that p is in
+// InputPlaces(t), which includes transitions that only test p with a read arc.
+func (net *Net) ConsumingTransitions(p int) []int {
+	res := []int{}
+	for t := range net.Tr {
+		if net.Cond[t].Get(p) > 0 {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// ChoiceTransitions returns the clusters of transitions that have the same set
+// of input places, given by the places tested in Cond, but that differ in their
+// effect. These transitions are in conflict whenever they are enabled and
+// correspond to choice points in the behavior of the net. We ignore arc weights
+// when comparing sets of input places and transitions without input places.
+// Each cluster has at least two transitions, sorted by index, and clusters are
+// sorted by their first transition.
+func (net *Net) ChoiceTransitions() [][]int {
+	groups := make(map[string][]int)
+	keys := []string{}
+	for t := range net.Tr {
+		if len(net.Cond[t]) == 0 {
+			continue
+		}
+		var b strings.Builder
+		for _, a := range net.Cond[t] {
+			fmt.Fprintf(&b, "%d ", a.Pl)
+		}
+		key := b.String()
+		if _, ok := groups[key]; !ok {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], t)
+	}
+	res := [][]int{}
+	for _, key := range keys {
+		g := groups[key]
+		for _, t := range g[1:] {
+			if !net.Delta[t].Equal(net.Delta[g[0]]) {
+				res = append(res, g)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// IsOrdinary reports whether every arc of the net has weight 1, meaning that
+// the net is an ordinary net. We consider all the arcs written in a .net file:
+// normal arcs, read arcs, inhibitor arcs and probabilistic arcs, so that p?-1,
+// that tests if place p is empty, is an ordinary arc whereas p?2 is not. Reset
+// arcs have no weight and are ignored. Note that a self-loop with weight 2,
+// where Delta is null, is not ordinary.
+//
+// We do not provide a conversion into an ordinary net since the classical
+// constructions, where weighted arcs are replaced with auxiliary places and
+// transitions, do not preserve the atomicity of transitions, which matters
+// with read and inhibitor arcs, priorities and time intervals.
+func (net *Net) IsOrdinary() bool {
+	for t := range net.Tr {
+		ordinary := true
+		net.forEachArc(t, func(p int, kind arcKind, w int) {
+			if kind != arcReset && w != 1 {
+				ordinary = false
+			}
+		})
+		for _, a := range net.probArcs(t) {
+			if a.Mult != 1 {
+				ordinary = false
+			}
+		}
+		if !ordinary {
+			return false
+		}
+	}
+	return true
+}
+
+// Components returns the weakly-connected components of the net, seen as a
+// bipartite graph between places and transitions, where there is an edge
+// between place p and transition t when they are linked by an arc of any kind,
+// including inhibitor, reset and probabilistic arcs. Each component is given
+// as the sorted list of its places, and components are sorted by their first
+// place. Hence an isolated place forms a component of its own, while
+// transitions that have no arcs do not appear in the result. Two places in
+// distinct components never interact, except through priorities and timing
+// constraints, that we do not take into account.
+func (net *Net) Components() [][]int {
+	// we use a union-find structure over places, with path halving
+	parent := make([]int, len(net.Pl))
+	for p := range parent {
+		parent[p] = p
+	}
+	find := func(p int) int {
+		for parent[p] != p {
+			parent[p] = parent[parent[p]]
+			p = parent[p]
+		}
+		return p
+	}
+	union := func(p, q int) {
+		p, q = find(p), find(q)
+		// the smallest place is the representative of its component
+		if p < q {
+			parent[q] = p
+		} else {
+			parent[p] = q
+		}
+	}
+	for t := range net.Tr {
+		first := -1
+		link := func(p int) {
+			if first < 0 {
+				first = p
+			} else {
+				union(first, p)
+			}
+		}
+		for _, m := range []Marking{net.Cond[t], net.Inhib[t], net.Pre[t], net.Delta[t]} {
+			for _, a := range m {
+				link(a.Pl)
+			}
+		}
+		for _, p := range net.resets(t) {
+			link(p)
+		}
+		for _, a := range net.probArcs(t) {
+			link(a.Pl)
+		}
+	}
+	res := [][]int{}
+	index := make(map[int]int)
+	for p := range net.Pl {
+		r := find(p)
+		k, ok := index[r]
+		if !ok {
+			k = len(res)
+			index[r] = k
+			res = append(res, nil)
+		}
+		res[k] = append(res[k], p)
+	}
+	return res
+}
+
+// Stats is a summary of the size of a net, as returned by method Stats.
+type Stats struct {
+	Places           int // number of places
+	Transitions      int // number of transitions
+	Arcs             int // number of normal arcs, both input and output arcs
+	ReadArcs         int // number of read arcs (test arcs)
+	InhibitorArcs    int // number of inhibitor arcs
+	TimedTransitions int // number of transitions with a non-trivial time interval
+	MaxArcWeight     int // largest weight of a normal, read or inhibitor arc
+	InitialTokens    int // total number of tokens in the initial marking
+}
+
+// Stats returns a summary of the size of the net. Arcs are counted like when
+// printing the net, meaning that there is at most one arc of each kind between
+// a place and a transition; for instance a self-loop counts as one input and
+// one output arc. Probabilistic arcs are counted as normal output arcs, reset
+// arcs are not counted, and the MaxArcWeight is 0 when the net has no arcs.
+func (net *Net) Stats() Stats {
+	res := Stats{Places: len(net.Pl), Transitions: len(net.Tr)}
+	for t := range net.Tr {
+		if !net.Time[t].Trivial() {
+			res.TimedTransitions++
+		}
+		net.forEachArc(t, func(p int, kind arcKind, w int) {
+			switch kind {
+			case arcIn, arcOut:
+				res.Arcs++
+			case arcRead:
+				res.ReadArcs++
+			case arcInhibit:
+				res.InhibitorArcs++
+			}
+			res.MaxArcWeight = max(res.MaxArcWeight, w)
+		})
+		for _, a := range net.probArcs(t) {
+			res.Arcs++
+			res.MaxArcWeight = max(res.MaxArcWeight, a.Mult)
+		}
+	}
+	for _, a := range net.Initial {
+		res.InitialTokens += a.Mult
+	}
+	return res
+}
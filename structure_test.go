@@ -0,0 +1,311 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestIsolatedNodes(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p1 -> p2\npl p3 : b\npr t0 > t1\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	places, trans := net.IsolatedNodes()
+	if !slices.Equal(places, []int{0, 3}) {
+		t.Errorf("IsolatedNodes: expected places [0 3], actual %v", places)
+	}
+	if !slices.Equal(trans, []int{1}) {
+		t.Errorf("IsolatedNodes: expected transitions [1], actual %v", trans)
+	}
+}
+
+func TestCheckPriorityReferences(t *testing.T) {
+	net, err := Parse(strings.NewReader("tr t0 p0 -> p1\ntr t1 p1 -> p0\ntr t2 : a\npr t0 > t1 tl t2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	errs := net.CheckPriorityReferences()
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "tl") {
+		t.Errorf("CheckPriorityReferences: expected one error for tl, actual %v", errs)
+	}
+}
+
+func TestAsVASS(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0*2 p1?3 p2?-1 -> p1\ntr t1 p1 p1?2 p3?! -> p0 p2*2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	guards, updates, err := net.AsVASS()
+	if err != nil {
+		t.Fatalf("AsVASS: %s", err)
+	}
+	// inhibitor and reset arcs are ignored, read arcs only appear in guards
+	expectedGuards := [][]int{{2, 3, 0, 0}, {0, 2, 0, 0}}
+	expectedUpdates := [][]int{{-2, 1, 0, 0}, {1, -1, 2, 0}}
+	for k := range net.Tr {
+		if !slices.Equal(guards[k], expectedGuards[k]) || !slices.Equal(updates[k], expectedUpdates[k]) {
+			t.Errorf("AsVASS(%s): expected %v and %v, actual %v and %v", net.Tr[k], expectedGuards[k], expectedUpdates[k], guards[k], updates[k])
+		}
+	}
+	net.Cond[1] = append(net.Cond[1], Atom{Pl: 7, Mult: 1})
+	if _, _, err := net.AsVASS(); err == nil {
+		t.Errorf("AsVASS: expected error with an invalid place index")
+	}
+	net, err = ParseWithOptions(strings.NewReader("tr t0 p0 -> p1%0.5 p2%0.5\n"), ParseOptions{Probabilities: true})
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if _, _, err := net.AsVASS(); err == nil {
+		t.Errorf("AsVASS: expected error with probabilistic arcs")
+	}
+}
+
+func TestPresetNames(t *testing.T) {
+	net, err := Parse(strings.NewReader("tr t0 p0*2 p0?3 p1?1 p2?-1 p3 -> p3 p4*2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if actual := net.PresetNames(0); !slices.Equal(actual, []string{"p0", "p1", "p3"}) {
+		t.Errorf("PresetNames: expected [p0 p1 p3], actual %v", actual)
+	}
+	if actual := net.PostsetNames(0); !slices.Equal(actual, []string{"p3", "p4"}) {
+		t.Errorf("PostsetNames: expected [p3 p4], actual %v", actual)
+	}
+}
+
+func TestChoiceTransitions(t *testing.T) {
+	net, err := Parse(strings.NewReader(`pl p0 (1)
+tr t0 p0 -> p1
+tr t1 p0*2 -> p2
+tr t2 p1 -> p0
+tr t3 p1 -> p0
+tr t4 -> p0
+tr t5 p0 p1 -> p1
+tr t6 p0?1 -> p0 p3
+`))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	expected := [][]int{{0, 1, 6}}
+	if actual := net.ChoiceTransitions(); !slices.EqualFunc(actual, expected, slices.Equal) {
+		t.Errorf("ChoiceTransitions: expected %v, actual %v", expected, actual)
+	}
+}
+
+func TestInputOutputPlaces(t *testing.T) {
+	net, err := ParseFile("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/demo.net; %s", err)
+	}
+	pl := func(names ...string) []int {
+		res := []int{}
+		for _, v := range names {
+			p, _ := net.PlaceIndex(v)
+			res = append(res, p)
+		}
+		slices.Sort(res)
+		return res
+	}
+	tr := func(names ...string) []int {
+		res := []int{}
+		for _, v := range names {
+			t, _ := net.TransitionIndex(v)
+			res = append(res, t)
+		}
+		slices.Sort(res)
+		return res
+	}
+	for _, v := range []struct {
+		t             string
+		input, output []int
+	}{
+		{"t0", pl("p0"), pl("p1", "p4")},
+		{"t1", pl("p0"), pl("p1")},
+		{"t2", pl(), pl()},
+		{"t4", pl(), pl("p4")},
+		{"t6", pl("p4"), pl()},
+	} {
+		k, _ := net.TransitionIndex(v.t)
+		if actual := net.InputPlaces(k); !slices.Equal(actual, v.input) {
+			t.Errorf("InputPlaces(%s): expected %v, actual %v", v.t, v.input, actual)
+		}
+		if actual := net.OutputPlaces(k); !slices.Equal(actual, v.output) {
+			t.Errorf("OutputPlaces(%s): expected %v, actual %v", v.t, v.output, actual)
+		}
+	}
+	for _, v := range []struct {
+		p                    string
+		producing, consuming []int
+	}{
+		{"p0", tr("t5"), tr("t0", "t1")},
+		{"p1", tr("t0", "t1"), tr()},
+		{"p4", tr("t0", "t4"), tr("t5", "t6")},
+		{"p2", tr(), tr("t3")},
+	} {
+		k, _ := net.PlaceIndex(v.p)
+		if actual := net.ProducingTransitions(k); !slices.Equal(actual, v.producing) {
+			t.Errorf("ProducingTransitions(%s): expected %v, actual %v", v.p, v.producing, actual)
+		}
+		if actual := net.ConsumingTransitions(k); !slices.Equal(actual, v.consuming) {
+			t.Errorf("ConsumingTransitions(%s): expected %v, actual %v", v.p, v.consuming, actual)
+		}
+	}
+}
+
+func TestArcWeight(t *testing.T) {
+	net, err := ParseFile("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error parsing file testdata/demo.net; %s", err)
+	}
+	tables := []struct {
+		t, p      string
+		pre, post int
+	}{
+		{"t0", "p0", 3, 0},
+		{"t0", "p1", 0, 1},
+		{"t0", "p4", 0, 1},
+		{"t5", "p4", 1, 0},
+		{"t5", "p0", 0, 1},
+		{"t6", "p4", 0, 0},
+		{"t2", "p1", 0, 0},
+		{"t3", "p0", 0, 0},
+	}
+	for _, tt := range tables {
+		tr, _ := net.TransitionIndex(tt.t)
+		p, _ := net.PlaceIndex(tt.p)
+		if pre, post := net.ArcWeight(tr, p); pre != tt.pre || post != tt.post {
+			t.Errorf("ArcWeight(%s, %s): expected (%d, %d), actual (%d, %d)", tt.t, tt.p, tt.pre, tt.post, pre, post)
+		}
+	}
+	loop, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0*2 -> p0*3\ntr t1 p0 -> p0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if pre, post := loop.ArcWeight(0, 0); pre != 2 || post != 3 {
+		t.Errorf("ArcWeight(t0, p0): expected (2, 3), actual (%d, %d)", pre, post)
+	}
+	if pre, post := loop.ArcWeight(1, 0); pre != 1 || post != 1 {
+		t.Errorf("ArcWeight(t1, p0): expected (1, 1), actual (%d, %d)", pre, post)
+	}
+}
+
+func TestIsOrdinary(t *testing.T) {
+	tables := []struct {
+		input    string
+		expected bool
+	}{
+		{"", true},
+		{"tr t0 p0 p1?1 p2?-1 p3?! -> p1 p4\n", true},
+		{"tr t0 p0*2 -> p1\n", false},
+		{"tr t0 p0 -> p1*2\n", false},
+		{"tr t0 p0*2 -> p0*2\n", false},
+		{"tr t0 p0 p0?2 -> p1\n", false},
+		{"tr t0 p0?-2 -> p1\n", false},
+		{"tr t0 p0 -> p1%0.5 p2%0.5\n", true},
+		{"tr t0 p0 -> p1*2%0.5 p2%0.5\n", false},
+	}
+	for _, tt := range tables {
+		net, err := ParseWithOptions(strings.NewReader(tt.input), ParseOptions{Probabilities: true})
+		if err != nil {
+			t.Fatalf("Error parsing net %q; %s", tt.input, err)
+		}
+		if actual := net.IsOrdinary(); actual != tt.expected {
+			t.Errorf("IsOrdinary(%q): expected %v, actual %v", tt.input, tt.expected, actual)
+		}
+	}
+	for v, expected := range map[string]bool{"abp.net": true, "ifip.net": false} {
+		net, err := ParseFile("testdata/" + v)
+		if err != nil {
+			t.Fatalf("Error parsing file; %s", err)
+		}
+		if actual := net.IsOrdinary(); actual != expected {
+			t.Errorf("IsOrdinary(%s): expected %v, actual %v", v, expected, actual)
+		}
+	}
+}
+
+func TestComponents(t *testing.T) {
+	abp, err := os.ReadFile("testdata/abp.net")
+	if err != nil {
+		t.Fatalf("Error opening file; %s", err)
+	}
+	ifip, err := os.ReadFile("testdata/ifip.net")
+	if err != nil {
+		t.Fatalf("Error opening file; %s", err)
+	}
+	// we rename the nodes of ifip.net to obtain two disjoint sub-nets
+	input := string(abp) + regexp.MustCompile(`\b([pt])(\d)`).ReplaceAllString(string(ifip), "${1}x$2")
+	net, err := ParseString(input)
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	comps := net.Components()
+	if len(comps) != 2 {
+		t.Fatalf("Components: expected 2 components, actual %v", comps)
+	}
+	for k, c := range comps {
+		for _, p := range c {
+			if renamed := strings.HasPrefix(net.Pl[p], "px"); renamed != (k == 1) {
+				t.Errorf("Components: place %s should not be in component %d", net.Pl[p], k)
+			}
+		}
+	}
+	if len(comps[0])+len(comps[1]) != len(net.Pl) {
+		t.Errorf("Components: every place should be in a component, actual %v", comps)
+	}
+	tables := []struct {
+		input    string
+		expected [][]int
+	}{
+		{"", [][]int{}},
+		{"pl p0\npl p1\npl p2\n", [][]int{{0}, {1}, {2}}},
+		{"tr t0 p0 -> p2\ntr t1 p1 -> p3\ntr t2 p3?-1 -> p4\ntr t3 ->\n", [][]int{{0, 1}, {2, 3, 4}}},
+		{"tr t0 p0 -> p1\ntr t1 p2?! -> \ntr t2 p2?1 -> p3\npl p4\n", [][]int{{0, 1}, {2, 3}, {4}}},
+		{"tr t p0 -> p1%0.5 p2%0.5\n", [][]int{{0, 1, 2}}},
+	}
+	for _, tt := range tables {
+		net, err := ParseWithOptions(strings.NewReader(tt.input), ParseOptions{Probabilities: true})
+		if err != nil {
+			t.Fatalf("Error parsing net %q; %s", tt.input, err)
+		}
+		actual := net.Components()
+		if !slices.EqualFunc(actual, tt.expected, slices.Equal) {
+			t.Errorf("Components(%q): expected %v, actual %v", tt.input, tt.expected, actual)
+		}
+	}
+}
+
+func TestStats(t *testing.T) {
+	net, err := ParseFile("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	expected := Stats{
+		Places:           4,
+		Transitions:      7,
+		Arcs:             9,
+		ReadArcs:         1,
+		InhibitorArcs:    1,
+		TimedTransitions: 3,
+		MaxArcWeight:     4000,
+		InitialTokens:    1,
+	}
+	if s := net.Stats(); s != expected {
+		t.Errorf("Stats: expected %+v, actual %+v", expected, s)
+	}
+	net, err = ParseString("pl p0 (2)\npl p1 (3)\ntr t0 p0*2 -> p0 p1\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	expected = Stats{Places: 2, Transitions: 1, Arcs: 3, MaxArcWeight: 2, InitialTokens: 5}
+	if s := net.Stats(); s != expected {
+		t.Errorf("Stats: expected %+v, actual %+v", expected, s)
+	}
+}
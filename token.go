@@ -18,6 +18,12 @@ func (t *textPos) String() string {
 	return fmt.Sprintf("line: %d column: %d", t.line+1, t.col-t.ahead)
 }
 
+// errorf returns a ParseError at position t with a message built from format
+// and a, like with fmt.Errorf.
+func (t *textPos) errorf(format string, a ...any) error {
+	return &ParseError{Line: t.line + 1, Col: t.col - t.ahead, Msg: fmt.Sprintf(format, a...)}
+}
+
 type tokenKind int
 
 // tokenKind is an enumeration describing possible tokens in a net file. tokTR is
@@ -41,6 +47,9 @@ const (
 	tokSTAR                       // arc multiplicity: '*'
 	tokINT                        // integer value, could occur in tpn instruction
 	tokNOTE                       // notes can appear when translating from TINA
+	tokREAL                       // decimal value, used in weights: '0.25'
+	tokPROB                       // probability of an output arc: '%0.3'
+	tokRESET                      // reset arc: '?!'
 )
 
 type token struct {
@@ -54,7 +63,10 @@ func (tok token) String() string {
 		") " + tok.s + fmt.Sprintf(" %v \n", tok.pos)
 }
 
-var eof = rune(0)
+// eof is the value returned by the scanner at the end of the input. We use a
+// value that is not a valid rune, so that a null character in the input is not
+// mistaken for the end of file.
+var eof = rune(-1)
 
 func isWhitespace(ch rune) bool {
 	return ch == ' ' || ch == '\t' || ch == '\r' || ch == '\n'
@@ -68,6 +80,12 @@ func isDigit(ch rune) bool {
 	return (ch >= '0' && ch <= '9')
 }
 
+// isMultiplier reports whether ch is one of the suffixes that can follow a
+// number, such as in 3K (3000); see mconvert.
+func isMultiplier(ch rune) bool {
+	return ch == 'K' || ch == 'M' || ch == 'G' || ch == 'T' || ch == 'P' || ch == 'E'
+}
+
 func isIdentChar(ch rune) bool {
 	return (ch == '_') || (ch == '\'') || (ch == '.')
 }
@@ -26,11 +26,14 @@ func _() {
 	_ = x[tokSTAR-15]
 	_ = x[tokINT-16]
 	_ = x[tokNOTE-17]
+	_ = x[tokREAL-18]
+	_ = x[tokPROB-19]
+	_ = x[tokRESET-20]
 }
 
-const _tokenKind_name = "tokTRtokEOFtokPLtokNETtokARROWtokIDENTtokTIMINGCtokINHIBITORtokREADtokLABELtokILLEGALtokMARKINGtokPRIOtokGTtokLTtokSTARtokINTtokNOTE"
+const _tokenKind_name = "tokTRtokEOFtokPLtokNETtokARROWtokIDENTtokTIMINGCtokINHIBITORtokREADtokLABELtokILLEGALtokMARKINGtokPRIOtokGTtokLTtokSTARtokINTtokNOTEtokREALtokPROBtokRESET"
 
-var _tokenKind_index = [...]uint8{0, 5, 11, 16, 22, 30, 38, 48, 60, 67, 75, 85, 95, 102, 107, 112, 119, 125, 132}
+var _tokenKind_index = [...]uint8{0, 5, 11, 16, 22, 30, 38, 48, 60, 67, 75, 85, 95, 102, 107, 112, 119, 125, 132, 139, 146, 154}
 
 func (i tokenKind) String() string {
 	if i < 0 || i >= tokenKind(len(_tokenKind_index)-1) {
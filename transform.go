@@ -0,0 +1,766 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ReadArcsAsConsumeRestore returns a copy of the net where every read arc is
+// replaced by a self-loop, meaning a pair of input and output arcs with the
+// same weight. The enabling condition (Cond) and the effect of firing (Delta)
+// of transitions are unchanged, only Pre is modified.
+//
+// With our (default) semantics, read arcs are pure tests: a transition with a
+// read arc on place p does not consume the tokens in p. With the
+// consume-and-restore semantics, used by some tools, the transition consumes
+// the tokens then puts them back. The difference is visible for the
+// concurrency of transitions (two transitions cannot read the same token at
+// the same time) and, in a Time Petri net, for the re-initialization of
+// transitions that depend on the tokens tested.
+func (net *Net) ReadArcsAsConsumeRestore() *Net {
+	res := net.Clone()
+	for t := range res.Tr {
+		for _, a := range res.Cond[t] {
+			if a.Mult > -res.Pre[t].Get(a.Pl) {
+				res.Pre[t] = res.Pre[t].updateIfLess(a.Pl, -a.Mult)
+			}
+		}
+	}
+	return res
+}
+
+// Reverse returns a copy of the net where the direction of every normal arc is
+// flipped, meaning that the input and output arcs of each transition are
+// exchanged. Hence Delta is negated and Pre is computed from the output arcs of
+// the original net. This is useful for backward reachability: marking m' is
+// reachable from m in the reverse net if and only if m is reachable from m' in
+// the original net, when we ignore timing constraints, priorities, inhibitor
+// and reset arcs.
+//
+// Read arcs test a place without changing its marking, so they are carried
+// over, but they must be checked on the marking before firing the original
+// transition, that is on the marking obtained after firing the reversed one.
+// Hence, when a transition reads r tokens in place p, on top of the tokens it
+// consumes, and produces post tokens in p, the condition of the reversed
+// transition on p is post + r. For instance, the reverse of "tr t p?2 -> p*3"
+// consumes 3 tokens in p and needs 5 tokens to fire. Inhibitor arcs are kept
+// unchanged. We also keep time intervals, priorities, the initial marking, and
+// reset arcs (that do not have a meaningful reverse), but we drop
+// probabilistic arcs, that are alternative outputs and cannot be used as
+// inputs. Reversing a net twice gives back the original net when it has no
+// probabilistic arcs.
+func (net *Net) Reverse() *Net {
+	res := net.Clone()
+	res.ArcProb = nil
+	for t := range res.Tr {
+		var cond, pre Marking
+		for p := range net.Pl {
+			inp := -net.Pre[t].Get(p)
+			outp := net.Delta[t].Get(p) + inp
+			read := max(0, net.Cond[t].Get(p)-inp)
+			if outp > 0 {
+				// output arcs become input arcs
+				pre = append(pre, Atom{p, -outp})
+			}
+			if c := outp + read; c > 0 {
+				cond = append(cond, Atom{p, c})
+			}
+		}
+		res.Cond[t] = cond
+		res.Pre[t] = pre
+		res.Delta[t] = net.Delta[t].Scale(-1)
+	}
+	return res
+}
+
+// Untimed returns a copy of the net where the time interval of every
+// transition is [0,w[, meaning that the result is a P/T net (with the same
+// arcs, priorities and initial marking) that can be used with tools that do
+// not support timing constraints. We use the interval set by the parser for
+// transitions without timing information, so that the result is Equal to the
+// net obtained by parsing its textual representation.
+func (net *Net) Untimed() *Net {
+	res := net.Clone()
+	for k := range res.Time {
+		res.Time[k] = NewUnboundedInterval(0, true)
+	}
+	return res
+}
+
+// Subnet returns the sub-net with only the transitions in trans, given by
+// their index, and the places connected to them by an arc of any kind. Places
+// and transitions keep their names, labels and relative order, but they are
+// re-indexed. The initial marking, named markings and priorities are
+// restricted to the nodes that are kept, and so are colors. We keep the notes
+// of the net, except the color and server notes that refer to a node that is
+// dropped. Indexes in trans that are out of range, or that occur several
+// times, are ignored.
+func (net *Net) Subnet(trans []int) *Net {
+	trMap := make([]int, len(net.Tr))
+	for t := range trMap {
+		trMap[t] = -1
+	}
+	plMap := make([]int, len(net.Pl))
+	for p := range plMap {
+		plMap[p] = -1
+	}
+	for _, t := range trans {
+		if t < 0 || t >= len(net.Tr) {
+			continue
+		}
+		trMap[t] = 0
+		for _, m := range []Marking{net.Cond[t], net.Inhib[t], net.Pre[t], net.Delta[t]} {
+			for _, a := range m {
+				plMap[a.Pl] = 0
+			}
+		}
+		for _, p := range net.resets(t) {
+			plMap[p] = 0
+		}
+		for _, a := range net.probArcs(t) {
+			plMap[a.Pl] = 0
+		}
+	}
+	res := &Net{Name: net.Name}
+	for p, k := range plMap {
+		if k >= 0 {
+			plMap[p] = len(res.Pl)
+			res.Pl = append(res.Pl, net.Pl[p])
+			res.Plabel = append(res.Plabel, net.Plabel[p])
+		}
+	}
+	rename := func(m Marking) Marking {
+		res := Marking{}
+		for _, a := range m {
+			if plMap[a.Pl] >= 0 {
+				res = append(res, Atom{plMap[a.Pl], a.Mult})
+			}
+		}
+		return res
+	}
+	res.Initial = rename(net.Initial)
+	for k, m := range net.NamedMarkings {
+		if res.NamedMarkings == nil {
+			res.NamedMarkings = make(map[string]Marking)
+		}
+		res.NamedMarkings[k] = rename(m)
+	}
+	for t, k := range trMap {
+		if k < 0 {
+			continue
+		}
+		k = res.addTransition(net.Tr[t])
+		trMap[t] = k
+		res.Tlabel[k] = net.Tlabel[t]
+		res.Time[k] = net.Time[t]
+		res.Cond[k] = rename(net.Cond[t])
+		res.Inhib[k] = rename(net.Inhib[t])
+		res.Pre[k] = rename(net.Pre[t])
+		res.Delta[k] = rename(net.Delta[t])
+		if net.IsDisabled(t) {
+			res.Disable(k)
+		}
+		if t < len(net.Weight) {
+			res.SetWeight(k, net.Weight[t])
+		}
+		if s := net.Server(t); s != SingleServer {
+			res.SetServer(k, s)
+		}
+		for _, a := range net.probArcs(t) {
+			res.addProbArc(k, ProbArc{plMap[a.Pl], a.Mult, a.Prob})
+		}
+		for _, p := range net.resets(t) {
+			res.addReset(k, plMap[p])
+		}
+	}
+	res.NodeColor = maps.Clone(net.NodeColor)
+	res.Notes = slices.Clone(net.Notes)
+	for t, k := range trMap {
+		if k < 0 {
+			continue
+		}
+		for _, t2 := range net.Prio[t] {
+			if trMap[t2] >= 0 {
+				res.Prio[k] = setAdd(res.Prio[k], trMap[t2])
+			}
+		}
+	}
+	res.indexNodes()
+	res.dropStaleNotes()
+	return res
+}
+
+// Merge returns the disjoint union of nets a and b, meaning a net with the
+// places and transitions of a followed by those of b. We add prefixA (and
+// prefixB) in front of every place and transition name of a (and b), to avoid
+// clashes; use the empty string to keep the names unchanged. The prefix is
+// added inside the braces for names between braces, so that {p 1} becomes
+// {l_p 1} with prefix l_. Arcs, markings and priorities of b are shifted so
+// that they refer to the nodes of b in the result, and we keep the labels,
+// time intervals, notes and extensions of both nets. Named markings with the
+// same name in a and b are merged into a single marking. The result has the
+// name of a, and neither a nor b is modified.
+//
+// We return an error if, after adding the prefixes, a place (or a transition)
+// of a has the same name as a place (or a transition) of b.
+func Merge(a, b *Net, prefixA, prefixB string) (*Net, error) {
+	res, nb := a.Clone(), b.Clone()
+	res.addPrefix(prefixA)
+	nb.addPrefix(prefixB)
+	for _, v := range nb.Pl {
+		if slices.Contains(res.Pl, v) {
+			return nil, fmt.Errorf("cannot merge nets, place %s is in both nets", v)
+		}
+	}
+	for _, v := range nb.Tr {
+		if slices.Contains(res.Tr, v) {
+			return nil, fmt.Errorf("cannot merge nets, transition %s is in both nets", v)
+		}
+	}
+	np, nt := len(res.Pl), len(res.Tr)
+	shift := func(m Marking) Marking {
+		res := make(Marking, len(m))
+		for k, a := range m {
+			res[k] = Atom{a.Pl + np, a.Mult}
+		}
+		return res
+	}
+	res.Pl = append(res.Pl, nb.Pl...)
+	res.Plabel = append(res.Plabel, nb.Plabel...)
+	res.Initial = append(res.Initial, shift(nb.Initial)...)
+	for k, m := range nb.NamedMarkings {
+		if res.NamedMarkings == nil {
+			res.NamedMarkings = make(map[string]Marking)
+		}
+		res.NamedMarkings[k] = append(res.NamedMarkings[k], shift(m)...)
+	}
+	for t := range nb.Tr {
+		k := res.addTransition(nb.Tr[t])
+		res.Tlabel[k] = nb.Tlabel[t]
+		res.Time[k] = nb.Time[t]
+		res.Cond[k] = shift(nb.Cond[t])
+		res.Inhib[k] = shift(nb.Inhib[t])
+		res.Pre[k] = shift(nb.Pre[t])
+		res.Delta[k] = shift(nb.Delta[t])
+		for _, t2 := range nb.Prio[t] {
+			res.Prio[k] = append(res.Prio[k], t2+nt)
+		}
+	}
+	if len(res.Disabled) != 0 || len(nb.Disabled) != 0 {
+		disabled := make([]bool, len(res.Tr))
+		copy(disabled, res.Disabled)
+		copy(disabled[nt:], nb.Disabled)
+		res.Disabled = disabled
+	}
+	for t := range nb.Tr {
+		if t < len(nb.Weight) {
+			res.SetWeight(t+nt, nb.Weight[t])
+		}
+		if s := nb.Server(t); s != SingleServer {
+			res.SetServer(t+nt, s)
+		}
+		for _, a := range nb.probArcs(t) {
+			res.addProbArc(t+nt, ProbArc{a.Pl + np, a.Mult, a.Prob})
+		}
+		for _, p := range nb.resets(t) {
+			res.addReset(t+nt, p+np)
+		}
+	}
+	for k, v := range nb.NodeColor {
+		if res.NodeColor == nil {
+			res.NodeColor = make(map[string]string)
+		}
+		res.NodeColor[k] = v
+	}
+	res.Notes = append(res.Notes, nb.Notes...)
+	res.indexNodes()
+	return res, nil
+}
+
+// addPrefix adds prefix in front of the name of every place and transition of
+// the net, as in Merge. We also update the colors of nodes and the notes that
+// refer to nodes by their names.
+func (net *Net) addPrefix(prefix string) {
+	if prefix == "" {
+		return
+	}
+	add := func(name string) string {
+		if strings.HasPrefix(name, "{") {
+			return "{" + prefix + name[1:]
+		}
+		return prefix + name
+	}
+	for k, v := range net.Pl {
+		net.Pl[k] = add(v)
+	}
+	for k, v := range net.Tr {
+		net.Tr[k] = add(v)
+	}
+	net.indexNodes()
+	net.renameInNotes(add, "color", "server")
+}
+
+// MergePlaces fuses place b into place a. The marking of the resulting place
+// is the sum of the markings of a and b: we add the initial markings of the two
+// places and the weights of the arcs from (or to) each of them. For inhibitor
+// arcs, we keep the most restrictive (smallest) of the two conditions. Place b
+// is then removed from the net, which means that places with an index greater
+// than b are shifted by one, and we drop the color of b and the color notes
+// that refer to it. We return an error if a and b are equal or are not valid
+// place indexes.
+func (net *Net) MergePlaces(a, b int) error {
+	if a < 0 || a >= len(net.Pl) || b < 0 || b >= len(net.Pl) {
+		return fmt.Errorf("invalid place index when merging places %d and %d", a, b)
+	}
+	if a == b {
+		return fmt.Errorf("cannot merge place %s with itself", net.Pl[a])
+	}
+	net.Initial = mergeAtoms(net.Initial, a, b, false)
+	for k, m := range net.NamedMarkings {
+		net.NamedMarkings[k] = mergeAtoms(m, a, b, false)
+	}
+	for t := range net.Tr {
+		net.Cond[t] = mergeAtoms(net.Cond[t], a, b, false)
+		net.Pre[t] = mergeAtoms(net.Pre[t], a, b, false)
+		net.Delta[t] = mergeAtoms(net.Delta[t], a, b, false)
+		net.Inhib[t] = mergeAtoms(net.Inhib[t], a, b, true)
+	}
+	net.renamePlacesInArcs(func(p int) int {
+		if p == b {
+			p = a
+		}
+		if p > b {
+			p--
+		}
+		return p
+	})
+	net.Pl = slices.Delete(net.Pl, b, b+1)
+	net.Plabel = slices.Delete(net.Plabel, b, b+1)
+	net.indexNodes()
+	net.dropStaleNotes()
+	return nil
+}
+
+// mergeAtoms returns a new marking where the multiplicity of place b is added
+// to the one of a (or where we keep the minimum of the two when min is true).
+// Place b is removed and the indexes of places greater than b are decremented.
+func mergeAtoms(m Marking, a, b int, min bool) Marking {
+	ma, oka := 0, false
+	mb, okb := 0, false
+	res := Marking{}
+	for _, v := range m {
+		switch v.Pl {
+		case a:
+			ma, oka = v.Mult, true
+		case b:
+			mb, okb = v.Mult, true
+		default:
+			res = append(res, v)
+		}
+	}
+	mult := ma + mb
+	if min && oka && okb {
+		mult = ma
+		if mb < ma {
+			mult = mb
+		}
+	}
+	res = res.AddToPlace(a, mult)
+	for k := range res {
+		if res[k].Pl > b {
+			res[k].Pl--
+		}
+	}
+	return res
+}
+
+// deletePlace removes place p from the net. The indexes of the places greater
+// than p are shifted by one in every marking of the net. We also drop the
+// colors and notes that refer to p.
+func (net *Net) deletePlace(p int) {
+	del := func(m Marking) Marking {
+		res := Marking{}
+		for _, a := range m {
+			switch {
+			case a.Pl < p:
+				res = append(res, a)
+			case a.Pl > p:
+				res = append(res, Atom{a.Pl - 1, a.Mult})
+			}
+		}
+		return res
+	}
+	net.Initial = del(net.Initial)
+	for k, m := range net.NamedMarkings {
+		net.NamedMarkings[k] = del(m)
+	}
+	for t := range net.Tr {
+		net.Cond[t] = del(net.Cond[t])
+		net.Inhib[t] = del(net.Inhib[t])
+		net.Pre[t] = del(net.Pre[t])
+		net.Delta[t] = del(net.Delta[t])
+	}
+	net.renamePlacesInArcs(func(p2 int) int {
+		switch {
+		case p2 == p:
+			return -1
+		case p2 > p:
+			return p2 - 1
+		}
+		return p2
+	})
+	net.Pl = slices.Delete(net.Pl, p, p+1)
+	net.Plabel = slices.Delete(net.Plabel, p, p+1)
+	net.indexNodes()
+	net.dropStaleNotes()
+}
+
+// deleteTransition removes transition t from the net. The indexes of the
+// transitions greater than t are shifted by one, including in the priority
+// relation. We also drop the colors and notes that refer to t.
+func (net *Net) deleteTransition(t int) {
+	net.Tr = slices.Delete(net.Tr, t, t+1)
+	net.Tlabel = slices.Delete(net.Tlabel, t, t+1)
+	net.Time = slices.Delete(net.Time, t, t+1)
+	net.Cond = slices.Delete(net.Cond, t, t+1)
+	net.Inhib = slices.Delete(net.Inhib, t, t+1)
+	net.Pre = slices.Delete(net.Pre, t, t+1)
+	net.Delta = slices.Delete(net.Delta, t, t+1)
+	net.Prio = slices.Delete(net.Prio, t, t+1)
+	for k, v := range net.Prio {
+		res := []int{}
+		for _, t2 := range v {
+			switch {
+			case t2 < t:
+				res = append(res, t2)
+			case t2 > t:
+				res = append(res, t2-1)
+			}
+		}
+		net.Prio[k] = res
+	}
+	if t < len(net.Disabled) {
+		net.Disabled = slices.Delete(net.Disabled, t, t+1)
+	}
+	if t < len(net.Weight) {
+		net.Weight = slices.Delete(net.Weight, t, t+1)
+	}
+	if t < len(net.ServerKind) {
+		net.ServerKind = slices.Delete(net.ServerKind, t, t+1)
+	}
+	if t < len(net.ArcProb) {
+		net.ArcProb = slices.Delete(net.ArcProb, t, t+1)
+	}
+	if t < len(net.Reset) {
+		net.Reset = slices.Delete(net.Reset, t, t+1)
+	}
+	net.indexNodes()
+	net.dropStaleNotes()
+}
+
+// renamePlacesInArcs replaces place p with f(p) in the probabilistic and
+// reset arcs of the net, or deletes the arc when f(p) is negative.
+func (net *Net) renamePlacesInArcs(f func(p int) int) {
+	for t, v := range net.ArcProb {
+		res := []ProbArc{}
+		for _, a := range v {
+			if a.Pl = f(a.Pl); a.Pl >= 0 {
+				res = append(res, a)
+			}
+		}
+		net.ArcProb[t] = res
+	}
+	for t, v := range net.Reset {
+		res := []int{}
+		for _, p := range v {
+			if p = f(p); p >= 0 {
+				res = setAdd(res, p)
+			}
+		}
+		net.Reset[t] = res
+	}
+}
+
+// permute returns a copy of the net where places and transitions have been
+// reordered, such that the k-th place of the result is place plOrder[k] of the
+// net, and similarly with trOrder for transitions. Both slices must be
+// permutations of the indexes of the net. Every marking, as well as the
+// priority relation and the extensions, is updated accordingly.
+func (net *Net) permute(plOrder, trOrder []int) *Net {
+	res := net.Clone()
+	pl := make([]int, len(plOrder))
+	for k, p := range plOrder {
+		pl[p] = k
+		res.Pl[k] = net.Pl[p]
+		res.Plabel[k] = net.Plabel[p]
+	}
+	tr := make([]int, len(trOrder))
+	for k, t := range trOrder {
+		tr[t] = k
+	}
+	rename := func(m Marking) Marking {
+		res := make(Marking, len(m))
+		for k, a := range m {
+			res[k] = Atom{pl[a.Pl], a.Mult}
+		}
+		slices.SortFunc(res, func(a, b Atom) int { return a.Pl - b.Pl })
+		return res
+	}
+	res.Initial = rename(net.Initial)
+	for k, m := range net.NamedMarkings {
+		res.NamedMarkings[k] = rename(m)
+	}
+	res.renamePlacesInArcs(func(p int) int { return pl[p] })
+	// we extend optional slices to the number of transitions, using default
+	// values, so that we can reorder them.
+	disabled := slices.Clone(net.Disabled)
+	weight := slices.Clone(net.Weight)
+	server := slices.Clone(net.ServerKind)
+	for len(disabled) != 0 && len(disabled) < len(net.Tr) {
+		disabled = append(disabled, false)
+	}
+	for len(weight) != 0 && len(weight) < len(net.Tr) {
+		weight = append(weight, 1)
+	}
+	for len(server) != 0 && len(server) < len(net.Tr) {
+		server = append(server, SingleServer)
+	}
+	res.Disabled, res.Weight, res.ServerKind = slices.Clone(disabled), slices.Clone(weight), slices.Clone(server)
+	prob, reset := res.ArcProb, res.Reset
+	if prob != nil {
+		res.ArcProb = make([][]ProbArc, len(net.Tr))
+	}
+	if reset != nil {
+		res.Reset = make([][]int, len(net.Tr))
+	}
+	for k, t := range trOrder {
+		res.Tr[k] = net.Tr[t]
+		res.Tlabel[k] = net.Tlabel[t]
+		res.Time[k] = net.Time[t]
+		res.Cond[k] = rename(net.Cond[t])
+		res.Inhib[k] = rename(net.Inhib[t])
+		res.Pre[k] = rename(net.Pre[t])
+		res.Delta[k] = rename(net.Delta[t])
+		res.Prio[k] = nil
+		for _, t2 := range net.Prio[t] {
+			res.Prio[k] = setAdd(res.Prio[k], tr[t2])
+		}
+		if len(disabled) != 0 {
+			res.Disabled[k] = disabled[t]
+		}
+		if len(weight) != 0 {
+			res.Weight[k] = weight[t]
+		}
+		if len(server) != 0 {
+			res.ServerKind[k] = server[t]
+		}
+		if t < len(prob) {
+			res.ArcProb[k] = prob[t]
+			slices.SortStableFunc(res.ArcProb[k], func(a, b ProbArc) int { return a.Pl - b.Pl })
+		}
+		if t < len(reset) {
+			res.Reset[k] = reset[t]
+		}
+	}
+	res.indexNodes()
+	return res
+}
+
+// Reduce returns a reduced version of the net, obtained by applying structural
+// reduction rules until no rule applies, together with a mapping from the names
+// of the nodes that have been removed to the name of the node that replaces
+// them (or the empty string when the node has no counterpart). The original
+// net is not modified. We only apply rules that preserve the set of reachable
+// markings, projected on the places that are kept, when we ignore timing
+// constraints:
+//
+//   - identity transitions, meaning transitions with a null Delta and no
+//     inhibitor, reset or probabilistic arcs, are removed since firing them
+//     never changes the marking;
+//
+//   - duplicate transitions, with the same conditions, the same effect, the
+//     same probabilistic arcs, weight and server kind than a transition with
+//     a smaller index, are removed and mapped to this transition;
+//
+//   - equivalent places, with the same initial marking and exactly the same
+//     arcs than a place with a smaller index, are fused with this place. Both
+//     places always have the same marking and the removed place is mapped to
+//     the one we keep.
+//
+// We only remove transitions that are not observable, meaning they have no
+// label, that have a trivial time interval, are not disabled and do not
+// appear in the priority relation. With duplicate transitions, the transition
+// that we keep must satisfy the same conditions. We do not apply agglomeration
+// rules (fusion of places or transitions in series), since they remove the
+// intermediate markings, where the tokens are in the fused place, and
+// therefore do not preserve the set of reachable markings but only weaker
+// properties, such as deadlock freedom. We return an error if the net is not
+// well-formed, for instance if the slices describing transitions do not have
+// the same length.
+func (net *Net) Reduce() (*Net, map[string]string, error) {
+	if err := net.checkConsistency(); err != nil {
+		return nil, nil, err
+	}
+	res := net.Clone()
+	mapping := make(map[string]string)
+	inprio := func(t int) bool {
+		if len(res.Prio[t]) != 0 {
+			return true
+		}
+		for _, v := range res.Prio {
+			if setMember(v, t) >= 0 {
+				return true
+			}
+		}
+		return false
+	}
+	removable := func(t int) bool {
+		return res.Tlabel[t] == "" && res.Time[t].Trivial() && !inprio(t) && !res.IsDisabled(t)
+	}
+	for changed := true; changed; {
+		changed = false
+		for t := 0; t < len(res.Tr); t++ {
+			if removable(t) && len(res.Delta[t]) == 0 && len(res.Inhib[t]) == 0 && len(res.resets(t)) == 0 && len(res.probArcs(t)) == 0 {
+				mapping[res.Tr[t]] = ""
+				res.deleteTransition(t)
+				changed = true
+				t--
+			}
+		}
+		for t2 := 0; t2 < len(res.Tr); t2++ {
+			for t := 0; t < t2; t++ {
+				if removable(t) && removable(t2) && res.sameTransition(t, t2) {
+					mapping[res.Tr[t2]] = res.Tr[t]
+					res.deleteTransition(t2)
+					changed = true
+					t2--
+					break
+				}
+			}
+		}
+		for q := 0; q < len(res.Pl); q++ {
+			for p := 0; p < q; p++ {
+				if res.samePlace(p, q) {
+					mapping[res.Pl[q]] = res.Pl[p]
+					res.deletePlace(q)
+					changed = true
+					q--
+					break
+				}
+			}
+		}
+	}
+	// we make sure that names mapped to a removed node are resolved
+	for k, v := range mapping {
+		for v != "" {
+			w, ok := mapping[v]
+			if !ok {
+				break
+			}
+			v = w
+		}
+		mapping[k] = v
+	}
+	return res, mapping, nil
+}
+
+// sameTransition reports whether transitions t1 and t2 have the same arcs,
+// including probabilistic arcs, the same time interval, weight and server
+// kind.
+func (net *Net) sameTransition(t1, t2 int) bool {
+	return net.Time[t1] == net.Time[t2] && net.Cond[t1].Equal(net.Cond[t2]) &&
+		net.Inhib[t1].Equal(net.Inhib[t2]) && net.Pre[t1].Equal(net.Pre[t2]) &&
+		net.Delta[t1].Equal(net.Delta[t2]) && slices.Equal(net.resets(t1), net.resets(t2)) &&
+		slices.Equal(net.probArcs(t1), net.probArcs(t2)) &&
+		net.TransitionWeight(t1) == net.TransitionWeight(t2) && net.Server(t1) == net.Server(t2)
+}
+
+// samePlace reports whether places p and q have the same initial marking and
+// the same arcs.
+func (net *Net) samePlace(p, q int) bool {
+	if net.Initial.Get(p) != net.Initial.Get(q) {
+		return false
+	}
+	for t := range net.Tr {
+		if net.Cond[t].Get(p) != net.Cond[t].Get(q) || net.Inhib[t].Get(p) != net.Inhib[t].Get(q) ||
+			net.Pre[t].Get(p) != net.Pre[t].Get(q) || net.Delta[t].Get(p) != net.Delta[t].Get(q) {
+			return false
+		}
+		if (setMember(net.resets(t), p) >= 0) != (setMember(net.resets(t), q) >= 0) {
+			return false
+		}
+		for _, a := range net.probArcs(t) {
+			if a.Pl == p || a.Pl == q {
+				// places with probabilistic arcs are never fused
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// addTransition appends a new transition, with the given name, to the net and
+// returns its index. The transition has no arcs, no label, and the default
+// timing constraint [0, w[. We keep the per-transition slices of the net, and
+// the index of transition names, in sync, like when parsing a net.
+func (net *Net) addTransition(name string) int {
+	net.Tr = append(net.Tr, name)
+	if net.trIndex == nil {
+		net.indexNodes()
+	} else {
+		net.trIndex[name] = len(net.Tr) - 1
+	}
+	net.Tlabel = append(net.Tlabel, "")
+	net.Time = append(net.Time, TimeInterval{
+		Left:  Bound{Bkind: BCLOSE, Value: 0},
+		Right: Bound{Bkind: BINFTY},
+	})
+	net.Cond = append(net.Cond, nil)
+	net.Inhib = append(net.Inhib, nil)
+	net.Pre = append(net.Pre, nil)
+	net.Delta = append(net.Delta, nil)
+	net.Prio = append(net.Prio, nil)
+	return len(net.Tr) - 1
+}
+
+// SynthesizeTransition adds a new transition to the net that, when fired from
+// marking from, leads to marking to. The transition tests every token in from
+// (its Cond is from), consumes the tokens that are not in to, and produces the
+// tokens that are missing from from, so that its Delta is to - from. It is
+// therefore enabled exactly in the markings that cover from. We return the
+// index of the new transition, or an error if name is already used by a
+// transition or if one of the markings mentions an unknown place or has a
+// negative multiplicity.
+func (net *Net) SynthesizeTransition(name string, from, to Marking) (int, error) {
+	if slices.Contains(net.Tr, name) {
+		return -1, fmt.Errorf("transition %s already exists", name)
+	}
+	for _, m := range []Marking{from, to} {
+		for _, a := range m {
+			if a.Pl < 0 || a.Pl >= len(net.Pl) {
+				return -1, fmt.Errorf("unknown place index %d in marking", a.Pl)
+			}
+			if a.Mult < 0 {
+				return -1, fmt.Errorf("negative multiplicity for place %s in marking", net.Pl[a.Pl])
+			}
+		}
+	}
+	delta := to.Sub(from)
+	var pre Marking
+	for _, a := range delta {
+		if a.Mult < 0 {
+			pre = append(pre, a)
+		}
+	}
+	t := net.addTransition(name)
+	net.Cond[t] = slices.Clone(from)
+	net.Pre[t] = pre
+	net.Delta[t] = delta
+	return t, nil
+}
@@ -0,0 +1,351 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestReadArcsAsConsumeRestore(t *testing.T) {
+	net, err := Parse(strings.NewReader("tr t0 p0?2 p1 -> p2\ntr t1 p0*3 p0?4 ->\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	res := net.ReadArcsAsConsumeRestore()
+	expected := []string{"tr t0  p0*2 p1 -> p0*2 p2\n", "tr t1  p0*4 -> p0\n"}
+	for _, v := range expected {
+		if !strings.Contains(res.String(), v) {
+			t.Errorf("ReadArcsAsConsumeRestore: expected %q in\n%s", v, res.String())
+		}
+	}
+	if !strings.Contains(net.String(), "p0?2") {
+		t.Errorf("ReadArcsAsConsumeRestore should not modify the original net:\n%s", net.String())
+	}
+}
+
+func TestMergePlaces(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\npl p1 (2)\ntr t0 p0 p1*2 -> p2\ntr t1 p2 -> p1 p3\ntr t2 p0?-3 p1?-2 ->\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if err := net.MergePlaces(0, 1); err != nil {
+		t.Fatalf("Error merging places; %s", err)
+	}
+	expected, err := Parse(strings.NewReader("pl p0 (3)\ntr t0 p0*3 -> p2\ntr t1 p2 -> p0 p3\ntr t2 p0?-2 ->\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if err := net.compare(expected); err != nil {
+		t.Errorf("MergePlaces: %s\n%s", err, net.String())
+	}
+	if err := net.MergePlaces(0, 0); err == nil {
+		t.Errorf("MergePlaces should fail when merging a place with itself")
+	}
+	// the color of the removed place is dropped, unless a transition has the
+	// same name
+	colors := "nt color 0 {p0 red}\nnt color 0 {p1 blue}\n"
+	for _, v := range []struct {
+		input  string
+		colors int
+	}{
+		{"pl p0 (1)\npl p1\ntr t0 p0 -> p1\n", 1},
+		{"pl p0 (1)\npl p1\ntr t0 p0 -> p1\ntr p1 ->\n", 2},
+	} {
+		net, err := Parse(strings.NewReader(v.input + colors))
+		if err != nil {
+			t.Fatalf("Error parsing net; %s", err)
+		}
+		if err := net.MergePlaces(0, 1); err != nil {
+			t.Fatalf("Error merging places; %s", err)
+		}
+		if len(net.NodeColor) != v.colors || len(net.Notes) != v.colors || net.NodeColor["p0"] != "red" {
+			t.Errorf("MergePlaces(%q): expected %d colors, actual %v %v", v.input, v.colors, net.NodeColor, net.Notes)
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	input := `pl p0 (1)
+pl q0 (1)
+tr t0 p0 q0 -> p1 q1
+tr t1 p1 q1 -> p0 q0
+tr t2 p0 q0 -> p1 q1
+tr t3 p0?1 ->
+tr t4 [1,2] p1 q1 -> p1 q1
+`
+	net, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	res, mapping, err := net.Reduce()
+	if err != nil {
+		t.Fatalf("Error in Reduce; %s", err)
+	}
+	expected := map[string]string{"q0": "p0", "q1": "p1", "t2": "t0", "t3": ""}
+	if len(mapping) != len(expected) {
+		t.Errorf("Reduce: expected mapping %v, actual %v", expected, mapping)
+	}
+	for k, v := range expected {
+		if actual, ok := mapping[k]; !ok || actual != v {
+			t.Errorf("Reduce: expected %s mapped to %q, actual %q", k, v, actual)
+		}
+	}
+	if len(res.Pl) != 2 || len(res.Tr) != 3 {
+		t.Errorf("Reduce: expected 2 places and 3 transitions, actual\n%s", res.String())
+	}
+	if err := res.SelfCheck(); err != nil {
+		t.Errorf("Reduce: %s", err)
+	}
+	// observable transitions, transitions with priorities or with different
+	// probabilistic arcs are never removed
+	for _, input := range []string{
+		"pl p0 (1)\ntr t0 p0 -> p1\ntr t1 : a p0 -> p1\n",
+		"pl p0 (1)\ntr t0 : a p0 -> p1\ntr t1 p0 -> p1\n",
+		"pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p0 -> p1\npr t0 > t2\n",
+		"pl p0 (1)\ntr t0 p0 -> p1%0.5\ntr t1 p0 -> p1%0.2\n",
+		"pl p0 (1)\ntr t0 : a p0 -> p0\n",
+	} {
+		net, err := ParseWithOptions(strings.NewReader(input), ParseOptions{Probabilities: true})
+		if err != nil {
+			t.Fatalf("Error parsing net; %s", err)
+		}
+		res, mapping, err := net.Reduce()
+		if err != nil {
+			t.Fatalf("Error in Reduce; %s", err)
+		}
+		if len(res.Tr) != len(net.Tr) || len(mapping) != 0 {
+			t.Errorf("Reduce(%q): expected no reduction, actual mapping %v\n%s", input, mapping, res)
+		}
+	}
+}
+
+func TestSynthesizeTransition(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (2)\ntr t0 p0 -> p1\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	from := Marking{{Pl: 0, Mult: 2}}
+	to := Marking{{Pl: 0, Mult: 1}, {Pl: 1, Mult: 3}}
+	k, err := net.SynthesizeTransition("s", from, to)
+	if err != nil {
+		t.Fatalf("SynthesizeTransition: %s", err)
+	}
+	if k != 1 || len(net.Time) != 2 || len(net.Prio) != 2 {
+		t.Fatalf("SynthesizeTransition: wrong index %d or parallel slices", k)
+	}
+	if !net.IsEnabled(from, k) || net.IsEnabled(Marking{{Pl: 0, Mult: 1}}, k) {
+		t.Errorf("SynthesizeTransition: wrong enabling condition %s", net.Mtoa(net.Cond[k]))
+	}
+	if m := from.Add(net.Delta[k]); !m.Equal(to) {
+		t.Errorf("SynthesizeTransition: expected %s, actual %s", net.Mtoa(to), net.Mtoa(m))
+	}
+	if err := net.SelfCheck(); err != nil {
+		t.Errorf("SynthesizeTransition: %s", err)
+	}
+	if _, err := net.SynthesizeTransition("s", from, to); err == nil {
+		t.Errorf("SynthesizeTransition: expected error with duplicate name")
+	}
+	if _, err := net.SynthesizeTransition("u", from, Marking{{Pl: 1, Mult: -1}}); err == nil {
+		t.Errorf("SynthesizeTransition: expected error with negative marking")
+	}
+	if _, err := net.SynthesizeTransition("v", Marking{{Pl: 4, Mult: 1}}, to); err == nil {
+		t.Errorf("SynthesizeTransition: expected error with unknown place")
+	}
+}
+
+func TestReverse(t *testing.T) {
+	for _, file := range []string{"testdata/demo.net", "testdata/abp.net", "testdata/ifip.net", "testdata/sokoban_3.net"} {
+		net, err := ParseFile(file)
+		if err != nil {
+			t.Fatalf("Error parsing file %s; %s", file, err)
+		}
+		if err := net.compare(net.Reverse().Reverse()); err != nil {
+			t.Errorf("Reverse(Reverse(%s)): %s", file, err)
+		}
+	}
+	net, err := Parse(strings.NewReader("pl p0 (1)\ntr t0 p0*2 p2?3 p3?-1 -> p1 p0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	expected, err := Parse(strings.NewReader("pl p0 (1)\npl p2\npl p3\npl p1\ntr t0 p0 p1 p2?3 p3?-1 -> p0*2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if err := expected.compare(net.Reverse()); err != nil {
+		t.Errorf("Reverse: %s", err)
+	}
+	// read arcs combined with output arcs on the same place
+	net, err = Parse(strings.NewReader("pl p (5)\ntr t p?2 -> p*3\ntr u p*2 p?4 q -> p q*2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	rev := net.Reverse()
+	if s := rev.Mtoa(rev.Cond[0]) + " / " + rev.Mtoa(rev.Pre[0]); s != "p*5 / p*-3" {
+		t.Errorf("Reverse: wrong condition for t, expected p*5 / p*-3, actual %s", s)
+	}
+	if rev.IsEnabled(Marking{{Pl: 0, Mult: 4}}, 0) || !rev.IsEnabled(Marking{{Pl: 0, Mult: 5}}, 0) {
+		t.Errorf("Reverse: t should need 5 tokens in p to fire backward")
+	}
+	for _, m := range []Marking{{{Pl: 0, Mult: 5}}, {{Pl: 0, Mult: 4}, {Pl: 1, Mult: 1}}, {{Pl: 0, Mult: 3}, {Pl: 1, Mult: 2}}} {
+		for tr := range net.Tr {
+			if m2 := net.fire(m, tr); net.IsEnabled(m, tr) && (!rev.IsEnabled(m2, tr) || !rev.fire(m2, tr).Equal(m)) {
+				t.Errorf("Reverse: cannot go back from %s with %s", net.Mtoa(m2), net.Tr[tr])
+			}
+		}
+	}
+	if err := net.compare(rev.Reverse()); err != nil {
+		t.Errorf("Reverse(Reverse()): %s", err)
+	}
+	net, err = ParseWithOptions(strings.NewReader("pl p (1)\ntr t p -> q r%0.5\n"), ParseOptions{Probabilities: true})
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if rev := net.Reverse(); len(rev.probArcs(0)) != 0 || rev.SelfCheck() != nil {
+		t.Errorf("Reverse: probabilistic arcs should be dropped\n%s", rev)
+	}
+}
+
+func TestUntimed(t *testing.T) {
+	net, err := ParseFile("testdata/demo.net")
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	res := net.Untimed()
+	if strings.ContainsAny(res.String(), "[]") {
+		t.Errorf("Untimed: expected no time intervals in\n%s", res.String())
+	}
+	if !strings.ContainsAny(net.String(), "[]") {
+		t.Errorf("Untimed should not modify the original net:\n%s", net.String())
+	}
+	for k := range res.Tr {
+		if !res.Time[k].Trivial() {
+			t.Errorf("Untimed: interval of %s is %s", res.Tr[k], res.Time[k].String())
+		}
+	}
+	res.Time = net.Time
+	if err := net.compare(res); err != nil {
+		t.Errorf("Untimed should only change time intervals; %s", err)
+	}
+	net2, err := ParseString(net.Untimed().String())
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if !net2.Equal(net.Untimed()) {
+		t.Errorf("Untimed: round trip failed")
+	}
+}
+
+func TestSubnet(t *testing.T) {
+	net, err := Parse(strings.NewReader("pl p0 (1)\npl p1 (2)\npl p2 (3)\npl p3\npl p4 (5)\ntr t0 p0 -> p1\ntr t1 p1 -> p2 p4\ntr t2 p2 p3?-1 -> p0\npr t2 > t0 t1\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	res := net.Subnet([]int{2, 0, 7, -1, 0})
+	expected, err := Parse(strings.NewReader("pl p0 (1)\npl p1 (2)\npl p2 (3)\npl p3\ntr t0 p0 -> p1\ntr t2 p2 p3?-1 -> p0\npr t2 > t0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if err := expected.compare(res); err != nil {
+		t.Errorf("Subnet: %s\n%s", err, res.String())
+	}
+	if len(net.Tr) != 3 || len(net.Pl) != 5 {
+		t.Errorf("Subnet should not modify the original net:\n%s", net.String())
+	}
+	res = net.Subnet([]int{1})
+	if want := []string{"p1", "p2", "p4"}; !slices.Equal(res.Pl, want) || res.Mtoa(res.Initial) != "p1*2 p2*3 p4*5" {
+		t.Errorf("Subnet: expected places %v, got %v with initial marking %s", want, res.Pl, res.Mtoa(res.Initial))
+	}
+	if err := res.SelfCheck(); err != nil {
+		t.Errorf("Subnet: %s", err)
+	}
+	// disabled transitions and notes on dropped nodes
+	net, err = Parse(strings.NewReader("pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p1 -> p2\ntr t2 p2 -> p0\nnt server 0 {t1 infinite}\nnt server 0 {t2 infinite}\nnt color 0 {p0 red}\nnt color 0 {p2 blue}\nnt comment 0 {hello}\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	net.Disable(0)
+	net.Disable(2)
+	res = net.Subnet([]int{0, 2})
+	if !res.IsDisabled(0) || !res.IsDisabled(1) {
+		t.Errorf("Subnet: disabled transitions should stay disabled, actual %v", res.Disabled)
+	}
+	res = net.Subnet([]int{1})
+	if len(res.Notes) != 3 || res.Notes[0].Body != "{t1 infinite}" || res.Notes[1].Body != "{p2 blue}" || res.Notes[2].Body != "{hello}" {
+		t.Errorf("Subnet: expected notes on kept nodes only, actual %v", res.Notes)
+	}
+	net2, err := Parse(strings.NewReader(res.String()))
+	if err != nil {
+		t.Fatalf("Error parsing subnet; %s", err)
+	}
+	if !slices.Equal(net2.Tr, []string{"t1"}) {
+		t.Errorf("Subnet: expected only transition t1 after parsing, actual %v", net2.Tr)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	net, err := ParseFile("testdata/ifip.net")
+	if err != nil {
+		t.Fatalf("Error parsing file; %s", err)
+	}
+	res, err := Merge(net, net, "l_", "r_")
+	if err != nil {
+		t.Fatalf("Merge: %s", err)
+	}
+	if len(res.Pl) != 2*len(net.Pl) || len(res.Tr) != 2*len(net.Tr) {
+		t.Errorf("Merge: expected %d places and %d transitions, actual %d and %d", 2*len(net.Pl), 2*len(net.Tr), len(res.Pl), len(res.Tr))
+	}
+	if res.Pl[0] != "l_"+net.Pl[0] || res.Tr[len(net.Tr)] != "r_"+net.Tr[0] {
+		t.Errorf("Merge: wrong names %v %v", res.Pl, res.Tr)
+	}
+	if err := res.SelfCheck(); err != nil {
+		t.Errorf("Merge: %s", err)
+	}
+	right := []int{}
+	for k := range net.Tr {
+		right = append(right, k+len(net.Tr))
+	}
+	expected, err := Merge(NewNet(net.Name), net, "", "r_")
+	if err != nil {
+		t.Fatalf("Merge: %s", err)
+	}
+	if err := expected.compare(res.Subnet(right)); err != nil {
+		t.Errorf("Merge: wrong copy of the second net; %s", err)
+	}
+	if s := res.Mtoa(res.Initial); s != strings.ReplaceAll("l_"+net.Mtoa(net.Initial), " ", " l_")+" "+strings.ReplaceAll("r_"+net.Mtoa(net.Initial), " ", " r_") {
+		t.Errorf("Merge: wrong initial marking %s", s)
+	}
+	if _, err := Merge(net, net, "", ""); err == nil {
+		t.Errorf("Merge: expected an error when names clash")
+	}
+	if _, err := Merge(net, net, "x_", "x_"); err == nil {
+		t.Errorf("Merge: expected an error when names clash")
+	}
+	// both nets have disabled transitions
+	dis := net.Clone()
+	dis.Disable(1)
+	dis.Disable(2)
+	res, err = Merge(dis, dis, "l_", "r_")
+	if err != nil {
+		t.Fatalf("Merge: %s", err)
+	}
+	for k := range res.Tr {
+		if expected := k%len(dis.Tr) == 1 || k%len(dis.Tr) == 2; res.IsDisabled(k) != expected {
+			t.Errorf("Merge: IsDisabled(%s) should be %v", res.Tr[k], expected)
+		}
+	}
+	net, err = ParseString("pl p (1)\ntr a p -> q\ntr b q -> p\npr a > b\n")
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	res, err = Merge(net, net, "x", "y")
+	if err != nil {
+		t.Fatalf("Merge: %s", err)
+	}
+	if !slices.Equal(res.Prio[0], []int{1}) || !slices.Equal(res.Prio[2], []int{3}) || res.Mtoa(res.Initial) != "xp yp" {
+		t.Errorf("Merge: wrong priorities or initial marking\n%s", res.String())
+	}
+}
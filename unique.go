@@ -63,3 +63,56 @@ func (mk Handle) Marking() Marking {
 	}
 	return m
 }
+
+// MarkingSet is a set of markings, where markings are stored using their
+// Handle (see Unique). Hence markings are interned and testing membership only
+// requires to compare handles. The zero value is an empty set ready to use.
+type MarkingSet struct {
+	set     map[Handle]struct{}
+	handles []Handle // in the order markings have been added
+}
+
+// Add adds marking m to the set and returns true if it was not already in the
+// set. We return an error, and do not modify the set, if m cannot be interned,
+// for instance because it has a negative multiplicity.
+func (s *MarkingSet) Add(m Marking) (bool, error) {
+	h, err := m.Unique()
+	if err != nil {
+		return false, fmt.Errorf("cannot add marking to set; %w", err)
+	}
+	if _, ok := s.set[h]; ok {
+		return false, nil
+	}
+	if s.set == nil {
+		s.set = make(map[Handle]struct{})
+	}
+	s.set[h] = struct{}{}
+	s.handles = append(s.handles, h)
+	return true, nil
+}
+
+// Contains reports whether marking m is in the set. The result is false for
+// markings that cannot be interned, since they cannot be added to the set.
+func (s *MarkingSet) Contains(m Marking) bool {
+	h, err := m.Unique()
+	if err != nil {
+		return false
+	}
+	_, ok := s.set[h]
+	return ok
+}
+
+// Len returns the number of markings in the set.
+func (s *MarkingSet) Len() int {
+	return len(s.handles)
+}
+
+// All returns the markings in the set, in the order they have been added. The
+// result is a new slice of fresh markings that can be modified by the caller.
+func (s *MarkingSet) All() []Marking {
+	res := make([]Marking, len(s.handles))
+	for k, h := range s.handles {
+		res[k] = h.Marking()
+	}
+	return res
+}
@@ -23,3 +23,45 @@ func TestMarking(t *testing.T) {
 		}
 	}
 }
+
+func TestMarkingSet(t *testing.T) {
+	var s MarkingSet
+	tables := []struct {
+		m     Marking
+		added bool
+	}{
+		{Marking{{Pl: 0, Mult: 3}, {Pl: 5, Mult: 4}}, true},
+		{Marking{}, true},
+		{Marking{{Pl: 0, Mult: 3}, {Pl: 5, Mult: 4}}, false},
+		{Marking{{Pl: 0, Mult: 3}}, true},
+		{Marking{}, false},
+	}
+	for _, v := range tables {
+		added, err := s.Add(v.m)
+		if err != nil {
+			t.Fatalf("Add(%v): %s", v.m, err)
+		}
+		if added != v.added {
+			t.Errorf("Add(%v): expected %v, actual %v", v.m, v.added, added)
+		}
+		if !s.Contains(v.m) {
+			t.Errorf("Contains(%v): expected true", v.m)
+		}
+	}
+	if s.Len() != 3 {
+		t.Errorf("Len: expected 3, actual %d", s.Len())
+	}
+	all := s.All()
+	for k, v := range []Marking{tables[0].m, tables[1].m, tables[3].m} {
+		if !all[k].Equal(v) {
+			t.Errorf("All: expected %v at position %d, actual %v", v, k, all[k])
+		}
+	}
+	if s.Contains(Marking{{Pl: 5, Mult: 4}}) {
+		t.Errorf("Contains: marking should not be in the set")
+	}
+	neg := Marking{{Pl: 1, Mult: -2}}
+	if _, err := s.Add(neg); err == nil || s.Len() != 3 || s.Contains(neg) {
+		t.Errorf("Add(%v): expected an error", neg)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteUppaal writes a timed automaton, equivalent to the net, in the XML
+// format of Uppaal. We build the (untimed) marking graph of the net, with at
+// most maxStates markings, and use one location for each reachable marking
+// and one edge for each transition between markings. We use one clock, x_t,
+// for each transition t with a non-trivial time interval. The clock of t is
+// reset when t becomes newly enabled; the lower bound of the interval of t is
+// used as a guard and its upper bound as an invariant of every location where
+// t is enabled.
+//
+// This translation is only possible for bounded nets and we return an error if
+// the net has more than maxStates reachable markings. The marking graph is
+// computed without taking into account timing constraints, which means that
+// some locations may be unreachable in the automaton. We do not support
+// priorities and return an error if the net has some.
+func (net *Net) WriteUppaal(w io.Writer, maxStates int) error {
+	for k, v := range net.Prio {
+		if len(v) != 0 {
+			return fmt.Errorf("cannot translate net with priorities into a timed automaton; see transition %s", net.Tr[k])
+		}
+	}
+	g, err := net.explore(maxStates)
+	if err != nil {
+		return err
+	}
+	clock := func(t int) string {
+		return fmt.Sprintf("x_%d", t)
+	}
+	clocks := []string{}
+	for t := range net.Tr {
+		if !net.Time[t].Trivial() {
+			clocks = append(clocks, clock(t))
+		}
+	}
+	bw := bufio.NewWriter(w)
+	bw.WriteString("<?xml version=\"1.0\" encoding=\"utf-8\"?>\n")
+	bw.WriteString("<!DOCTYPE nta PUBLIC '-//Uppaal Team//DTD Flat System 1.1//EN' 'http://www.it.uu.se/research/group/darts/uppaal/flat-1_2.dtd'>\n")
+	bw.WriteString("<nta>\n")
+	if len(clocks) != 0 {
+		fmt.Fprintf(bw, "  <declaration>clock %s;</declaration>\n", strings.Join(clocks, ", "))
+	}
+	bw.WriteString("  <template>\n    <name>Net</name>\n")
+	for s, m := range g.markings {
+		inv := []string{}
+		for _, t := range net.AllEnabled(m) {
+			if net.Time[t].Trivial() || net.Time[t].Right.Bkind == BINFTY {
+				continue
+			}
+			inv = append(inv, clock(t)+" "+uppaalUpper(net.Time[t].Right))
+		}
+		fmt.Fprintf(bw, "    <location id=\"id%d\">\n", s)
+		fmt.Fprintf(bw, "      <name>s%d</name>\n", s)
+		if len(inv) != 0 {
+			fmt.Fprintf(bw, "      <label kind=\"invariant\">%s</label>\n", xmlEscape(strings.Join(inv, " && ")))
+		}
+		fmt.Fprintf(bw, "      <label kind=\"comments\">%s</label>\n", xmlEscape(net.Mtoa(m)))
+		bw.WriteString("    </location>\n")
+	}
+	bw.WriteString("    <init ref=\"id0\"/>\n")
+	for s, edges := range g.edges {
+		for _, e := range edges {
+			fmt.Fprintf(bw, "    <transition>\n      <source ref=\"id%d\"/>\n      <target ref=\"id%d\"/>\n", s, e.dst)
+			if i := net.Time[e.t]; !i.Trivial() && (i.Left.Value != 0 || i.Left.Bkind == BOPEN) {
+				fmt.Fprintf(bw, "      <label kind=\"guard\">%s</label>\n", xmlEscape(clock(e.t)+" "+uppaalLower(i.Left)))
+			}
+			reset := []string{}
+			for _, t := range net.newlyEnabled(g.markings[s], e.t, g.markings[e.dst]) {
+				if !net.Time[t].Trivial() {
+					reset = append(reset, clock(t)+" = 0")
+				}
+			}
+			if len(reset) != 0 {
+				fmt.Fprintf(bw, "      <label kind=\"assignment\">%s</label>\n", strings.Join(reset, ", "))
+			}
+			fmt.Fprintf(bw, "      <label kind=\"comments\">%s</label>\n", xmlEscape(net.Tr[e.t]))
+			bw.WriteString("    </transition>\n")
+		}
+	}
+	bw.WriteString("  </template>\n  <system>system Net;</system>\n</nta>\n")
+	return bw.Flush()
+}
+
+// newlyEnabled returns the transitions enabled at marking m2, obtained by
+// firing t from m, that are newly enabled. A transition is newly enabled if it
+// is t itself or if it is not enabled at the intermediate marking obtained by
+// removing the tokens consumed by t from m.
+func (net *Net) newlyEnabled(m Marking, t int, m2 Marking) []int {
+	res := []int{}
+	intermediate := m.Add(net.Pre[t])
+	for _, t2 := range net.AllEnabled(m2) {
+		if t2 == t || !net.IsEnabled(intermediate, t2) {
+			res = append(res, t2)
+		}
+	}
+	return res
+}
+
+// uppaalLower returns a clock constraint for a lower bound, such as ">= 4".
+func uppaalLower(b Bound) string {
+	if b.Bkind == BOPEN {
+		return fmt.Sprintf("> %d", b.Value)
+	}
+	return fmt.Sprintf(">= %d", b.Value)
+}
+
+// uppaalUpper returns a clock constraint for a (finite) upper bound, such as
+// "<= 5".
+func uppaalUpper(b Bound) string {
+	if b.Bkind == BOPEN {
+		return fmt.Sprintf("< %d", b.Value)
+	}
+	return fmt.Sprintf("<= %d", b.Value)
+}
@@ -0,0 +1,79 @@
+// Copyright 2025. Silvano DAL ZILIO. All rights reserved.
+// Use of this source code is governed by the AGPL license
+// that can be found in the LICENSE file.
+
+package nets
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteUppaal(t *testing.T) {
+	net, err := Parse(strings.NewReader("net demo\npl p0 (1)\ntr t0 [1,3] p0 -> p1\ntr t1 ]2,w[ p1 -> p2\ntr t2 [0,4[ p2 -> p0\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	var b strings.Builder
+	if err := net.WriteUppaal(&b, 10); err != nil {
+		t.Fatalf("WriteUppaal: %s", err)
+	}
+	expected := `<?xml version="1.0" encoding="utf-8"?>
+<!DOCTYPE nta PUBLIC '-//Uppaal Team//DTD Flat System 1.1//EN' 'http://www.it.uu.se/research/group/darts/uppaal/flat-1_2.dtd'>
+<nta>
+  <declaration>clock x_0, x_1, x_2;</declaration>
+  <template>
+    <name>Net</name>
+    <location id="id0">
+      <name>s0</name>
+      <label kind="invariant">x_0 &lt;= 3</label>
+      <label kind="comments">p0</label>
+    </location>
+    <location id="id1">
+      <name>s1</name>
+      <label kind="comments">p1</label>
+    </location>
+    <location id="id2">
+      <name>s2</name>
+      <label kind="invariant">x_2 &lt; 4</label>
+      <label kind="comments">p2</label>
+    </location>
+    <init ref="id0"/>
+    <transition>
+      <source ref="id0"/>
+      <target ref="id1"/>
+      <label kind="guard">x_0 &gt;= 1</label>
+      <label kind="assignment">x_1 = 0</label>
+      <label kind="comments">t0</label>
+    </transition>
+    <transition>
+      <source ref="id1"/>
+      <target ref="id2"/>
+      <label kind="guard">x_1 &gt; 2</label>
+      <label kind="assignment">x_2 = 0</label>
+      <label kind="comments">t1</label>
+    </transition>
+    <transition>
+      <source ref="id2"/>
+      <target ref="id0"/>
+      <label kind="assignment">x_0 = 0</label>
+      <label kind="comments">t2</label>
+    </transition>
+  </template>
+  <system>system Net;</system>
+</nta>
+`
+	if b.String() != expected {
+		t.Errorf("WriteUppaal: expected\n%s\nactual\n%s", expected, b.String())
+	}
+	if err := net.WriteUppaal(&b, 2); err == nil {
+		t.Errorf("WriteUppaal: expected error with more than 2 markings")
+	}
+	net, err = Parse(strings.NewReader("pl p0 (1)\ntr t0 p0 -> p1\ntr t1 p0 -> p2\npr t0 > t1\n"))
+	if err != nil {
+		t.Fatalf("Error parsing net; %s", err)
+	}
+	if err := net.WriteUppaal(&b, 10); err == nil {
+		t.Errorf("WriteUppaal: expected error on net with priorities")
+	}
+}